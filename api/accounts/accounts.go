@@ -0,0 +1,232 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinUsernameLength is the fewest characters a username may have
+	MinUsernameLength = 3
+	// MaxUsernameLength is the most characters a username may have
+	MaxUsernameLength = 32
+	// MinPasswordLength is the fewest characters a password may have
+	MinPasswordLength = 8
+	// MaxPasswordLength is the most bytes a password may have, as bcrypt ignores anything longer
+	MaxPasswordLength = 72
+)
+
+var (
+	// ErrUsernameTaken is returned when registering a username which already belongs to a user
+	ErrUsernameTaken = errors.New("username is already taken")
+	// ErrUserNotFound is returned when a user does not exist
+	ErrUserNotFound = errors.New("user was not found")
+	// ErrInvalidCredentials is returned when a username and password do not match a user
+	ErrInvalidCredentials = errors.New("username or password is incorrect")
+)
+
+// PasswordCost is the bcrypt cost used to hash new passwords
+var PasswordCost = bcrypt.DefaultCost
+
+var (
+	// dummyHash is compared against when a user does not exist, so that logging in takes as long for unknown usernames
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// User represents a registered user. The password hash is never included in responses.
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Result represents the score a user achieved for a submitted quiz, along with the outcome of each question in the
+// order they were asked. Score is a percentage.
+type Result struct {
+	Username    string    `json:"username"`
+	Category    string    `json:"category"`
+	Difficulty  string    `json:"difficulty,omitempty"`
+	Score       float64   `json:"score"`
+	ScoreString string    `json:"scoreString"`
+	Outcomes    []Outcome `json:"outcomes"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Outcome records how a single question of a quiz was answered. Credit is between 0 and 1 and skipped questions,
+// including those which ran out of time, were not answered at all. Points are those earned under the scoring strategy.
+type Outcome struct {
+	QuestionID int     `json:"questionId"`
+	Category   string  `json:"category"`
+	Question   string  `json:"question"`
+	Credit     float64 `json:"credit"`
+	Skipped    bool    `json:"skipped,omitempty"`
+	Points     float64 `json:"points"`
+	Available  float64 `json:"available"`
+}
+
+// Store holds registered users and the results of their quizzes. Implementations must be safe for concurrent use.
+type Store interface {
+	// Create stores a new user, assigning it an ID, or returns ErrUsernameTaken if the username is in use
+	Create(user User) (User, error)
+	// Find retrieves a user by username or returns ErrUserNotFound
+	Find(username string) (User, error)
+	// RecordResult stores the result of a quiz submitted by a user
+	RecordResult(result Result) error
+	// Results retrieves every result of a user, oldest first, or returns ErrUserNotFound
+	Results(username string) ([]Result, error)
+}
+
+// NormaliseUsername trims and lowercases a username, and checks that its length is allowed and that it only
+// contains letters, digits, dots, hyphens and underscores
+func NormaliseUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+
+	length := len([]rune(username))
+	if length < MinUsernameLength || length > MaxUsernameLength {
+		return "", fmt.Errorf("username must be between %d and %d characters", MinUsernameLength, MaxUsernameLength)
+	}
+
+	for _, r := range username {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_' {
+			msg := "username must only contain letters, digits, dots, hyphens and underscores"
+			return "", errors.New(msg)
+		}
+	}
+
+	return username, nil
+}
+
+// ValidatePassword checks that a password is long enough to be hard to guess and short enough for bcrypt to hash in full
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", MaxPasswordLength)
+	}
+
+	return nil
+}
+
+// HashPassword hashes a password with bcrypt
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Authenticate retrieves the user with a username and checks their password, returning ErrInvalidCredentials if
+// either is wrong so that callers cannot tell which usernames exist
+func Authenticate(store Store, username string, password string) (User, error) {
+	user, err := store.Find(username)
+	if errors.Is(err, ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return User{}, ErrInvalidCredentials
+	} else if err != nil {
+		return User{}, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return User{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// dummyPasswordHash returns a hash with the same cost as new passwords, which is generated when it is first needed
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), PasswordCost)
+	})
+	return dummyHash
+}
+
+// MemoryStore is an in-memory Store guarded by a mutex
+type MemoryStore struct {
+	mu      sync.RWMutex
+	users   map[string]User
+	nextID  int
+	results []Result
+}
+
+// NewMemoryStore creates an empty in-memory account store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:  make(map[string]User),
+		nextID: 1,
+	}
+}
+
+// Create stores a new user, assigning it the next ID unless it already has one
+func (s *MemoryStore) Create(user User) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user.Username]; ok {
+		return User{}, ErrUsernameTaken
+	}
+
+	if user.ID == 0 {
+		user.ID = s.nextID
+	}
+	if user.ID >= s.nextID {
+		s.nextID = user.ID + 1
+	}
+
+	s.users[user.Username] = user
+	return user, nil
+}
+
+// Find retrieves a user by username
+func (s *MemoryStore) Find(username string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[username]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// RecordResult stores the result of a quiz submitted by a user
+func (s *MemoryStore) RecordResult(result Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[result.Username]; !ok {
+		return ErrUserNotFound
+	}
+
+	s.results = append(s.results, result)
+	return nil
+}
+
+// Results retrieves every result of a user, oldest first
+func (s *MemoryStore) Results(username string) ([]Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.users[username]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	results := []Result{}
+	for _, result := range s.results {
+		if result.Username == username {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
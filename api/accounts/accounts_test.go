@@ -0,0 +1,154 @@
+package accounts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestNormaliseUsername tests trimming, lowercasing and validating usernames
+func TestNormaliseUsername(t *testing.T) {
+	tests := []struct {
+		name          string
+		username      string
+		expected      string
+		expectedError string
+	}{
+		{"success_trimmed_and_lowercased", "  Ada.Lovelace ", "ada.lovelace", ""},
+		{"success_hyphens_and_underscores", "quiz_wiz-99", "quiz_wiz-99", ""},
+		{"failure_too_short", "ab", "", "username must be between 3 and 32 characters"},
+		{"failure_too_long", "abcdefghijklmnopqrstuvwxyz1234567", "", "username must be between 3 and 32 characters"},
+		{"failure_spaces", "ada lovelace", "", "username must only contain letters, digits, dots, hyphens and underscores"},
+		{"failure_symbols", "ada@example", "", "username must only contain letters, digits, dots, hyphens and underscores"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, err := NormaliseUsername(tt.username)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, username)
+			}
+		})
+	}
+}
+
+// TestValidatePassword tests the password length limits
+func TestValidatePassword(t *testing.T) {
+	assert.NoError(t, ValidatePassword("correct horse"))
+	assert.EqualError(t, ValidatePassword("short"), "password must be at least 8 characters")
+	assert.EqualError(t, ValidatePassword(string(make([]byte, 73))), "password must be at most 72 bytes")
+}
+
+// TestAuthenticate checks that only the right password for an existing user is accepted
+func TestAuthenticate(t *testing.T) {
+	originalCost := PasswordCost
+	PasswordCost = bcrypt.MinCost
+	defer func() { PasswordCost = originalCost }()
+
+	hash, err := HashPassword("correct horse")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash, "Expected the password to be hashed")
+
+	store := NewMemoryStore()
+	user, err := store.Create(User{Username: "ada", PasswordHash: hash})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+
+	_, err = store.Create(User{Username: "ada", PasswordHash: hash})
+	assert.ErrorIs(t, err, ErrUsernameTaken)
+
+	tests := []struct {
+		name          string
+		username      string
+		password      string
+		expectedError error
+	}{
+		{"success_correct_password", "ada", "correct horse", nil},
+		{"failure_wrong_password", "ada", "battery staple", ErrInvalidCredentials},
+		{"failure_unknown_user", "bob", "correct horse", ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticated, err := Authenticate(store, tt.username, tt.password)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, user, authenticated)
+			}
+		})
+	}
+
+	// Unknown usernames are checked against a hash of the same cost, so they take as long to reject
+	cost, err := bcrypt.Cost(dummyPasswordHash())
+	assert.NoError(t, err)
+	assert.Equal(t, PasswordCost, cost)
+}
+
+// TestMemoryStoreResults checks that results can only be recorded for registered users and are retrieved oldest first
+func TestMemoryStoreResults(t *testing.T) {
+	store := NewMemoryStore()
+	_, err := store.Create(User{Username: "ada"})
+	assert.NoError(t, err)
+	_, err = store.Create(User{Username: "bob"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.RecordResult(Result{Username: "ada", Category: "science", Score: 80, ScoreString: "4/5"}))
+	assert.NoError(t, store.RecordResult(Result{Username: "bob", Category: "music", Score: 20, ScoreString: "1/5"}))
+	assert.NoError(t, store.RecordResult(Result{Username: "ada", Category: "music", Score: 60, ScoreString: "3/5"}))
+	assert.ErrorIs(t, store.RecordResult(Result{Username: "cy", Category: "science"}), ErrUserNotFound)
+
+	results, err := store.Results("ada")
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "science", results[0].Category)
+		assert.Equal(t, "music", results[1].Category)
+	}
+
+	_, err = store.Results("cy")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+// TestTokens checks that issued tokens identify their user until they expire or are tampered with
+func TestTokens(t *testing.T) {
+	tokens := Tokens{Secret: []byte("secret"), TTL: time.Hour}
+	now := time.Now()
+
+	token, expiresAt, err := tokens.Issue(User{Username: "ada"}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	expired, _, err := tokens.Issue(User{Username: "ada"}, now.Add(-2*time.Hour))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		tokens        Tokens
+		token         string
+		expected      string
+		expectedError error
+	}{
+		{"success_valid_token", tokens, token, "ada", nil},
+		{"failure_expired_token", tokens, expired, "", ErrInvalidToken},
+		{"failure_wrong_secret", Tokens{Secret: []byte("other")}, token, "", ErrInvalidToken},
+		{"failure_malformed_token", tokens, "not-a-token", "", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username, err := tt.tokens.Parse(tt.token)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, username)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package accounts
+
+import (
+	"sort"
+)
+
+const (
+	// RecentScores is the number of most recent scores included in a user's statistics
+	RecentScores = 20
+	// TrendDays is the number of most recent days with quizzes included in a user's trend
+	TrendDays = 14
+)
+
+// Stats summarises a user's results. Scores and accuracies are percentages. Accuracy is the credit earned over every
+// question asked, so skipped questions count against it.
+type Stats struct {
+	Quizzes       int             `json:"quizzes"`
+	Questions     int             `json:"questions"`
+	AverageScore  float64         `json:"averageScore"`
+	BestScore     float64         `json:"bestScore"`
+	Accuracy      float64         `json:"accuracy"`
+	Categories    []CategoryStats `json:"categories"`
+	BestCategory  string          `json:"bestCategory,omitempty"`
+	WorstCategory string          `json:"worstCategory,omitempty"`
+	Trend         []DailyStats    `json:"trend"`
+	Recent        []float64       `json:"recent"`
+}
+
+// CategoryStats summarises a user's answers to the questions of a single category, including those asked within
+// random and combined quizzes
+type CategoryStats struct {
+	Category  string  `json:"category"`
+	Quizzes   int     `json:"quizzes"`
+	Questions int     `json:"questions"`
+	Correct   int     `json:"correct"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+// DailyStats summarises the quizzes a user submitted on a single day, in UTC
+type DailyStats struct {
+	Date         string  `json:"date"`
+	Quizzes      int     `json:"quizzes"`
+	AverageScore float64 `json:"averageScore"`
+}
+
+// Summarise calculates a user's statistics from their results, which must be ordered oldest first.
+// Best and worst categories are only chosen once categories with different accuracies have been answered.
+func Summarise(results []Result) Stats {
+	stats := Stats{
+		Categories: []CategoryStats{},
+		Trend:      []DailyStats{},
+		Recent:     []float64{},
+	}
+	if len(results) == 0 {
+		return stats
+	}
+
+	totalScore, totalCredit := 0.0, 0.0
+	categories := make(map[string]*CategoryStats)
+	categoryCredit := make(map[string]float64)
+	days := []DailyStats{}
+	dayScores := 0.0
+
+	for _, result := range results {
+		stats.Quizzes++
+		totalScore += result.Score
+		if result.Score > stats.BestScore {
+			stats.BestScore = result.Score
+		}
+
+		counted := make(map[string]bool)
+		for _, outcome := range result.Outcomes {
+			category, ok := categories[outcome.Category]
+			if !ok {
+				category = &CategoryStats{Category: outcome.Category}
+				categories[outcome.Category] = category
+			}
+			if !counted[outcome.Category] {
+				counted[outcome.Category] = true
+				category.Quizzes++
+			}
+
+			category.Questions++
+			categoryCredit[outcome.Category] += outcome.Credit
+			if outcome.Credit == 1 {
+				category.Correct++
+			}
+
+			stats.Questions++
+			totalCredit += outcome.Credit
+		}
+
+		// Results arrive oldest first, so each new day follows the last
+		date := result.CreatedAt.UTC().Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			dayScores = 0
+			days = append(days, DailyStats{Date: date})
+		}
+		day := &days[len(days)-1]
+		day.Quizzes++
+		dayScores += result.Score
+		day.AverageScore = dayScores / float64(day.Quizzes)
+	}
+
+	stats.AverageScore = totalScore / float64(stats.Quizzes)
+	if stats.Questions > 0 {
+		stats.Accuracy = totalCredit / float64(stats.Questions) * 100
+	}
+
+	for name, category := range categories {
+		category.Accuracy = categoryCredit[name] / float64(category.Questions) * 100
+		stats.Categories = append(stats.Categories, *category)
+	}
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		return stats.Categories[i].Category < stats.Categories[j].Category
+	})
+
+	if len(stats.Categories) > 1 {
+		best, worst := stats.Categories[0], stats.Categories[0]
+		for _, category := range stats.Categories[1:] {
+			if category.Accuracy > best.Accuracy {
+				best = category
+			}
+			if category.Accuracy < worst.Accuracy {
+				worst = category
+			}
+		}
+		// Categories with the same accuracy are neither better nor worse than each other
+		if best.Accuracy > worst.Accuracy {
+			stats.BestCategory = best.Category
+			stats.WorstCategory = worst.Category
+		}
+	}
+
+	if len(days) > TrendDays {
+		days = days[len(days)-TrendDays:]
+	}
+	stats.Trend = days
+
+	recent := results
+	if len(recent) > RecentScores {
+		recent = recent[len(recent)-RecentScores:]
+	}
+	for _, result := range recent {
+		stats.Recent = append(stats.Recent, result.Score)
+	}
+
+	return stats
+}
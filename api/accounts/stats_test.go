@@ -0,0 +1,72 @@
+package accounts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSummarise tests calculating a user's statistics from their results
+func TestSummarise(t *testing.T) {
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	results := []Result{
+		{Category: "science", Score: 50, CreatedAt: day, Outcomes: []Outcome{
+			{QuestionID: 1, Category: "science", Credit: 1},
+			{QuestionID: 2, Category: "science", Credit: 0},
+		}},
+		{Category: "music+science", Score: 75, CreatedAt: day.Add(time.Hour), Outcomes: []Outcome{
+			{QuestionID: 3, Category: "music", Credit: 1},
+			{QuestionID: 4, Category: "music", Credit: 0.5},
+			{QuestionID: 1, Category: "science", Credit: 1},
+			{QuestionID: 5, Category: "science", Skipped: true},
+		}},
+		{Category: "music", Score: 100, CreatedAt: day.Add(24 * time.Hour), Outcomes: []Outcome{
+			{QuestionID: 3, Category: "music", Credit: 1},
+			{QuestionID: 6, Category: "music", Credit: 1},
+		}},
+	}
+
+	stats := Summarise(results)
+
+	assert.Equal(t, 3, stats.Quizzes)
+	assert.Equal(t, 8, stats.Questions)
+	assert.Equal(t, 75.0, stats.AverageScore)
+	assert.Equal(t, 100.0, stats.BestScore)
+	assert.Equal(t, 68.75, stats.Accuracy)
+	assert.Equal(t, []CategoryStats{
+		{Category: "music", Quizzes: 2, Questions: 4, Correct: 3, Accuracy: 87.5},
+		{Category: "science", Quizzes: 2, Questions: 4, Correct: 2, Accuracy: 50},
+	}, stats.Categories)
+	assert.Equal(t, "music", stats.BestCategory)
+	assert.Equal(t, "science", stats.WorstCategory)
+	assert.Equal(t, []DailyStats{
+		{Date: "2024-01-01", Quizzes: 2, AverageScore: 62.5},
+		{Date: "2024-01-02", Quizzes: 1, AverageScore: 100},
+	}, stats.Trend)
+	assert.Equal(t, []float64{50, 75, 100}, stats.Recent)
+}
+
+// TestSummariseEdgeCases checks users without results, with a single category and with a long history
+func TestSummariseEdgeCases(t *testing.T) {
+	empty := Summarise(nil)
+	assert.Equal(t, 0, empty.Quizzes)
+	assert.Empty(t, empty.Categories)
+	assert.NotNil(t, empty.Recent, "Expected an empty list of recent scores rather than null")
+
+	single := Summarise([]Result{{Score: 50, Outcomes: []Outcome{{Category: "science", Credit: 1}}}})
+	assert.Empty(t, single.BestCategory, "Expected no best category when only one has been answered")
+	assert.Empty(t, single.WorstCategory)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	long := []Result{}
+	for i := 0; i < 30; i++ {
+		long = append(long, Result{Score: float64(i), CreatedAt: start.AddDate(0, 0, i)})
+	}
+
+	stats := Summarise(long)
+	assert.Len(t, stats.Recent, RecentScores)
+	assert.Equal(t, 10.0, stats.Recent[0], "Expected only the most recent scores")
+	assert.Len(t, stats.Trend, TrendDays)
+	assert.Equal(t, "2024-01-30", stats.Trend[TrendDays-1].Date)
+}
@@ -0,0 +1,72 @@
+package accounts
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// TokenIssuer is the issuer recorded within every access token
+const TokenIssuer = "quizwizard"
+
+// DefaultTokenTTL is how long access tokens remain valid when no lifetime is configured
+const DefaultTokenTTL = 24 * time.Hour
+
+// ErrInvalidToken is returned when an access token is malformed, has the wrong signature or has expired
+var ErrInvalidToken = errors.New("access token is invalid or has expired")
+
+// Tokens issues access tokens which are signed with HS256 and identify a user by the token subject
+type Tokens struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// NewSecret generates a random secret for signing access tokens
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate token secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Issue signs an access token for a user, returning the token and when it expires
+func (t Tokens) Issue(user User, now time.Time) (string, time.Time, error) {
+	ttl := t.TTL
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	expiresAt := now.Add(ttl)
+
+	claims := jwt.StandardClaims{
+		Subject:   user.Username,
+		Issuer:    TokenIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.Secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// Parse checks the signature and expiry of an access token and returns the username it was issued to
+func (t Tokens) Parse(token string) (string, error) {
+	claims := &jwt.StandardClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return t.Secret, nil
+	})
+	if err != nil || !parsed.Valid || claims.Subject == "" {
+		return "", ErrInvalidToken
+	}
+
+	return claims.Subject, nil
+}
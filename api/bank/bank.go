@@ -0,0 +1,527 @@
+package bank
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"quizwizard/api/models"
+)
+
+var (
+	// ErrQuestionNotFound is returned when no question has the specified ID
+	ErrQuestionNotFound = errors.New("question does not exist")
+	// ErrDuplicateQuestionID is returned when a question ID is already in use
+	ErrDuplicateQuestionID = errors.New("question ID is already in use")
+	// ErrCategoryNotFound is returned when a category does not exist
+	ErrCategoryNotFound = errors.New("category does not exist")
+	// ErrCategoryExists is returned when a category already exists
+	ErrCategoryExists = errors.New("category already exists")
+	// ErrCategoryNotEmpty is returned when deleting a category which still contains questions
+	ErrCategoryNotEmpty = errors.New("category still contains questions")
+	// ErrInvalidCategory is returned when a category name cannot be used
+	ErrInvalidCategory = errors.New("category name is invalid")
+	// ErrChanged is returned by ReplaceIf when the bank no longer holds the expected questions
+	ErrChanged = errors.New("question bank has changed")
+)
+
+// Persister stores the question bank whenever it changes
+type Persister interface {
+	ReplaceQuestions(questions map[string]models.Questions) error
+}
+
+// Bank holds the live question bank. Every change builds a new copy of the bank which is
+// persisted and then swapped in, so readers always see a complete and consistent bank.
+// Category details are held alongside the questions but are not persisted.
+type Bank struct {
+	writeMu   sync.Mutex
+	mu        sync.RWMutex
+	questions map[string]models.Questions
+	details   map[string]models.CategoryDetails
+	persister Persister
+}
+
+// New creates a question bank. The persister is optional.
+func New(questions map[string]models.Questions, persister Persister) *Bank {
+	if questions == nil {
+		questions = make(map[string]models.Questions)
+	}
+
+	return &Bank{
+		questions: questions,
+		details:   make(map[string]models.CategoryDetails),
+		persister: persister,
+	}
+}
+
+// Questions returns the current questions for each category. The returned map must not be modified.
+func (b *Bank) Questions() map[string]models.Questions {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.questions
+}
+
+// SetDetails replaces the display names and descriptions of the categories
+func (b *Bank) SetDetails(details map[string]models.CategoryDetails) {
+	cpy := make(map[string]models.CategoryDetails, len(details))
+	for category, detail := range details {
+		cpy[category] = detail
+	}
+
+	b.mu.Lock()
+	b.details = cpy
+	b.mu.Unlock()
+}
+
+// Categories returns every category in order of slug, with its details and a summary of the questions within it and
+// its subcategories. Parents of subcategories are included even when they have no questions of their own, and
+// categories without a display name are named after their slug.
+func (b *Bank) Categories() []models.Category {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	slugs := make(map[string]bool, len(b.questions))
+	for category := range b.questions {
+		for _, level := range models.CategoryLevels(category) {
+			slugs[level] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(slugs))
+	for slug := range slugs {
+		sorted = append(sorted, slug)
+	}
+	sort.Strings(sorted)
+
+	categories := make([]models.Category, 0, len(sorted))
+	for _, slug := range sorted {
+		detail := b.details[slug]
+		name := detail.Name
+		if name == "" {
+			name = models.DisplayName(slug)
+		}
+
+		questions := models.Questions{}
+		for _, categoryQuestions := range Subtree(b.questions, slug) {
+			questions = append(questions, categoryQuestions...)
+		}
+
+		categories = append(categories, models.Category{
+			Slug:          slug,
+			Name:          name,
+			Description:   detail.Description,
+			Parent:        models.ParentCategory(slug),
+			QuestionCount: len(questions),
+			Difficulties:  models.DifficultiesOf(questions),
+		})
+	}
+	return categories
+}
+
+// Replace validates and swaps in an entirely new set of questions. An invalid set leaves the bank unchanged.
+func (b *Bank) Replace(questions map[string]models.Questions) error {
+	return b.update(func(map[string]models.Questions) (map[string]models.Questions, error) {
+		if err := Validate(questions); err != nil {
+			return nil, err
+		}
+		return copyQuestions(questions), nil
+	})
+}
+
+// ReplaceIf validates and swaps in an entirely new set of questions, as long as the bank still holds the questions
+// with the expected Hash. Otherwise it returns ErrChanged. The check and the swap are made under the write lock, so no
+// other change can land between them.
+func (b *Bank) ReplaceIf(expectedHash string, questions map[string]models.Questions) error {
+	return b.update(func(current map[string]models.Questions) (map[string]models.Questions, error) {
+		if Hash(current) != expectedHash {
+			return nil, ErrChanged
+		}
+
+		if err := Validate(questions); err != nil {
+			return nil, err
+		}
+		return copyQuestions(questions), nil
+	})
+}
+
+// AddQuestion validates and stores a new question. A question ID of zero is replaced with the next free ID.
+func (b *Bank) AddQuestion(question models.Question) (models.Question, error) {
+	err := b.update(func(questions map[string]models.Questions) (map[string]models.Questions, error) {
+		if question.ID == 0 {
+			question.ID = nextQuestionID(questions)
+		}
+
+		if _, _, ok := findQuestion(questions, question.ID); ok {
+			return nil, ErrDuplicateQuestionID
+		}
+
+		if err := validateQuestion(questions, question); err != nil {
+			return nil, err
+		}
+
+		questions[question.Category] = append(questions[question.Category], question)
+		return questions, nil
+	})
+
+	return question, err
+}
+
+// UpdateQuestion validates and replaces the question with the same ID, which may move it to another category
+func (b *Bank) UpdateQuestion(question models.Question) (models.Question, error) {
+	err := b.update(func(questions map[string]models.Questions) (map[string]models.Questions, error) {
+		category, index, ok := findQuestion(questions, question.ID)
+		if !ok {
+			return nil, ErrQuestionNotFound
+		}
+
+		if err := validateQuestion(questions, question); err != nil {
+			return nil, err
+		}
+
+		if category == question.Category {
+			questions[category][index] = question
+		} else {
+			questions[category] = removeQuestion(questions[category], index)
+			questions[question.Category] = append(questions[question.Category], question)
+		}
+		return questions, nil
+	})
+
+	return question, err
+}
+
+// DeleteQuestion removes the question with the specified ID
+func (b *Bank) DeleteQuestion(id int) error {
+	return b.update(func(questions map[string]models.Questions) (map[string]models.Questions, error) {
+		category, index, ok := findQuestion(questions, id)
+		if !ok {
+			return nil, ErrQuestionNotFound
+		}
+
+		questions[category] = removeQuestion(questions[category], index)
+		return questions, nil
+	})
+}
+
+// AddCategory creates a new empty category
+func (b *Bank) AddCategory(name string) error {
+	return b.update(func(questions map[string]models.Questions) (map[string]models.Questions, error) {
+		if err := ValidateCategoryName(name); err != nil {
+			return nil, err
+		}
+
+		if _, ok := questions[name]; ok {
+			return nil, ErrCategoryExists
+		}
+
+		questions[name] = models.Questions{}
+		return questions, nil
+	})
+}
+
+// RenameCategory renames a category along with every one of its subcategories, moving their questions and details
+// to the new names. The optional move function is called once the rename has been checked, to move anything else
+// held under the old names, such as scores. The whole rename holds the write lock, and the questions and details are
+// swapped in together, so no other change or reader sees it half done.
+func (b *Bank) RenameCategory(name string, newName string, move func() error) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+
+	questions := copyQuestions(b.Questions())
+	subtree := Subtree(questions, name)
+	if len(subtree) == 0 {
+		return ErrCategoryNotFound
+	}
+
+	if err := ValidateCategoryName(newName); err != nil {
+		return err
+	}
+
+	// Categories within the new name which are not being renamed would be merged with the renamed ones
+	for category := range Subtree(questions, newName) {
+		if _, ok := subtree[category]; !ok {
+			return ErrCategoryExists
+		}
+	}
+
+	for category := range subtree {
+		delete(questions, category)
+	}
+	for category, categoryQuestions := range subtree {
+		renamed := newName + strings.TrimPrefix(category, name)
+		for i := range categoryQuestions {
+			categoryQuestions[i].Category = renamed
+		}
+		questions[renamed] = categoryQuestions
+	}
+
+	b.mu.RLock()
+	details := make(map[string]models.CategoryDetails, len(b.details))
+	for category, detail := range b.details {
+		if models.IsWithin(category, name) {
+			category = newName + strings.TrimPrefix(category, name)
+		}
+		details[category] = detail
+	}
+	b.mu.RUnlock()
+
+	if move != nil {
+		if err := move(); err != nil {
+			return err
+		}
+	}
+
+	if b.persister != nil {
+		if err := b.persister.ReplaceQuestions(questions); err != nil {
+			return fmt.Errorf("failed to persist question bank: %w", err)
+		}
+	}
+
+	b.mu.Lock()
+	b.questions = questions
+	b.details = details
+	b.mu.Unlock()
+
+	return nil
+}
+
+// DeleteCategory removes a category along with every one of its subcategories, as long as none of them contain questions
+func (b *Bank) DeleteCategory(name string) error {
+	err := b.update(func(questions map[string]models.Questions) (map[string]models.Questions, error) {
+		subtree := Subtree(questions, name)
+		if len(subtree) == 0 {
+			return nil, ErrCategoryNotFound
+		}
+
+		for _, categoryQuestions := range subtree {
+			if len(categoryQuestions) > 0 {
+				return nil, ErrCategoryNotEmpty
+			}
+		}
+
+		for category := range subtree {
+			delete(questions, category)
+		}
+		return questions, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for category := range b.details {
+		if models.IsWithin(category, name) {
+			delete(b.details, category)
+		}
+	}
+	return nil
+}
+
+// update applies a change to a copy of the bank, persists the result and then swaps it in.
+// Writers are serialised so that no change is lost, while readers are only blocked for the swap.
+func (b *Bank) update(change func(questions map[string]models.Questions) (map[string]models.Questions, error)) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+
+	updated, err := change(copyQuestions(b.Questions()))
+	if err != nil {
+		return err
+	}
+
+	if b.persister != nil {
+		if err := b.persister.ReplaceQuestions(updated); err != nil {
+			return fmt.Errorf("failed to persist question bank: %w", err)
+		}
+	}
+
+	b.mu.Lock()
+	b.questions = updated
+	b.mu.Unlock()
+
+	return nil
+}
+
+// Hash returns a hex-encoded SHA-256 hash which identifies a set of questions regardless of the order of each
+// category's questions
+func Hash(questions map[string]models.Questions) string {
+	sorted := make(map[string]models.Questions, len(questions))
+	for category, qs := range questions {
+		sorted[category] = append(models.Questions{}, qs...)
+		sort.Slice(sorted[category], func(i, j int) bool { return sorted[category][i].ID < sorted[category][j].ID })
+	}
+
+	// Maps are marshalled with sorted keys, so equal questions always produce the same JSON
+	data, err := json.Marshal(sorted)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadFile reads the questions for each category from a JSON file
+func ReadFile(filename string) (map[string]models.Questions, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	var questions map[string]models.Questions
+	if err := json.Unmarshal(data, &questions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON within file %s: %w", filename, err)
+	}
+
+	return questions, nil
+}
+
+// ReadDetailsFile reads the display name and description of each category from a JSON file. A missing file
+// gives every category its default name and no description.
+func ReadDetailsFile(filename string) (map[string]models.CategoryDetails, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]models.CategoryDetails{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	var details map[string]models.CategoryDetails
+	if err := json.Unmarshal(data, &details); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON within file %s: %w", filename, err)
+	}
+
+	return details, nil
+}
+
+// Validate checks every category and question within a complete set of questions and reports all problems found
+func Validate(questions map[string]models.Questions) error {
+	var problems []error
+	seenIDs := make(map[int]string)
+
+	for _, category := range sortedCategories(questions) {
+		if err := ValidateCategoryName(category); err != nil {
+			problems = append(problems, fmt.Errorf("category '%s': %w", category, err))
+		}
+
+		for _, question := range questions[category] {
+			for _, problem := range question.Problems() {
+				problems = append(problems, fmt.Errorf("category '%s', question %d: %w: %s", category, question.ID, models.ErrInvalidQuestion, problem.Message))
+			}
+
+			if question.Category != category {
+				problems = append(problems, fmt.Errorf("category '%s', question %d: %w: category '%s' does not match", category, question.ID, models.ErrInvalidQuestion, question.Category))
+			}
+
+			if other, ok := seenIDs[question.ID]; ok {
+				problems = append(problems, fmt.Errorf("category '%s', question %d: %w (also used in category '%s')", category, question.ID, ErrDuplicateQuestionID, other))
+			} else {
+				seenIDs[question.ID] = category
+			}
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// validateQuestion checks that a question is complete and belongs to an existing category
+func validateQuestion(questions map[string]models.Questions, question models.Question) error {
+	if err := question.Validate(); err != nil {
+		return err
+	}
+
+	if _, ok := questions[question.Category]; !ok {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
+
+// ValidateCategoryName checks that a category name is non-empty, lower case, not reserved and
+// free of the characters used to combine categories and difficulties. Subcategories name each level
+// of the tree separated by "/", such as "science/physics", and every level must be non-empty.
+func ValidateCategoryName(name string) error {
+	if len(name) == 0 || name != strings.ToLower(strings.TrimSpace(name)) || strings.ContainsAny(name, ",+:") {
+		return ErrInvalidCategory
+	}
+
+	levels := strings.Split(name, models.CategorySeparator)
+	if levels[0] == "random" {
+		return ErrInvalidCategory
+	}
+	for _, level := range levels {
+		if len(level) == 0 || level != strings.TrimSpace(level) {
+			return ErrInvalidCategory
+		}
+	}
+	return nil
+}
+
+// Subtree returns the questions of a category and every one of its subcategories, keyed by category.
+// A category which only has subcategories is not included itself.
+func Subtree(questions map[string]models.Questions, name string) map[string]models.Questions {
+	subtree := make(map[string]models.Questions)
+	for category, categoryQuestions := range questions {
+		if models.IsWithin(category, name) {
+			subtree[category] = categoryQuestions
+		}
+	}
+	return subtree
+}
+
+// sortedCategories returns the category names in alphabetical order
+func sortedCategories(questions map[string]models.Questions) []string {
+	categories := make([]string, 0, len(questions))
+	for category := range questions {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// findQuestion returns the category and index of the question with the specified ID
+func findQuestion(questions map[string]models.Questions, id int) (string, int, bool) {
+	for category, qs := range questions {
+		for i, question := range qs {
+			if question.ID == id {
+				return category, i, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// nextQuestionID returns an ID which is greater than every existing question ID
+func nextQuestionID(questions map[string]models.Questions) int {
+	maxID := 0
+	for _, qs := range questions {
+		for _, question := range qs {
+			if question.ID > maxID {
+				maxID = question.ID
+			}
+		}
+	}
+	return maxID + 1
+}
+
+// removeQuestion returns the questions without the question at the specified index
+func removeQuestion(questions models.Questions, index int) models.Questions {
+	updated := make(models.Questions, 0, len(questions)-1)
+	updated = append(updated, questions[:index]...)
+	return append(updated, questions[index+1:]...)
+}
+
+// copyQuestions returns a copy of the bank which can be modified without affecting readers
+func copyQuestions(questions map[string]models.Questions) map[string]models.Questions {
+	cpy := make(map[string]models.Questions, len(questions))
+	for category, qs := range questions {
+		cpy[category] = append(models.Questions{}, qs...)
+	}
+	return cpy
+}
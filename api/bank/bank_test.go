@@ -0,0 +1,401 @@
+package bank
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quizwizard/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPersister records the last persisted bank and can be made to fail
+type mockPersister struct {
+	persisted map[string]models.Questions
+	err       error
+}
+
+// ReplaceQuestions records the persisted bank unless the persister is set to fail
+func (p *mockPersister) ReplaceQuestions(questions map[string]models.Questions) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.persisted = questions
+	return nil
+}
+
+// getTestQuestions is a helper function which returns a fresh copy of test questions
+func getTestQuestions() map[string]models.Questions {
+	return map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+		},
+		"math": {
+			{ID: 3, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4", "5", "6"}, CorrectAnswerIndex: 1},
+		},
+		"history": {},
+	}
+}
+
+// TestAddQuestion tests adding questions to the bank
+func TestAddQuestion(t *testing.T) {
+	tests := []struct {
+		name          string
+		question      models.Question
+		expectedID    int
+		expectedError error
+	}{
+		{
+			name:       "success_assigns_next_id",
+			question:   models.Question{Category: "history", Question: "In which year did the Titanic sink?", Answers: []string{"1912", "1913"}, CorrectAnswerIndex: 0},
+			expectedID: 4,
+		},
+		{
+			name:       "success_with_explicit_id",
+			question:   models.Question{ID: 10, Category: "math", Question: "What is 3 + 3?", Answers: []string{"6", "7"}, CorrectAnswerIndex: 0},
+			expectedID: 10,
+		},
+		{
+			name:          "failure_duplicate_id",
+			question:      models.Question{ID: 1, Category: "math", Question: "What is 3 + 3?", Answers: []string{"6", "7"}, CorrectAnswerIndex: 0},
+			expectedError: ErrDuplicateQuestionID,
+		},
+		{
+			name:          "failure_unknown_category",
+			question:      models.Question{Category: "music", Question: "Who sang 'Thriller'?", Answers: []string{"Prince", "Michael Jackson"}, CorrectAnswerIndex: 1},
+			expectedError: ErrCategoryNotFound,
+		},
+		{
+			name:          "failure_correct_answer_out_of_range",
+			question:      models.Question{Category: "math", Question: "What is 3 + 3?", Answers: []string{"6", "7"}, CorrectAnswerIndex: 2},
+			expectedError: models.ErrInvalidQuestion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			persister := &mockPersister{}
+			b := New(getTestQuestions(), persister)
+
+			question, err := b.AddQuestion(tt.question)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, getTestQuestions(), b.Questions(), "A failed change must leave the bank unchanged")
+				assert.Nil(t, persister.persisted)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedID, question.ID)
+
+			stored := b.Questions()[tt.question.Category]
+			assert.Equal(t, question, stored[len(stored)-1])
+			assert.Equal(t, b.Questions(), persister.persisted)
+		})
+	}
+}
+
+// TestUpdateQuestion tests replacing questions within the bank
+func TestUpdateQuestion(t *testing.T) {
+	b := New(getTestQuestions(), nil)
+
+	updated := models.Question{ID: 2, Category: "science", Question: "Which planet is largest?", Answers: []string{"Earth", "Jupiter"}, CorrectAnswerIndex: 1}
+	_, err := b.UpdateQuestion(updated)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, b.Questions()["science"][1])
+
+	moved := models.Question{ID: 1, Category: "history", Question: "Who was the first president of the United States?", Answers: []string{"George Washington", "John Adams"}, CorrectAnswerIndex: 0}
+	_, err = b.UpdateQuestion(moved)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Questions{updated}, b.Questions()["science"])
+	assert.Equal(t, models.Questions{moved}, b.Questions()["history"])
+
+	_, err = b.UpdateQuestion(models.Question{ID: 99, Category: "math", Question: "?", Answers: []string{"1", "2"}})
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+}
+
+// TestDeleteQuestion tests removing questions from the bank
+func TestDeleteQuestion(t *testing.T) {
+	b := New(getTestQuestions(), nil)
+	before := b.Questions()
+
+	assert.NoError(t, b.DeleteQuestion(1))
+	assert.Len(t, b.Questions()["science"], 1)
+	assert.Equal(t, 2, b.Questions()["science"][0].ID)
+	assert.Len(t, before["science"], 2, "Earlier snapshots must not be modified")
+
+	assert.ErrorIs(t, b.DeleteQuestion(1), ErrQuestionNotFound)
+}
+
+// TestCategories tests creating, renaming and deleting categories
+func TestCategories(t *testing.T) {
+	b := New(getTestQuestions(), nil)
+
+	assert.NoError(t, b.AddCategory("music"))
+	assert.ErrorIs(t, b.AddCategory("music"), ErrCategoryExists)
+	assert.ErrorIs(t, b.AddCategory("random"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory(""), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("Music"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("music+art"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("music,art"), ErrInvalidCategory)
+	assert.NoError(t, b.AddCategory("science/physics"))
+	assert.ErrorIs(t, b.AddCategory("science//optics"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("/science"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("science/"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("science/ optics"), ErrInvalidCategory)
+	assert.ErrorIs(t, b.AddCategory("random/music"), ErrInvalidCategory)
+
+	assert.NoError(t, b.RenameCategory("math", "maths", nil))
+	assert.NotContains(t, b.Questions(), "math")
+	assert.Equal(t, "maths", b.Questions()["maths"][0].Category)
+	assert.ErrorIs(t, b.RenameCategory("math", "sums", nil), ErrCategoryNotFound)
+	assert.ErrorIs(t, b.RenameCategory("maths", "science", nil), ErrCategoryExists)
+
+	assert.ErrorIs(t, b.DeleteCategory("science"), ErrCategoryNotEmpty)
+	assert.NoError(t, b.DeleteCategory("music"))
+	assert.ErrorIs(t, b.DeleteCategory("music"), ErrCategoryNotFound)
+}
+
+// TestSubcategoryRenameAndDelete tests that renaming or deleting a category also acts on its subcategories
+func TestSubcategoryRenameAndDelete(t *testing.T) {
+	questions := getTestQuestions()
+	questions["science/physics"] = models.Questions{
+		{ID: 4, Category: "science/physics", Question: "What is the unit of force?", Answers: []string{"Newton", "Joule"}, CorrectAnswerIndex: 0},
+	}
+	questions["languages/french/verbs"] = models.Questions{}
+	questions["languages/german"] = models.Questions{
+		{ID: 5, Category: "languages/german", Question: "What is 'dog' in German?", Answers: []string{"Hund", "Katze"}, CorrectAnswerIndex: 0},
+	}
+	b := New(questions, nil)
+	b.SetDetails(map[string]models.CategoryDetails{
+		"science/physics":  {Name: "Physics", Description: "Forces and energy."},
+		"languages/french": {Name: "Français"},
+	})
+
+	assert.NoError(t, b.RenameCategory("science", "sciences", nil))
+	assert.NotContains(t, b.Questions(), "science")
+	assert.NotContains(t, b.Questions(), "science/physics")
+	assert.Equal(t, "sciences/physics", b.Questions()["sciences/physics"][0].Category)
+	assert.Equal(t, "sciences", b.Questions()["sciences"][0].Category)
+
+	// A parent which only has subcategories can be renamed, and details follow every renamed category
+	assert.NoError(t, b.RenameCategory("languages", "linguistics", nil))
+	assert.Contains(t, b.Questions(), "linguistics/french/verbs")
+	assert.Contains(t, b.Questions(), "linguistics/german")
+	categories := make(map[string]models.Category)
+	for _, category := range b.Categories() {
+		categories[category.Slug] = category
+	}
+	assert.Equal(t, "Physics", categories["sciences/physics"].Name)
+	assert.Equal(t, "Français", categories["linguistics/french"].Name)
+
+	// Renaming into a category which already has subcategories would merge them
+	assert.ErrorIs(t, b.RenameCategory("math", "sciences", nil), ErrCategoryExists)
+	assert.ErrorIs(t, b.RenameCategory("history", "linguistics", nil), ErrCategoryExists)
+	assert.ErrorIs(t, b.RenameCategory("languages", "words", nil), ErrCategoryNotFound)
+
+	// Deleting a category removes every one of its subcategories, but only if all of them are empty
+	assert.ErrorIs(t, b.DeleteCategory("linguistics"), ErrCategoryNotEmpty)
+	assert.NoError(t, b.DeleteQuestion(5))
+	assert.NoError(t, b.DeleteCategory("linguistics"))
+	assert.NotContains(t, b.Questions(), "linguistics/french/verbs")
+	assert.NotContains(t, b.Questions(), "linguistics/german")
+
+	assert.NoError(t, b.AddCategory("linguistics/french"))
+	for _, category := range b.Categories() {
+		if category.Slug == "linguistics/french" {
+			assert.Equal(t, "French", category.Name, "Expected the details of deleted subcategories to be removed")
+		}
+	}
+}
+
+// TestRenameCategoryMovesWithBank checks that the move function is called for a valid rename and that the bank is
+// left unchanged when it fails
+func TestRenameCategoryMovesWithBank(t *testing.T) {
+	b := New(getTestQuestions(), nil)
+	b.SetDetails(map[string]models.CategoryDetails{"science": {Name: "Natural Science"}})
+
+	moves := 0
+	assert.ErrorIs(t, b.RenameCategory("science", "math", func() error { moves++; return nil }), ErrCategoryExists)
+	assert.Equal(t, 0, moves, "Expected an invalid rename not to move anything")
+
+	assert.EqualError(t, b.RenameCategory("science", "physics", func() error { return errors.New("database is locked") }), "database is locked")
+	assert.Contains(t, b.Questions(), "science")
+	assert.NotContains(t, b.Questions(), "physics")
+	assert.Equal(t, "Natural Science", b.Categories()[2].Name)
+
+	assert.NoError(t, b.RenameCategory("science", "physics", func() error { moves++; return nil }))
+	assert.Equal(t, 1, moves)
+	assert.Contains(t, b.Questions(), "physics")
+	assert.Equal(t, "Natural Science", b.Categories()[2].Name)
+}
+
+// TestCategoryDetails tests listing categories with their details, which follow categories when they are renamed
+func TestCategoryDetails(t *testing.T) {
+	questions := getTestQuestions()
+	questions["science"][1].Difficulty = "hard"
+	b := New(questions, nil)
+	b.SetDetails(map[string]models.CategoryDetails{
+		"science": {Name: "Natural Science", Description: "The world around us."},
+		"music":   {Name: "Music"},
+	})
+
+	expected := []models.Category{
+		{Slug: "history", Name: "History", Difficulties: []string{}},
+		{Slug: "math", Name: "Math", QuestionCount: 1, Difficulties: []string{"medium"}},
+		{Slug: "science", Name: "Natural Science", Description: "The world around us.", QuestionCount: 2, Difficulties: []string{"medium", "hard"}},
+	}
+	assert.Equal(t, expected, b.Categories())
+
+	assert.NoError(t, b.RenameCategory("science", "physics", nil))
+	assert.Equal(t, "Natural Science", b.Categories()[2].Name, "Expected the details to follow the renamed category")
+
+	assert.NoError(t, b.DeleteCategory("history"))
+	assert.NoError(t, b.AddCategory("history"))
+	assert.Equal(t, "History", b.Categories()[0].Name)
+}
+
+// TestSubcategories tests listing parents of subcategories and selecting the questions within a category
+func TestSubcategories(t *testing.T) {
+	questions := getTestQuestions()
+	questions["science/physics"] = models.Questions{
+		{ID: 4, Category: "science/physics", Question: "What is the unit of force?", Answers: []string{"Newton", "Joule"}, CorrectAnswerIndex: 0, Difficulty: "easy"},
+	}
+	questions["languages/french/verbs"] = models.Questions{}
+	b := New(questions, nil)
+
+	expected := []models.Category{
+		{Slug: "history", Name: "History", Difficulties: []string{}},
+		{Slug: "languages", Name: "Languages", Difficulties: []string{}},
+		{Slug: "languages/french", Name: "French", Parent: "languages", Difficulties: []string{}},
+		{Slug: "languages/french/verbs", Name: "Verbs", Parent: "languages/french", Difficulties: []string{}},
+		{Slug: "math", Name: "Math", QuestionCount: 1, Difficulties: []string{"medium"}},
+		{Slug: "science", Name: "Science", QuestionCount: 3, Difficulties: []string{"easy", "medium"}},
+		{Slug: "science/physics", Name: "Physics", Parent: "science", QuestionCount: 1, Difficulties: []string{"easy"}},
+	}
+	assert.Equal(t, expected, b.Categories())
+
+	assert.Equal(t, []string{"science", "science/physics"}, sortedCategories(Subtree(questions, "science")))
+	assert.Equal(t, []string{"science/physics"}, sortedCategories(Subtree(questions, "science/physics")))
+	assert.Equal(t, []string{"languages/french/verbs"}, sortedCategories(Subtree(questions, "languages")))
+	assert.Empty(t, Subtree(questions, "sci"))
+}
+
+// TestReadDetailsFile tests reading category details from a file, which is optional
+func TestReadDetailsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	details, err := ReadDetailsFile(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, details)
+
+	filename := filepath.Join(dir, "categories.json")
+	assert.NoError(t, os.WriteFile(filename, []byte(`{"music": {"name": "Music", "description": "Songs and instruments."}}`), 0644))
+	details, err = ReadDetailsFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]models.CategoryDetails{"music": {Name: "Music", Description: "Songs and instruments."}}, details)
+
+	assert.NoError(t, os.WriteFile(filename, []byte(`["music"]`), 0644))
+	_, err = ReadDetailsFile(filename)
+	assert.ErrorContains(t, err, "failed to unmarshal JSON")
+}
+
+// TestPersistFailureLeavesBankUnchanged checks that changes are only swapped in once they have been persisted
+func TestPersistFailureLeavesBankUnchanged(t *testing.T) {
+	b := New(getTestQuestions(), &mockPersister{err: errors.New("disk full")})
+
+	err := b.DeleteQuestion(1)
+
+	assert.ErrorContains(t, err, "disk full")
+	assert.Equal(t, getTestQuestions(), b.Questions())
+}
+
+// TestValidate tests validating a complete set of questions
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(getTestQuestions()))
+
+	questions := getTestQuestions()
+	questions["math"] = append(questions["math"],
+		models.Question{ID: 1, Category: "math", Question: "What is 3 + 3?", Answers: []string{"6", "7"}, CorrectAnswerIndex: 0},
+		models.Question{ID: 4, Category: "science", Question: "What is 4 + 4?", Answers: []string{"8", "9"}, CorrectAnswerIndex: 3},
+	)
+	questions["Random"] = models.Questions{}
+
+	err := Validate(questions)
+
+	assert.ErrorIs(t, err, ErrDuplicateQuestionID)
+	assert.ErrorIs(t, err, ErrInvalidCategory)
+	assert.ErrorIs(t, err, models.ErrInvalidQuestion)
+	assert.EqualError(t, err, "category 'Random': category name is invalid\n"+
+		"category 'math', question 4: question is invalid: correctAnswerIndex 3 is out of range\n"+
+		"category 'math', question 4: question is invalid: category 'science' does not match\n"+
+		"category 'science', question 1: question ID is already in use (also used in category 'math')")
+}
+
+// TestReplace checks that an invalid set of questions is rejected
+func TestReplace(t *testing.T) {
+	b := New(getTestQuestions(), nil)
+
+	invalid := map[string]models.Questions{"music": {{ID: 1, Category: "music"}}}
+	assert.ErrorIs(t, b.Replace(invalid), models.ErrInvalidQuestion)
+	assert.Equal(t, getTestQuestions(), b.Questions())
+
+	replacement := getTestQuestions()
+	delete(replacement, "history")
+	assert.NoError(t, b.Replace(replacement))
+	assert.Equal(t, replacement, b.Questions())
+}
+
+// TestReplaceIf tests replacing the questions only while the bank holds the expected questions
+func TestReplaceIf(t *testing.T) {
+	b := New(getTestQuestions(), nil)
+	expected := Hash(b.Questions())
+
+	replacement := getTestQuestions()
+	delete(replacement, "history")
+
+	_, err := b.AddQuestion(models.Question{Category: "math", Question: "What is 3 + 3?", Answers: []string{"6", "7"}, CorrectAnswerIndex: 0})
+	assert.NoError(t, err)
+	edited := b.Questions()
+	assert.ErrorIs(t, b.ReplaceIf(expected, replacement), ErrChanged)
+	assert.Equal(t, edited, b.Questions())
+
+	assert.NoError(t, b.ReplaceIf(Hash(edited), replacement))
+	assert.Equal(t, replacement, b.Questions())
+}
+
+// TestHash tests that equal questions have the same hash regardless of their order
+func TestHash(t *testing.T) {
+	questions := getTestQuestions()
+	reordered := getTestQuestions()
+	reordered["science"][0], reordered["science"][1] = reordered["science"][1], reordered["science"][0]
+	assert.Equal(t, Hash(questions), Hash(reordered))
+
+	reordered["science"][0].Question = "What is H2O?"
+	assert.NotEqual(t, Hash(questions), Hash(reordered))
+}
+
+// TestCompare tests summarising the differences between two versions of the bank
+func TestCompare(t *testing.T) {
+	old := getTestQuestions()
+	updated := getTestQuestions()
+	delete(updated, "history")
+	updated["science"][0].Question = "What is H2O?"
+	updated["math"] = append(updated["math"], models.Question{ID: 4, Category: "math"})
+	updated["music"] = models.Questions{{ID: 5, Category: "music"}}
+	updated["science"] = updated["science"][:1]
+
+	diff := Compare(old, updated)
+
+	assert.Equal(t, Diff{
+		AddedCategories:   []string{"music"},
+		RemovedCategories: []string{"history"},
+		AddedQuestions:    2,
+		RemovedQuestions:  1,
+		ChangedQuestions:  1,
+	}, diff)
+	assert.Equal(t, "2 questions added, 1 removed, 1 changed; categories added: music; categories removed: history", diff.String())
+}
@@ -0,0 +1,78 @@
+package bank
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"quizwizard/api/models"
+)
+
+// Diff summarises the differences between two versions of the question bank
+type Diff struct {
+	AddedCategories   []string
+	RemovedCategories []string
+	AddedQuestions    int
+	RemovedQuestions  int
+	ChangedQuestions  int
+}
+
+// Compare returns the differences between an old and a new version of the question bank
+func Compare(old map[string]models.Questions, updated map[string]models.Questions) Diff {
+	var diff Diff
+
+	for _, category := range sortedCategories(updated) {
+		if _, ok := old[category]; !ok {
+			diff.AddedCategories = append(diff.AddedCategories, category)
+		}
+	}
+	for _, category := range sortedCategories(old) {
+		if _, ok := updated[category]; !ok {
+			diff.RemovedCategories = append(diff.RemovedCategories, category)
+		}
+	}
+
+	oldQuestions := questionsByID(old)
+	updatedQuestions := questionsByID(updated)
+
+	for id, question := range updatedQuestions {
+		oldQuestion, ok := oldQuestions[id]
+		if !ok {
+			diff.AddedQuestions++
+		} else if !reflect.DeepEqual(oldQuestion, question) {
+			diff.ChangedQuestions++
+		}
+	}
+	for id := range oldQuestions {
+		if _, ok := updatedQuestions[id]; !ok {
+			diff.RemovedQuestions++
+		}
+	}
+
+	return diff
+}
+
+// String describes the differences in a single line
+func (d Diff) String() string {
+	summary := fmt.Sprintf("%d questions added, %d removed, %d changed", d.AddedQuestions, d.RemovedQuestions, d.ChangedQuestions)
+
+	if len(d.AddedCategories) > 0 {
+		summary += "; categories added: " + strings.Join(d.AddedCategories, ", ")
+	}
+	if len(d.RemovedCategories) > 0 {
+		summary += "; categories removed: " + strings.Join(d.RemovedCategories, ", ")
+	}
+
+	return summary
+}
+
+// questionsByID indexes every question in the bank by its ID
+func questionsByID(questions map[string]models.Questions) map[int]models.Question {
+	index := make(map[int]models.Question)
+	for _, qs := range questions {
+		for _, question := range qs {
+			index[question.ID] = question
+		}
+	}
+	return index
+}
@@ -1,9 +1,39 @@
 package globals
 
-import "quizwizard/api/models"
+import (
+	"time"
 
-// Questions stores questions for each category
-var Questions = make(map[string]models.Questions)
+	"quizwizard/api/accounts"
+	"quizwizard/api/bank"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+	"quizwizard/api/scoring"
+	"quizwizard/api/sessions"
+)
 
-// CategoryScores stores percentage scores for each category
-var CategoryScores = make(map[string][]float64)
+// Bank stores the live questions for each category
+var Bank = bank.New(nil, nil)
+
+// Scores stores percentage scores for each category
+var Scores scores.ScoreStore = scores.NewMemoryStore()
+
+// Leaderboard ranks the scores of named players
+var Leaderboard scores.Leaderboard = scores.NewMemoryLeaderboard()
+
+// Sessions stores the quiz sessions which are awaiting submission
+var Sessions = sessions.NewStore(time.Hour)
+
+// QuizLength limits the number of questions issued for each quiz
+var QuizLength = models.QuizLength{Min: 1, Max: 20, Default: 5}
+
+// TimeLimits sets the number of seconds allowed to answer each question
+var TimeLimits = models.TimeLimits{}
+
+// Scoring selects the scoring strategy for each category
+var Scoring = scoring.Strategies{}
+
+// Accounts stores registered users and the results of their quizzes
+var Accounts accounts.Store = accounts.NewMemoryStore()
+
+// Tokens issues the access tokens of users who log in
+var Tokens = accounts.Tokens{TTL: accounts.DefaultTokenTTL}
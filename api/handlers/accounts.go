@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/globals"
+	"quizwizard/api/models"
+
+	"github.com/labstack/echo"
+)
+
+// userContextKey is the context key under which the username of an authenticated request is stored
+const userContextKey = "user"
+
+// bearerPrefix precedes the access token within the Authorization header
+const bearerPrefix = "Bearer "
+
+// Authenticate returns middleware which checks the signed access token sent as a bearer token in the Authorization header.
+// When the token is required, requests without one are rejected. Otherwise they continue anonymously, but a token
+// which is sent must still be valid.
+func Authenticate(tokens accounts.Tokens, required bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if header == "" {
+				if !required {
+					return next(c)
+				}
+				msg := "An access token must be provided. Please log in first."
+				return prepareErrorResponse(c, CodeUnauthorized, msg, http.StatusUnauthorized)
+			}
+
+			token, ok := strings.CutPrefix(header, bearerPrefix)
+			username, err := tokens.Parse(token)
+			if !ok || err != nil {
+				msg := "A valid access token must be provided. Please log in again."
+				return prepareErrorResponse(c, CodeUnauthorized, msg, http.StatusUnauthorized)
+			}
+
+			if _, err := globals.Accounts.Find(username); err != nil {
+				msg := "The account for this access token no longer exists. Please log in again."
+				return prepareErrorResponse(c, CodeUnauthorized, msg, http.StatusUnauthorized)
+			}
+
+			c.Set(userContextKey, username)
+			return next(c)
+		}
+	}
+}
+
+// Register creates an account with a bcrypt hash of the chosen password
+func Register(c echo.Context) error {
+	var credentials models.Credentials
+	err := c.Bind(&credentials)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+
+	username, err := accounts.NormaliseUsername(credentials.Username)
+	if err != nil {
+		msg := "Invalid username: " + err.Error() + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "username", Message: msg})
+	}
+
+	if err := accounts.ValidatePassword(credentials.Password); err != nil {
+		msg := "Invalid password: " + err.Error() + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "password", Message: msg})
+	}
+
+	hash, err := accounts.HashPassword(credentials.Password)
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	user, err := globals.Accounts.Create(accounts.User{Username: username, PasswordHash: hash, CreatedAt: time.Now()})
+	if errors.Is(err, accounts.ErrUsernameTaken) {
+		msg := "Username " + username + " is already taken."
+		return prepareErrorResponse(c, CodeUsernameTaken, msg, http.StatusConflict)
+	} else if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	msg := "Account " + user.Username + " registered successfully."
+	return prepareResponse(c, true, msg, http.StatusCreated, user)
+}
+
+// Login checks a user's password and issues a signed access token
+func Login(c echo.Context) error {
+	var credentials models.Credentials
+	err := c.Bind(&credentials)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+
+	// Usernames which could never have been registered are treated like any other unknown username
+	username, _ := accounts.NormaliseUsername(credentials.Username)
+
+	user, err := accounts.Authenticate(globals.Accounts, username, credentials.Password)
+	if errors.Is(err, accounts.ErrInvalidCredentials) {
+		msg := "Incorrect username or password."
+		return prepareErrorResponse(c, CodeInvalidCredentials, msg, http.StatusUnauthorized)
+	} else if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	token, expiresAt, err := globals.Tokens.Issue(user, time.Now())
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	res := models.AccessToken{
+		Token:     token,
+		Username:  user.Username,
+		ExpiresAt: expiresAt,
+	}
+	return prepareResponse(c, true, "Logged in successfully.", http.StatusOK, res)
+}
+
+// GetAccount returns the account of the authenticated user
+func GetAccount(c echo.Context) error {
+	user, err := globals.Accounts.Find(currentUser(c))
+	if err != nil {
+		msg := "An access token must be provided. Please log in first."
+		return prepareErrorResponse(c, CodeUnauthorized, msg, http.StatusUnauthorized)
+	}
+
+	return prepareResponse(c, true, "Account retrieved successfully.", http.StatusOK, user)
+}
+
+// currentUser returns the username of the authenticated user, or an empty string if the request is anonymous
+func currentUser(c echo.Context) string {
+	username, _ := c.Get(userContextKey).(string)
+	return username
+}
+
+// ownsSession reports whether the request may use a quiz session. Sessions started by a logged in user may only
+// be used by the same user, while anonymous sessions may be used by anyone holding their ID.
+func ownsSession(c echo.Context, session models.QuizSession) bool {
+	return session.Username == "" || session.Username == currentUser(c)
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/bank"
+	"quizwizard/api/globals"
+	"quizwizard/api/models"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// useTestAccounts is a helper function which replaces the account store and token settings with fresh ones
+// containing the user "ada" with the password "correct horse", restoring the originals when the test ends
+func useTestAccounts(t *testing.T) accounts.Tokens {
+	originalAccounts, originalTokens, originalCost := globals.Accounts, globals.Tokens, accounts.PasswordCost
+	t.Cleanup(func() {
+		globals.Accounts, globals.Tokens, accounts.PasswordCost = originalAccounts, originalTokens, originalCost
+	})
+
+	accounts.PasswordCost = bcrypt.MinCost
+	globals.Accounts = accounts.NewMemoryStore()
+	globals.Tokens = accounts.Tokens{Secret: []byte("test secret"), TTL: time.Hour}
+
+	hash, err := accounts.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("failed to hash test password: %v", err)
+	}
+	if _, err := globals.Accounts.Create(accounts.User{Username: "ada", PasswordHash: hash}); err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	return globals.Tokens
+}
+
+// issueTestToken is a helper function which signs an access token for a username
+func issueTestToken(t *testing.T, tokens accounts.Tokens, username string, now time.Time) string {
+	token, _, err := tokens.Issue(accounts.User{Username: username}, now)
+	if err != nil {
+		t.Fatalf("failed to issue test token: %v", err)
+	}
+	return token
+}
+
+// TestRegister tests the Register handler function
+func TestRegister(t *testing.T) {
+	useTestAccounts(t)
+
+	e := echo.New()
+	e.POST("/register", Register)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success_registered",
+			body:           `{"username": " Bob ", "password": "battery staple"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"success":true,"message":"Account bob registered successfully.","data":{"id":2,"username":"bob"`,
+		},
+		{
+			name:           "failure_due_to_invalid_format",
+			body:           `{"username": 1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"success":false,"code":"INVALID_REQUEST","message":"Invalid request format.","details":[{"field":"username","message":"Expected string but received number."}]}`,
+		},
+		{
+			name:           "failure_due_to_invalid_username",
+			body:           `{"username": "b", "password": "battery staple"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"success":false,"code":"VALIDATION_FAILED","message":"Invalid username: username must be between 3 and 32 characters.","details":[{"field":"username","message":"Invalid username: username must be between 3 and 32 characters."}]}`,
+		},
+		{
+			name:           "failure_due_to_invalid_password",
+			body:           `{"username": "cyd", "password": "short"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"success":false,"code":"VALIDATION_FAILED","message":"Invalid password: password must be at least 8 characters.","details":[{"field":"password","message":"Invalid password: password must be at least 8 characters."}]}`,
+		},
+		{
+			name:           "failure_due_to_taken_username",
+			body:           `{"username": "ADA", "password": "battery staple"}`,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"success":false,"code":"USERNAME_TAKEN","message":"Username ada is already taken."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			assert.NotContains(t, rec.Body.String(), "battery staple", "Expected the password to never be returned")
+		})
+	}
+
+	user, err := globals.Accounts.Find("bob")
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("battery staple")), "Expected the password to be stored as a bcrypt hash")
+}
+
+// TestLogin tests the Login handler function
+func TestLogin(t *testing.T) {
+	tokens := useTestAccounts(t)
+
+	e := echo.New()
+	e.POST("/login", Login)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"success_logged_in", `{"username": "Ada", "password": "correct horse"}`, http.StatusOK, `"success":true,"message":"Logged in successfully."`},
+		{"failure_due_to_invalid_format", `{"password": 1}`, http.StatusBadRequest, `{"success":false,"code":"INVALID_REQUEST","message":"Invalid request format.","details":[{"field":"password","message":"Expected string but received number."}]}`},
+		{"failure_due_to_wrong_password", `{"username": "ada", "password": "battery staple"}`, http.StatusUnauthorized, `{"success":false,"code":"INVALID_CREDENTIALS","message":"Incorrect username or password."}`},
+		{"failure_due_to_unknown_user", `{"username": "bob", "password": "correct horse"}`, http.StatusUnauthorized, `{"success":false,"code":"INVALID_CREDENTIALS","message":"Incorrect username or password."}`},
+		{"failure_due_to_invalid_username", `{"username": "a", "password": "correct horse"}`, http.StatusUnauthorized, `{"success":false,"code":"INVALID_CREDENTIALS","message":"Incorrect username or password."}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data models.AccessToken `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, "ada", res.Data.Username)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), res.Data.ExpiresAt, time.Minute)
+
+			username, err := tokens.Parse(res.Data.Token)
+			assert.NoError(t, err)
+			assert.Equal(t, "ada", username)
+		})
+	}
+}
+
+// TestAuthenticate checks that required and optional authentication accept only valid tokens for existing users
+func TestAuthenticate(t *testing.T) {
+	tokens := useTestAccounts(t)
+
+	e := echo.New()
+	e.GET("/me", GetAccount, Authenticate(tokens, true))
+	e.GET("/optional", func(c echo.Context) error {
+		return prepareResponse(c, true, "Hello "+currentUser(c)+".", http.StatusOK, nil)
+	}, Authenticate(tokens, false))
+
+	now := time.Now()
+	tests := []struct {
+		name           string
+		path           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"success_required_token", "/me", "Bearer " + issueTestToken(t, tokens, "ada", now), http.StatusOK, `"success":true,"message":"Account retrieved successfully.","data":{"id":1,"username":"ada"`},
+		{"success_optional_token", "/optional", "Bearer " + issueTestToken(t, tokens, "ada", now), http.StatusOK, `{"success":true,"message":"Hello ada."}`},
+		{"success_optional_without_token", "/optional", "", http.StatusOK, `{"success":true,"message":"Hello ."}`},
+		{"failure_due_to_missing_token", "/me", "", http.StatusUnauthorized, `{"success":false,"code":"UNAUTHORIZED","message":"An access token must be provided. Please log in first."}`},
+		{"failure_due_to_expired_token", "/me", "Bearer " + issueTestToken(t, tokens, "ada", now.Add(-2*time.Hour)), http.StatusUnauthorized, `{"success":false,"code":"UNAUTHORIZED","message":"A valid access token must be provided. Please log in again."}`},
+		{"failure_due_to_wrong_secret", "/optional", "Bearer " + issueTestToken(t, accounts.Tokens{Secret: []byte("other")}, "ada", now), http.StatusUnauthorized, `{"success":false,"code":"UNAUTHORIZED","message":"A valid access token must be provided. Please log in again."}`},
+		{"failure_due_to_missing_scheme", "/me", issueTestToken(t, tokens, "ada", now), http.StatusUnauthorized, `{"success":false,"code":"UNAUTHORIZED","message":"A valid access token must be provided. Please log in again."}`},
+		{"failure_due_to_unknown_user", "/me", "Bearer " + issueTestToken(t, tokens, "bob", now), http.StatusUnauthorized, `{"success":false,"code":"UNAUTHORIZED","message":"The account for this access token no longer exists. Please log in again."}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+// TestQuizSessionsBelongToUser checks that a quiz started by a logged in user can only be answered and submitted by them
+func TestQuizSessionsBelongToUser(t *testing.T) {
+	tokens := useTestAccounts(t)
+	_, err := globals.Accounts.Create(accounts.User{Username: "bob"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	originalBank, originalScores := globals.Bank, globals.Scores
+	defer func() { globals.Bank, globals.Scores = originalBank, originalScores }()
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{"science": {}})
+
+	auth := Authenticate(tokens, false)
+	e := echo.New()
+	e.GET("/questions", GetQuestions, auth)
+	e.POST("/sessions/:id/answers", CheckAnswer, auth)
+	e.POST("/submit", SubmitAnswers, auth)
+
+	adaToken := issueTestToken(t, tokens, "ada", time.Now())
+	bobToken := issueTestToken(t, tokens, "bob", time.Now())
+
+	req := httptest.NewRequest(http.MethodGet, "/questions?category=science", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+adaToken)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if !assert.Equal(t, http.StatusOK, rec.Code) {
+		return
+	}
+
+	var quiz struct {
+		Data models.Quiz `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &quiz))
+	sessionID := quiz.Data.SessionID
+
+	session, ok := globals.Sessions.Get(sessionID)
+	assert.True(t, ok)
+	assert.Equal(t, "ada", session.Username)
+
+	submission, _ := json.Marshal(models.QuizResponse{SessionID: sessionID, QuestionResponses: []models.QuestionResponse{{QuestionID: 1, Answer: 0}}})
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"failure_answer_by_another_user", http.MethodPost, "/sessions/" + sessionID + "/answers", `{"questionId": 1, "answer": 0}`, bobToken, http.StatusForbidden, `{"success":false,"code":"SESSION_FORBIDDEN","message":"Quiz session ` + sessionID + ` belongs to another user."}`},
+		{"failure_anonymous_submission", http.MethodPost, "/submit", string(submission), "", http.StatusForbidden, `{"success":false,"code":"SESSION_FORBIDDEN","message":"Quiz session ` + sessionID + ` belongs to another user."}`},
+		{"failure_submission_by_another_user", http.MethodPost, "/submit", string(submission), bobToken, http.StatusForbidden, `{"success":false,"code":"SESSION_FORBIDDEN","message":"Quiz session ` + sessionID + ` belongs to another user."}`},
+		{"success_submission_by_owner", http.MethodPost, "/submit", string(submission), adaToken, http.StatusOK, `"username":"ada"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"quizwizard/api/bank"
+	"quizwizard/api/globals"
+	"quizwizard/api/models"
+
+	"github.com/labstack/echo"
+)
+
+// AdminKeyHeader is the request header which must carry the admin API key
+const AdminKeyHeader = "X-Admin-Key"
+
+// AdminAuth returns middleware which rejects requests that do not carry the admin API key
+func AdminAuth(key string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			provided := c.Request().Header.Get(AdminKeyHeader)
+			if len(key) == 0 || subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+				msg := "A valid admin API key must be provided."
+				return prepareErrorResponse(c, CodeUnauthorized, msg, http.StatusUnauthorized)
+			}
+			return next(c)
+		}
+	}
+}
+
+// CreateQuestion validates a new question and adds it to the live question bank
+func CreateQuestion(c echo.Context) error {
+	var question models.Question
+	err := c.Bind(&question)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+	question.Category = normaliseCategory(question.Category)
+
+	created, err := globals.Bank.AddQuestion(question)
+	if err != nil {
+		return prepareBankErrorResponse(c, "Failed to create question: ", err, questionErrors(question)...)
+	}
+
+	msg := fmt.Sprintf("Question %d created successfully.", created.ID)
+	return prepareResponse(c, true, msg, http.StatusCreated, created)
+}
+
+// UpdateQuestion validates and replaces an existing question within the live question bank
+func UpdateQuestion(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		msg := c.Param("id") + " is not a valid question ID."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "id", Message: msg})
+	}
+
+	var question models.Question
+	err = c.Bind(&question)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+	question.ID = id
+	question.Category = normaliseCategory(question.Category)
+
+	updated, err := globals.Bank.UpdateQuestion(question)
+	if err != nil {
+		return prepareBankErrorResponse(c, "Failed to update question: ", err, questionErrors(question)...)
+	}
+
+	msg := fmt.Sprintf("Question %d updated successfully.", updated.ID)
+	return prepareResponse(c, true, msg, http.StatusOK, updated)
+}
+
+// DeleteQuestion removes a question from the live question bank
+func DeleteQuestion(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		msg := c.Param("id") + " is not a valid question ID."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "id", Message: msg})
+	}
+
+	err = globals.Bank.DeleteQuestion(id)
+	if err != nil {
+		return prepareBankErrorResponse(c, "Failed to delete question: ", err)
+	}
+
+	msg := fmt.Sprintf("Question %d deleted successfully.", id)
+	return prepareResponse(c, true, msg, http.StatusOK, nil)
+}
+
+// CreateCategory adds a new empty category to the live question bank
+func CreateCategory(c echo.Context) error {
+	var categoryRequest models.CategoryRequest
+	err := c.Bind(&categoryRequest)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+	name := normaliseCategory(categoryRequest.Name)
+
+	err = globals.Bank.AddCategory(name)
+	if err != nil {
+		return prepareBankErrorResponse(c, "Failed to create category: ", err)
+	}
+	for _, level := range models.CategoryLevels(name) {
+		globals.Scores.AddCategory(level)
+	}
+
+	msg := "Category " + name + " created successfully."
+	return prepareResponse(c, true, msg, http.StatusCreated, models.CategoryRequest{Name: name})
+}
+
+// RenameCategory renames a category and its subcategories within the live question bank
+func RenameCategory(c echo.Context) error {
+	name := normaliseCategory(unescapeParam(c.Param("name")))
+
+	var categoryRequest models.CategoryRequest
+	err := c.Bind(&categoryRequest)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+	newName := normaliseCategory(categoryRequest.Name)
+
+	// Scores and leaderboard entries are moved along with the questions, while the bank holds its write lock
+	err = globals.Bank.RenameCategory(name, newName, func() error {
+		if err := globals.Scores.RenameCategory(name, newName); err != nil {
+			return err
+		}
+		return globals.Leaderboard.RenameCategory(name, newName)
+	})
+	if err != nil {
+		return prepareBankErrorResponse(c, "Failed to rename category: ", err)
+	}
+	for category := range bank.Subtree(globals.Bank.Questions(), newName) {
+		for _, level := range models.CategoryLevels(category) {
+			globals.Scores.AddCategory(level)
+		}
+	}
+
+	msg := "Category " + name + " renamed to " + newName + " successfully."
+	return prepareResponse(c, true, msg, http.StatusOK, models.CategoryRequest{Name: newName})
+}
+
+// DeleteCategory removes a category and its subcategories from the live question bank, as long as they are all empty
+func DeleteCategory(c echo.Context) error {
+	name := normaliseCategory(unescapeParam(c.Param("name")))
+
+	err := globals.Bank.DeleteCategory(name)
+	if err != nil {
+		return prepareBankErrorResponse(c, "Failed to delete category: ", err)
+	}
+
+	msg := "Category " + name + " deleted successfully."
+	return prepareResponse(c, true, msg, http.StatusOK, nil)
+}
+
+// normaliseCategory trims and lower-cases a category name
+func normaliseCategory(category string) string {
+	category = strings.Trim(category, " ")
+	return strings.ToLower(category)
+}
+
+// prepareBankErrorResponse prepares the response payload for an error returned by the question bank, along with
+// any problems with the fields of the question which was rejected
+func prepareBankErrorResponse(c echo.Context, prefix string, err error, details ...FieldError) error {
+	var code string
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models.ErrInvalidQuestion), errors.Is(err, bank.ErrInvalidCategory):
+		code, statusCode = CodeValidationFailed, http.StatusBadRequest
+	case errors.Is(err, bank.ErrQuestionNotFound):
+		code, statusCode = CodeQuestionNotFound, http.StatusNotFound
+	case errors.Is(err, bank.ErrCategoryNotFound):
+		code, statusCode = CodeCategoryNotFound, http.StatusNotFound
+	case errors.Is(err, bank.ErrDuplicateQuestionID):
+		code, statusCode = CodeQuestionExists, http.StatusConflict
+	case errors.Is(err, bank.ErrCategoryExists):
+		code, statusCode = CodeCategoryExists, http.StatusConflict
+	case errors.Is(err, bank.ErrCategoryNotEmpty):
+		code, statusCode = CodeCategoryNotEmpty, http.StatusConflict
+	}
+
+	if statusCode == http.StatusInternalServerError {
+		return prepareInternalErrorResponse(c)
+	}
+
+	if statusCode != http.StatusBadRequest {
+		details = nil
+	}
+	return prepareErrorResponse(c, code, prefix+err.Error(), statusCode, details...)
+}
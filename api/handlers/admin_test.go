@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quizwizard/api/bank"
+	"quizwizard/api/globals"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAdminServer is a helper function which registers the admin endpoints behind the admin key middleware
+func newAdminServer() *echo.Echo {
+	e := echo.New()
+	admin := e.Group("/admin", AdminAuth("secret"))
+	admin.POST("/questions", CreateQuestion)
+	admin.PUT("/questions/:id", UpdateQuestion)
+	admin.DELETE("/questions/:id", DeleteQuestion)
+	admin.POST("/categories", CreateCategory)
+	admin.PUT("/categories/:name", RenameCategory)
+	admin.DELETE("/categories/:name", DeleteCategory)
+	return e
+}
+
+// TestAdminEndpoints tests the admin handler functions and the admin key middleware
+func TestAdminEndpoints(t *testing.T) {
+	e := newAdminServer()
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		adminKey           string
+		requestBody        string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "failure_due_to_missing_admin_key",
+			method:             http.MethodDelete,
+			path:               "/admin/questions/1",
+			adminKey:           "",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse: `{
+                "success": false,
+                "code": "UNAUTHORIZED",
+                "message": "A valid admin API key must be provided."
+            }`,
+		},
+		{
+			name:               "failure_due_to_incorrect_admin_key",
+			method:             http.MethodDelete,
+			path:               "/admin/questions/1",
+			adminKey:           "guess",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse: `{
+                "success": false,
+                "code": "UNAUTHORIZED",
+                "message": "A valid admin API key must be provided."
+            }`,
+		},
+		{
+			name:               "successfully_created_question",
+			method:             http.MethodPost,
+			path:               "/admin/questions",
+			adminKey:           "secret",
+			requestBody:        `{"category": " Science ", "question": "What is the chemical symbol for gold?", "answers": ["Au", "Ag"], "correctAnswerIndex": 0}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: `{
+                "success": true,
+                "message": "Question 2 created successfully.",
+                "data": {"id": 2, "category": "science", "question": "What is the chemical symbol for gold?", "answers": ["Au", "Ag"], "correctAnswerIndex": 0}
+            }`,
+		},
+		{
+			name:               "failure_due_to_invalid_question",
+			method:             http.MethodPost,
+			path:               "/admin/questions",
+			adminKey:           "secret",
+			requestBody:        `{"category": "science", "question": "What is the chemical symbol for gold?", "answers": ["Au", "Ag"], "correctAnswerIndex": 2}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "VALIDATION_FAILED",
+                "message": "Failed to create question: question is invalid: correctAnswerIndex 2 is out of range",
+                "details": [{"field": "correctAnswerIndex", "message": "correctAnswerIndex 2 is out of range"}]
+            }`,
+		},
+		{
+			name:               "failure_due_to_unknown_category",
+			method:             http.MethodPost,
+			path:               "/admin/questions",
+			adminKey:           "secret",
+			requestBody:        `{"category": "music", "question": "Who sang 'Thriller'?", "answers": ["Prince", "Michael Jackson"], "correctAnswerIndex": 1}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: `{
+                "success": false,
+                "code": "CATEGORY_NOT_FOUND",
+                "message": "Failed to create question: category does not exist"
+            }`,
+		},
+		{
+			name:               "successfully_updated_question",
+			method:             http.MethodPut,
+			path:               "/admin/questions/1",
+			adminKey:           "secret",
+			requestBody:        `{"category": "science", "question": "What is the chemical symbol for water?", "answers": ["HO", "H2O"], "correctAnswerIndex": 1}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Question 1 updated successfully.",
+                "data": {"id": 1, "category": "science", "question": "What is the chemical symbol for water?", "answers": ["HO", "H2O"], "correctAnswerIndex": 1}
+            }`,
+		},
+		{
+			name:               "failure_due_to_invalid_question_id",
+			method:             http.MethodPut,
+			path:               "/admin/questions/abc",
+			adminKey:           "secret",
+			requestBody:        `{}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "VALIDATION_FAILED",
+                "message": "abc is not a valid question ID.",
+                "details": [{"field": "id", "message": "abc is not a valid question ID."}]
+            }`,
+		},
+		{
+			name:               "failure_due_to_unknown_question",
+			method:             http.MethodDelete,
+			path:               "/admin/questions/42",
+			adminKey:           "secret",
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: `{
+                "success": false,
+                "code": "QUESTION_NOT_FOUND",
+                "message": "Failed to delete question: question does not exist"
+            }`,
+		},
+		{
+			name:               "successfully_created_category",
+			method:             http.MethodPost,
+			path:               "/admin/categories",
+			adminKey:           "secret",
+			requestBody:        `{"name": "Music"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: `{
+                "success": true,
+                "message": "Category music created successfully.",
+                "data": {"name": "music"}
+            }`,
+		},
+		{
+			name:               "failure_due_to_existing_category",
+			method:             http.MethodPost,
+			path:               "/admin/categories",
+			adminKey:           "secret",
+			requestBody:        `{"name": "science"}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: `{
+                "success": false,
+                "code": "CATEGORY_EXISTS",
+                "message": "Failed to create category: category already exists"
+            }`,
+		},
+		{
+			name:               "successfully_renamed_category",
+			method:             http.MethodPut,
+			path:               "/admin/categories/history",
+			adminKey:           "secret",
+			requestBody:        `{"name": "past"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Category history renamed to past successfully.",
+                "data": {"name": "past"}
+            }`,
+		},
+		{
+			name:               "failure_due_to_deleting_category_with_questions",
+			method:             http.MethodDelete,
+			path:               "/admin/categories/science",
+			adminKey:           "secret",
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: `{
+                "success": false,
+                "code": "CATEGORY_NOT_EMPTY",
+                "message": "Failed to delete category: category still contains questions"
+            }`,
+		},
+		{
+			name:               "successfully_deleted_category",
+			method:             http.MethodDelete,
+			path:               "/admin/categories/past",
+			adminKey:           "secret",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Category past deleted successfully."
+            }`,
+		},
+	}
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+		},
+		"history":        {},
+		"history/modern": {},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"science": {},
+		"history": {70},
+	})
+	globals.Leaderboard = scores.NewMemoryLeaderboard()
+	assert.NoError(t, globals.Leaderboard.Record(scores.Entry{Player: "ada", Category: "history/modern", Score: 70}))
+
+	// The cases run in order against the same bank
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.adminKey != "" {
+				req.Header.Set(AdminKeyHeader, tt.adminKey)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.JSONEq(t, tt.expectedResponse, rec.Body.String())
+		})
+	}
+
+	assert.True(t, globals.Scores.HasCategory("music"), "Expected a score bucket for the new category")
+	assert.True(t, globals.Scores.HasCategory("past/modern"), "Expected a score bucket for the renamed subcategory")
+	assert.False(t, globals.Scores.HasCategory("history"), "Expected the scores to move with the renamed category")
+	stats, err := globals.Scores.Stats("past")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Count)
+	rankings, err := globals.Leaderboard.Top("past", time.Time{}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, rankings, 1) {
+		assert.Equal(t, "past/modern", rankings[0].Category)
+	}
+	assert.ElementsMatch(t, []string{"science", "music"}, keys(globals.Bank.Questions()))
+}
+
+// keys is a helper function which returns the categories of a question bank
+func keys(questions map[string]models.Questions) []string {
+	categories := make([]string, 0, len(questions))
+	for category := range questions {
+		categories = append(categories, category)
+	}
+	return categories
+}
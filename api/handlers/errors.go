@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"quizwizard/api/models"
+
+	"github.com/labstack/echo"
+)
+
+// Error codes identify why a request failed. Messages may be reworded, but codes are stable, so clients should
+// branch on the code rather than the message.
+const (
+	// CodeInvalidRequest is returned when the request body cannot be read
+	CodeInvalidRequest = "INVALID_REQUEST"
+	// CodeValidationFailed is returned when a parameter or field has an invalid value, which is named in the details
+	CodeValidationFailed = "VALIDATION_FAILED"
+	// CodeCategoryNotFound is returned when a category does not exist
+	CodeCategoryNotFound = "CATEGORY_NOT_FOUND"
+	// CodeInvalidCombination is returned when random is combined with other categories
+	CodeInvalidCombination = "INVALID_COMBINATION"
+	// CodeCategoryExists is returned when a category is created or renamed with the name of an existing category
+	CodeCategoryExists = "CATEGORY_EXISTS"
+	// CodeCategoryNotEmpty is returned when a category which still contains questions is deleted
+	CodeCategoryNotEmpty = "CATEGORY_NOT_EMPTY"
+	// CodeNoQuestions is returned when no questions match the category and difficulty of a quiz
+	CodeNoQuestions = "NO_QUESTIONS"
+	// CodeQuestionNotFound is returned when a question does not exist
+	CodeQuestionNotFound = "QUESTION_NOT_FOUND"
+	// CodeQuestionExists is returned when a question is created with the ID of an existing question
+	CodeQuestionExists = "QUESTION_EXISTS"
+	// CodeSessionNotFound is returned when a quiz session does not exist, has expired or was already submitted
+	CodeSessionNotFound = "SESSION_NOT_FOUND"
+	// CodeSessionForbidden is returned when a quiz session belongs to another user
+	CodeSessionForbidden = "SESSION_FORBIDDEN"
+	// CodeQuestionNotIssued is returned when an answer is checked for a question which is not part of the quiz session
+	CodeQuestionNotIssued = "QUESTION_NOT_ISSUED"
+	// CodeAlreadyAnswered is returned when an answer is checked for a question which was already answered
+	CodeAlreadyAnswered = "ALREADY_ANSWERED"
+	// CodeInvalidSubmission is returned when a quiz submission cannot be scored
+	CodeInvalidSubmission = "INVALID_SUBMISSION"
+	// CodeUnauthorized is returned when a valid access token or admin API key is required but was not sent
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeInvalidCredentials is returned when logging in with an incorrect username or password
+	CodeInvalidCredentials = "INVALID_CREDENTIALS"
+	// CodeUsernameTaken is returned when registering a username which already belongs to another user
+	CodeUsernameTaken = "USERNAME_TAKEN"
+	// CodeNotFound is returned when no endpoint matches the request path
+	CodeNotFound = "NOT_FOUND"
+	// CodeMethodNotAllowed is returned when the endpoint does not accept the request method
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	// CodeInternalError is returned when the request failed because of a problem with the API
+	CodeInternalError = "INTERNAL_ERROR"
+)
+
+// FieldError describes a problem with a single parameter or field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// prepareErrorResponse prepares the response payload for a failed request, along with any problems with its fields
+func prepareErrorResponse(c echo.Context, code string, msg string, statusCode int, details ...FieldError) error {
+	err := &response{
+		Success: false,
+		Code:    code,
+		Message: msg,
+		Details: details,
+	}
+
+	return c.JSON(statusCode, err)
+}
+
+// prepareInternalErrorResponse prepares the response payload for a request which failed because of a problem with the API
+func prepareInternalErrorResponse(c echo.Context) error {
+	msg := "An unexpected error occurred. Please try again later."
+	return prepareErrorResponse(c, CodeInternalError, msg, http.StatusInternalServerError)
+}
+
+// prepareBindErrorResponse prepares the response payload for a request body which could not be read, naming the
+// field when a value has the wrong type
+func prepareBindErrorResponse(c echo.Context, err error) error {
+	msg := "Invalid request format."
+
+	// Echo wraps decoding errors without supporting errors.Unwrap
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Internal != nil {
+		err = httpErr.Internal
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		detail := FieldError{Field: typeErr.Field, Message: "Expected " + typeErr.Type.String() + " but received " + typeErr.Value + "."}
+		return prepareErrorResponse(c, CodeInvalidRequest, msg, http.StatusBadRequest, detail)
+	}
+	return prepareErrorResponse(c, CodeInvalidRequest, msg, http.StatusBadRequest)
+}
+
+// questionErrors converts the problems with a submitted question into field errors. A missing ID is not a problem,
+// as the question bank assigns one.
+func questionErrors(question models.Question) []FieldError {
+	details := []FieldError{}
+	for _, problem := range question.Problems() {
+		if problem.Field == "id" && question.ID == 0 {
+			continue
+		}
+		details = append(details, FieldError{Field: problem.Field, Message: problem.Message})
+	}
+	return details
+}
+
+// HTTPErrorHandler replaces Echo's default error handler so that errors which are not handled by an endpoint, such as
+// unknown paths, unsupported methods and panics, are returned within the usual response payload
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		statusCode = httpErr.Code
+	}
+
+	var respErr error
+	switch {
+	case c.Request().Method == http.MethodHead:
+		respErr = c.NoContent(statusCode)
+	case statusCode == http.StatusNotFound:
+		respErr = prepareErrorResponse(c, CodeNotFound, "The requested endpoint does not exist.", statusCode)
+	case statusCode == http.StatusMethodNotAllowed:
+		msg := "The " + c.Request().Method + " method is not allowed for this endpoint."
+		respErr = prepareErrorResponse(c, CodeMethodNotAllowed, msg, statusCode)
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnsupportedMediaType:
+		respErr = prepareBindErrorResponse(c, err)
+	case statusCode == http.StatusUnauthorized:
+		respErr = prepareErrorResponse(c, CodeUnauthorized, "A valid access token must be provided. Please log in again.", statusCode)
+	case statusCode < http.StatusInternalServerError:
+		respErr = prepareErrorResponse(c, CodeInvalidRequest, http.StatusText(statusCode)+".", statusCode)
+	default:
+		c.Logger().Error(err)
+		respErr = prepareInternalErrorResponse(c)
+	}
+
+	if respErr != nil {
+		c.Logger().Error(respErr)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHTTPErrorHandler tests that errors which are not handled by an endpoint are returned within the response payload
+func TestHTTPErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.Use(middleware.Recover())
+
+	e.GET("/categories", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/panic", func(c echo.Context) error {
+		panic("something went wrong")
+	})
+	e.GET("/failure", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTooManyRequests)
+	})
+	e.POST("/bind", func(c echo.Context) error {
+		var body struct {
+			Count int `json:"count"`
+		}
+		return c.Bind(&body)
+	})
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "failure_due_to_unknown_path",
+			method:             http.MethodGet,
+			path:               "/unknown",
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"success": false, "code": "NOT_FOUND", "message": "The requested endpoint does not exist."}`,
+		},
+		{
+			name:               "failure_due_to_method_not_allowed",
+			method:             http.MethodDelete,
+			path:               "/categories",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedResponse:   `{"success": false, "code": "METHOD_NOT_ALLOWED", "message": "The DELETE method is not allowed for this endpoint."}`,
+		},
+		{
+			name:               "failure_due_to_panic",
+			method:             http.MethodGet,
+			path:               "/panic",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"success": false, "code": "INTERNAL_ERROR", "message": "An unexpected error occurred. Please try again later."}`,
+		},
+		{
+			name:               "failure_due_to_other_client_error",
+			method:             http.MethodGet,
+			path:               "/failure",
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedResponse:   `{"success": false, "code": "INVALID_REQUEST", "message": "Too Many Requests."}`,
+		},
+		{
+			name:               "failure_due_to_malformed_body",
+			method:             http.MethodPost,
+			path:               "/bind",
+			body:               `{"count":`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"success": false, "code": "INVALID_REQUEST", "message": "Invalid request format."}`,
+		},
+		{
+			name:               "failure_due_to_mistyped_field",
+			method:             http.MethodPost,
+			path:               "/bind",
+			body:               `{"count": "ten"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{"success": false, "code": "INVALID_REQUEST", "message": "Invalid request format.",
+				"details": [{"field": "count", "message": "Expected int but received string."}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.JSONEq(t, tt.expectedResponse, rec.Body.String())
+		})
+	}
+
+	t.Run("failure_due_to_unknown_path_without_body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodHead, "/unknown", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+}
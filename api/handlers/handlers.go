@@ -1,154 +1,499 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
-	"unicode"
+	"time"
 
+	"quizwizard/api/accounts"
+	"quizwizard/api/bank"
 	"quizwizard/api/globals"
 	"quizwizard/api/models"
+	"quizwizard/api/scores"
+	"quizwizard/api/sessions"
 	"quizwizard/api/utils"
 
 	"github.com/labstack/echo"
 )
 
-// response represents the payload which is returned by each API endpoint
+// response represents the payload which is returned by each API endpoint. Failed requests carry an error code and,
+// when particular fields were invalid, details of each problem.
 type response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Success bool         `json:"success"`
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	Data    interface{}  `json:"data,omitempty"`
 }
 
-// GetCategories retrieves and returns a list of the latest quiz categories
+// GetCategories retrieves and returns the latest quiz categories with their details, followed by the random category
 func GetCategories(c echo.Context) error {
-	questions := globals.Questions
+	categories := globals.Bank.Categories()
 
-	if len(questions) == 0 {
-		msg := "An unexpected error occurred. Please try again later."
-		return prepareResponse(c, false, msg, http.StatusInternalServerError, nil)
+	if len(categories) == 0 {
+		return prepareInternalErrorResponse(c)
 	}
 
-	categories := make([]string, len(questions))
-	i := 0
-	for key := range questions {
-		runes := []rune(key)
-		runes[0] = unicode.ToUpper(runes[0])
-		categories[i] = string(runes)
-		i++
+	all := models.Questions{}
+	for i := range categories {
+		categories[i].DefaultQuestions = globals.QuizLength.DefaultFor(categories[i].Slug)
+		all = append(all, globals.Bank.Questions()[categories[i].Slug]...)
 	}
-	sort.Strings(categories)
-	categories = append(categories, "Random")
+	categories = append(categories, models.Category{
+		Slug:             "random",
+		Name:             "Random",
+		Description:      "Questions drawn from every category.",
+		QuestionCount:    len(all),
+		Difficulties:     models.DifficultiesOf(all),
+		DefaultQuestions: globals.QuizLength.DefaultFor("random"),
+	})
 
 	return prepareResponse(c, true, "Categories retrieved successfully.", http.StatusOK, categories)
 }
 
-// GetQuestions retrieves and returns a list of questions for a specified category
+// GetCategoryStats returns summary statistics for the submitted scores of a category, or a combination of categories
+// joined by "+". Scores for a difficulty are only included when that difficulty is specified, and the scores of a
+// category include those of its subcategories.
+func GetCategoryStats(c echo.Context) error {
+	categories := parseCategories([]string{strings.ReplaceAll(unescapeParam(c.Param("name")), "+", ",")})
+	questions := globals.Bank.Questions()
+	for _, name := range categories {
+		if name == "random" && len(categories) > 1 {
+			msg := "random cannot be combined with other categories."
+			return prepareErrorResponse(c, CodeInvalidCombination, msg, http.StatusBadRequest)
+		}
+
+		if len(bank.Subtree(questions, name)) == 0 && name != "random" {
+			msg := name + " is not a valid category."
+			return prepareErrorResponse(c, CodeCategoryNotFound, msg, http.StatusNotFound)
+		}
+	}
+	category := scores.Combination(categories)
+
+	difficulty := c.QueryParam("difficulty")
+	difficulty = strings.Trim(difficulty, " ")
+	difficulty = strings.ToLower(difficulty)
+	if len(difficulty) > 0 && !models.IsDifficulty(difficulty) {
+		msg := difficulty + " is not a valid difficulty. Please choose " + strings.Join(models.Difficulties, ", ") + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "difficulty", Message: msg})
+	}
+
+	// Combinations and difficulties which have never been submitted have no bucket yet
+	bucket := scores.Bucket(category, difficulty)
+	stats := scores.NewHistogram().Stats()
+	if globals.Scores.HasCategory(bucket) {
+		var err error
+		stats, err = globals.Scores.Stats(bucket)
+		if err != nil {
+			return prepareInternalErrorResponse(c)
+		}
+	}
+
+	res := map[string]interface{}{
+		"category":   category,
+		"difficulty": difficulty,
+		"passMark":   scores.PassMark,
+		"stats":      stats,
+	}
+	return prepareResponse(c, true, "Category statistics retrieved successfully.", http.StatusOK, res)
+}
+
+// GetQuestions retrieves and returns a list of questions for a specified category, or combination of categories.
+// The difficulty, number of questions and, for random and combined quizzes, how questions are selected may also be specified.
+// Quizzes started by a logged in user belong to them.
 func GetQuestions(c echo.Context) error {
-	category := c.QueryParam("category")
-	category = strings.Trim(category, " ")
-	category = strings.ToLower(category)
+	// Categories may be combined as a comma separated list or by repeating the parameter
+	categories := parseCategories(c.QueryParams()["category"])
+
+	difficulty := c.QueryParam("difficulty")
+	difficulty = strings.Trim(difficulty, " ")
+	difficulty = strings.ToLower(difficulty)
+
+	questions := globals.Bank.Questions()
+	if len(questions) == 0 {
+		return prepareInternalErrorResponse(c)
+	}
+
+	if len(categories) == 0 {
+		categories = []string{"random"} // Select 'random' as the default category
+	}
+
+	for _, name := range categories {
+		if name == "random" && len(categories) > 1 {
+			msg := "random cannot be combined with other categories."
+			return prepareErrorResponse(c, CodeInvalidCombination, msg, http.StatusBadRequest)
+		}
+
+		if len(bank.Subtree(questions, name)) == 0 && name != "random" {
+			msg := name + " is not a valid category."
+			return prepareErrorResponse(c, CodeCategoryNotFound, msg, http.StatusNotFound)
+		}
+	}
+	category := scores.Combination(categories)
+
+	if len(difficulty) > 0 && !models.IsDifficulty(difficulty) {
+		msg := difficulty + " is not a valid difficulty. Please choose " + strings.Join(models.Difficulties, ", ") + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "difficulty", Message: msg})
+	}
 
-	if len(globals.Questions) == 0 {
-		msg := "An unexpected error occurred. Please try again later."
-		return prepareResponse(c, false, msg, http.StatusInternalServerError, nil)
+	count := globals.QuizLength.DefaultFor(category)
+	countParam := strings.Trim(c.QueryParam("count"), " ")
+	if len(countParam) > 0 {
+		requested, err := strconv.Atoi(countParam)
+		if err != nil || !globals.QuizLength.Allows(requested) {
+			msg := fmt.Sprintf("%s is not a valid question count. Please choose a number between %d and %d.", countParam, globals.QuizLength.Min, globals.QuizLength.Max)
+			return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "count", Message: msg})
+		}
+		count = requested
 	}
 
-	if len(category) == 0 {
-		category = "random" // Select 'random' as the default category
+	selection := c.QueryParam("selection")
+	selection = strings.Trim(selection, " ")
+	selection = strings.ToLower(selection)
+	if len(selection) == 0 {
+		selection = utils.SelectionStratified
 	}
 
-	if _, ok := globals.Questions[category]; !ok && category != "random" {
-		msg := category + " is not a valid category."
-		return prepareResponse(c, false, msg, http.StatusNotFound, nil)
+	if selection != utils.SelectionStratified && selection != utils.SelectionUniform {
+		msg := selection + " is not a valid selection. Please choose " + utils.SelectionStratified + " or " + utils.SelectionUniform + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "selection", Message: msg})
 	}
 
+	// Categories draw from their subcategories and combined quizzes draw from the chosen categories, while random quizzes draw from every category
+	pool := questions
+	if category != "random" {
+		pool = make(map[string]models.Questions)
+		for _, name := range categories {
+			for subcategory, subcategoryQuestions := range bank.Subtree(questions, name) {
+				pool[subcategory] = subcategoryQuestions
+			}
+		}
+	}
+	mixed := len(pool) > 1 || category == "random"
+
 	var responseQuestions models.Questions
-	if category == "random" {
-		// Select random questions from all categories
-		responseQuestions = utils.RandomiseQuestions(globals.Questions)
+	if mixed && selection == utils.SelectionStratified {
+		// Select random questions spread evenly across the categories
+		responseQuestions = utils.StratifyQuestions(pool, difficulty, count)
+	} else if mixed {
+		// Select random questions from the categories
+		responseQuestions = utils.RandomiseQuestions(pool, difficulty, count)
 	} else {
-		// Shuffle the questions from the selected category
-		responseQuestions = globals.Questions[category].ShuffledCopy()
+		// Shuffle the questions from the only category in the pool
+		for _, categoryQuestions := range pool {
+			responseQuestions = categoryQuestions.WithDifficulty(difficulty).ShuffledCopy()
+		}
+		if len(responseQuestions) > count {
+			responseQuestions = responseQuestions[:count]
+		}
 	}
 
 	if len(responseQuestions) == 0 {
-		msg := "Currently there are no questions available for the " + category + " category. Please choose a different category or try again later."
-		return prepareResponse(c, false, msg, http.StatusNotFound, nil)
+		msg := "Currently there are no questions available for the " + quizName(category, difficulty) + " category. Please choose a different category or try again later."
+		return prepareErrorResponse(c, CodeNoQuestions, msg, http.StatusNotFound)
+	}
+
+	// Record the issued questions, the order of their answers and their time limits so that the submission can be scored against them
+	answerOrders := utils.ShuffleAnswers(responseQuestions)
+	timeLimits := utils.QuestionTimeLimits(responseQuestions)
+	session, err := globals.Sessions.Create(category, difficulty, responseQuestions, answerOrders, timeLimits, currentUser(c))
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	shuffledQuestions := make(models.Questions, len(responseQuestions))
+	for i, question := range responseQuestions {
+		shuffledQuestions[i] = question.Reordered(answerOrders[question.ID])
+	}
+
+	publicQuestions := shuffledQuestions.Public()
+	for i := range publicQuestions {
+		publicQuestions[i].TimeLimit = timeLimits[publicQuestions[i].ID]
 	}
 
-	msg := "Questions successfully retrieved from the " + category + " category."
-	return prepareResponse(c, true, msg, http.StatusOK, responseQuestions)
+	quiz := models.Quiz{
+		SessionID:  session.ID,
+		Category:   category,
+		Categories: categories,
+		Difficulty: difficulty,
+		Questions:  publicQuestions,
+	}
+
+	msg := "Questions successfully retrieved from the " + quizName(category, difficulty) + " category."
+	return prepareResponse(c, true, msg, http.StatusOK, quiz)
 }
 
-// SubmitAnswers stores a score for a quiz submission and returns the results
+// CheckAnswer checks a single answer within a quiz session and locks it in for the final submission
+func CheckAnswer(c echo.Context) error {
+	sessionID := strings.Trim(c.Param("id"), " ")
+
+	var questionResponse models.QuestionResponse
+	err := c.Bind(&questionResponse)
+	if err != nil {
+		return prepareBindErrorResponse(c, err)
+	}
+
+	session, ok := globals.Sessions.Get(sessionID)
+	if !ok {
+		msg := "Quiz session " + sessionID + " was not found or has expired."
+		return prepareErrorResponse(c, CodeSessionNotFound, msg, http.StatusNotFound)
+	}
+
+	if !ownsSession(c, session) {
+		msg := "Quiz session " + sessionID + " belongs to another user."
+		return prepareErrorResponse(c, CodeSessionForbidden, msg, http.StatusForbidden)
+	}
+
+	questionID := questionResponse.QuestionID
+	if !session.HasQuestion(questionID) {
+		msg := fmt.Sprintf("Question %d was not issued for this quiz session.", questionID)
+		return prepareErrorResponse(c, CodeQuestionNotIssued, msg, http.StatusBadRequest)
+	}
+
+	question, ok := utils.FindQuestion(globals.Bank.Questions(), questionID)
+	if !ok {
+		return prepareInternalErrorResponse(c)
+	}
+
+	// The answer and the correct answer index refer to the options in the order they were shown
+	question = question.Reordered(session.AnswerOrders[questionID])
+
+	msg := "Answer checked successfully."
+	timedOut := false
+	err = globals.Sessions.RecordAnswer(sessionID, questionResponse)
+	if errors.Is(err, sessions.ErrTimeExpired) {
+		// The answer arrived too late, so a blank answer was locked in instead
+		msg = fmt.Sprintf("The time limit for question %d has passed.", questionID)
+		timedOut = true
+		questionResponse = models.BlankResponse(questionID)
+	} else if errors.Is(err, sessions.ErrAlreadyAnswered) {
+		msg := fmt.Sprintf("Question %d has already been answered.", questionID)
+		return prepareErrorResponse(c, CodeAlreadyAnswered, msg, http.StatusConflict)
+	} else if err != nil {
+		msg := "Quiz session " + sessionID + " was not found or has expired."
+		return prepareErrorResponse(c, CodeSessionNotFound, msg, http.StatusNotFound)
+	}
+
+	credit := question.Credit(questionResponse)
+	res := models.AnswerCheck{
+		QuestionID:           questionID,
+		Correct:              credit == 1,
+		TimedOut:             timedOut,
+		Credit:               credit,
+		CorrectAnswerIndex:   question.CorrectAnswerIndex,
+		CorrectAnswerIndexes: question.CorrectAnswerIndexes,
+		CorrectAnswer:        question.CorrectAnswer(),
+	}
+	return prepareResponse(c, true, msg, http.StatusOK, res)
+}
+
+// SubmitAnswers stores a score for a quiz submission and returns the results.
+// Submissions by logged in users are added to their results.
 func SubmitAnswers(c echo.Context) error {
-	if len(globals.CategoryScores) == 0 {
-		msg := "An unexpected error occurred. Please try again later."
-		return prepareResponse(c, false, msg, http.StatusInternalServerError, nil)
+	if len(globals.Scores.Categories()) == 0 {
+		return prepareInternalErrorResponse(c)
 	}
 
 	var quizSubmission models.QuizResponse
 	err := c.Bind(&quizSubmission)
 	if err != nil {
-		msg := "Invalid request format."
-		return prepareResponse(c, false, msg, http.StatusBadRequest, nil)
+		return prepareBindErrorResponse(c, err)
+	}
+
+	sessionID := strings.Trim(quizSubmission.SessionID, " ")
+	if len(sessionID) == 0 {
+		msg := "A session ID must be provided."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "sessionId", Message: msg})
+	}
+
+	player, err := models.NormalisePlayerName(quizSubmission.PlayerName)
+	if err != nil {
+		msg := "Invalid player name: " + err.Error() + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "playerName", Message: msg})
 	}
 
 	if len(quizSubmission.QuestionResponses) == 0 {
-		return prepareResponse(c, false, "No answers were submitted.", http.StatusBadRequest, nil)
+		return prepareErrorResponse(c, CodeInvalidSubmission, "No answers were submitted.", http.StatusBadRequest)
 	}
 
-	category := quizSubmission.Category
-	category = strings.Trim(category, " ")
-	category = strings.ToLower(category)
+	session, ok := globals.Sessions.Get(sessionID)
+	if !ok {
+		msg := "Quiz session " + sessionID + " was not found or has expired."
+		return prepareErrorResponse(c, CodeSessionNotFound, msg, http.StatusNotFound)
+	}
 
-	if len(category) == 0 {
-		return prepareResponse(c, false, "A category must be provided.", http.StatusBadRequest, nil)
+	if !ownsSession(c, session) {
+		msg := "Quiz session " + sessionID + " belongs to another user."
+		return prepareErrorResponse(c, CodeSessionForbidden, msg, http.StatusForbidden)
 	}
+	username := currentUser(c)
 
-	if _, ok := globals.CategoryScores[category]; !ok {
-		msg := category + " is not a valid category."
-		return prepareResponse(c, false, msg, http.StatusNotFound, nil)
+	category := session.Category
+	for _, name := range scores.SplitCombination(category) {
+		if !globals.Scores.HasCategory(name) {
+			msg := name + " is not a valid category."
+			return prepareErrorResponse(c, CodeCategoryNotFound, msg, http.StatusNotFound)
+		}
 	}
 
-	// Calculate the score
-	scoreString, scorePercentage, err := utils.CalculateScore(quizSubmission.QuestionResponses)
+	// Answers to timed questions which were not checked during the quiz earn nothing if they arrive too late
+	responses := utils.ApplyDeadlines(session, quizSubmission.QuestionResponses, time.Now())
+
+	// Mark and score the answers once, against a single snapshot of the question bank
+	questions := globals.Bank.Questions()
+	answers, err := utils.MarkAnswers(session, responses, questions)
 	if err != nil {
 		msg := "Failed to process submission: " + err.Error()
-		return prepareResponse(c, false, msg, http.StatusBadRequest, nil)
+		return prepareErrorResponse(c, CodeInvalidSubmission, msg, http.StatusBadRequest)
 	}
 
-	// Calculate the comparison percentage
-	comparisonScore, err := utils.CalculateComparison(category, scorePercentage)
+	points, err := utils.CalculatePoints(answers)
 	if err != nil {
 		msg := "Failed to process submission: " + err.Error()
-		return prepareResponse(c, false, msg, http.StatusBadRequest, nil)
+		return prepareErrorResponse(c, CodeInvalidSubmission, msg, http.StatusBadRequest)
+	}
+
+	scoreString, scorePercentage := utils.CalculateScore(points)
+	categoryScores := utils.CalculateCategoryScores(points)
+
+	// Each session can only be submitted once. It is claimed while the submission is saved and only deleted once
+	// everything has been saved, so that a submission which fails to save can be retried. Each part of the submission
+	// is recorded once saved, so that a retry does not save it again.
+	claimed, ok := globals.Sessions.Claim(sessionID)
+	if !ok {
+		msg := "Quiz session " + sessionID + " was not found or has expired."
+		return prepareErrorResponse(c, CodeSessionNotFound, msg, http.StatusNotFound)
 	}
+	defer globals.Sessions.Release(sessionID)
 
-	// Update the global scores map
-	err = utils.AppendCategoryScore(category, scorePercentage)
+	// Calculate the comparison percentage
+	comparisonScore, err := utils.CalculateComparison(category, session.Difficulty, scorePercentage)
 	if err != nil {
-		msg := "Failed to process submission: " + err.Error()
-		return prepareResponse(c, false, msg, http.StatusBadRequest, nil)
+		return prepareInternalErrorResponse(c)
+	}
+
+	// Subcategory scores are also compared at each level above them
+	levelComparisons, err := utils.CalculateLevelComparisons(category, session.Difficulty, scorePercentage)
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	// Update the score store
+	if !claimed.Saved[sessions.PartScore] {
+		err = utils.AppendCategoryScore(category, session.Difficulty, scorePercentage)
+		if err != nil {
+			return prepareInternalErrorResponse(c)
+		}
+		globals.Sessions.MarkSaved(sessionID, sessions.PartScore)
+	}
+
+	// Named players are ranked on the leaderboard
+	if player != "" && !claimed.Saved[sessions.PartLeaderboard] {
+		err = globals.Leaderboard.Record(scores.Entry{
+			Player:      player,
+			Category:    category,
+			Difficulty:  session.Difficulty,
+			Score:       scorePercentage,
+			ScoreString: scoreString,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return prepareInternalErrorResponse(c)
+		}
+		globals.Sessions.MarkSaved(sessionID, sessions.PartLeaderboard)
+	}
+
+	// Logged in users keep a record of their results
+	if username != "" && !claimed.Saved[sessions.PartResult] {
+		err = globals.Accounts.RecordResult(accounts.Result{
+			Username:    username,
+			Category:    category,
+			Difficulty:  session.Difficulty,
+			Score:       scorePercentage,
+			ScoreString: scoreString,
+			Outcomes:    utils.CalculateOutcomes(answers, points, questions),
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return prepareInternalErrorResponse(c)
+		}
+		globals.Sessions.MarkSaved(sessionID, sessions.PartResult)
+	}
+
+	globals.Sessions.Delete(sessionID)
+
+	stats, err := globals.Scores.Stats(scores.Bucket(category, session.Difficulty))
+	if err != nil {
+		return prepareInternalErrorResponse(c)
 	}
 
+	name := quizName(category, session.Difficulty)
 	comparisonString := ""
-	if len(globals.CategoryScores[category]) <= 1 {
-		comparisonString = fmt.Sprintf("You are the first quizzer for the %s category.", category)
+	if stats.Count <= 1 {
+		comparisonString = fmt.Sprintf("You are the first quizzer for the %s category.", name)
 	} else {
-		comparisonString = fmt.Sprintf("Your score for the %s category was better than %.0f%% of all quizzers.", category, comparisonScore)
+		comparisonString = fmt.Sprintf("Your score for the %s category was better than %.0f%% of all quizzers.", name, comparisonScore)
 	}
 
 	res := map[string]interface{}{
 		"scoreString":     scoreString,
 		"scorePercentage": scorePercentage,
 		"comparison":      comparisonString,
+		"breakdown":       categoryScores,
+		"points":          points,
+	}
+	if len(levelComparisons) > 1 {
+		res["levels"] = levelComparisons
+	}
+	if player != "" {
+		res["player"] = player
+	}
+	if username != "" {
+		res["username"] = username
 	}
 	return prepareResponse(c, true, "Submission processed successfully.", http.StatusOK, res)
 }
 
+// parseCategories splits comma separated category parameters into a sorted list of unique category names
+func parseCategories(values []string) []string {
+	seen := make(map[string]bool)
+	categories := []string{}
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.Trim(name, " ")
+			name = strings.ToLower(name)
+			if len(name) > 0 && !seen[name] {
+				seen[name] = true
+				categories = append(categories, name)
+			}
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// unescapeParam decodes a path parameter, as subcategories such as "science/physics" are sent with their "/" escaped
+func unescapeParam(param string) string {
+	unescaped, err := url.PathUnescape(param)
+	if err != nil {
+		return param
+	}
+	return unescaped
+}
+
+// quizName describes the category of a quiz along with its difficulty, such as "science (hard)"
+func quizName(category string, difficulty string) string {
+	if len(difficulty) == 0 {
+		return category
+	}
+	return category + " (" + difficulty + ")"
+}
+
 // prepareResponse prepares the response payload which is returned from each API endpoint
 func prepareResponse(c echo.Context, success bool, msg string, statusCode int, data interface{}) error {
 	err := &response{
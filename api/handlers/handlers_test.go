@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"quizwizard/api/bank"
 	"quizwizard/api/globals"
 	"quizwizard/api/models"
+	"quizwizard/api/scores"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/labstack/echo"
@@ -15,6 +20,9 @@ import (
 // TestGetCategories tests the GetCategories handler function
 func TestGetCategories(t *testing.T) {
 	e := echo.New()
+	originalBank, originalQuizLength := globals.Bank, globals.QuizLength
+	defer func() { globals.Bank, globals.QuizLength = originalBank, originalQuizLength }()
+	globals.QuizLength = models.QuizLength{Min: 1, Max: 20, Default: 5, CategoryDefaults: map[string]int{"computing": 10}}
 
 	tests := []struct {
 		name               string
@@ -25,28 +33,41 @@ func TestGetCategories(t *testing.T) {
 		{
 			name: "successfully_retrieve_categories",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
-					"science":   {},
-					"math":      {},
-					"history":   {},
-					"computing": {},
-				}
+				globals.Bank = bank.New(map[string]models.Questions{
+					"science/physics": {{ID: 1, Category: "science/physics", Difficulty: "easy"}},
+					"science/biology": {{ID: 2, Category: "science/biology"}},
+					"math":            {{ID: 3, Category: "math", Difficulty: "hard"}},
+					"history":         {},
+					"computing":       {{ID: 4, Category: "computing"}},
+				}, nil)
+				globals.Bank.SetDetails(map[string]models.CategoryDetails{
+					"computing": {Name: "Computer Science", Description: "Programs and machines."},
+				})
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: `{
                 "success": true,
                 "message": "Categories retrieved successfully.",
-                "data": ["Computing", "History", "Math", "Science", "Random"]
+                "data": [
+                    {"slug": "computing", "name": "Computer Science", "description": "Programs and machines.", "parent": "", "questionCount": 1, "difficulties": ["medium"], "defaultQuestions": 10},
+                    {"slug": "history", "name": "History", "description": "", "parent": "", "questionCount": 0, "difficulties": [], "defaultQuestions": 5},
+                    {"slug": "math", "name": "Math", "description": "", "parent": "", "questionCount": 1, "difficulties": ["hard"], "defaultQuestions": 5},
+                    {"slug": "science", "name": "Science", "description": "", "parent": "", "questionCount": 2, "difficulties": ["easy", "medium"], "defaultQuestions": 5},
+                    {"slug": "science/biology", "name": "Biology", "description": "", "parent": "science", "questionCount": 1, "difficulties": ["medium"], "defaultQuestions": 5},
+                    {"slug": "science/physics", "name": "Physics", "description": "", "parent": "science", "questionCount": 1, "difficulties": ["easy"], "defaultQuestions": 5},
+                    {"slug": "random", "name": "Random", "description": "Questions drawn from every category.", "parent": "", "questionCount": 4, "difficulties": ["easy", "medium", "hard"], "defaultQuestions": 5}
+                ]
             }`,
 		},
 		{
 			name: "failure_due_to_empty_questions_map",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{}
+				globals.Bank = bank.New(map[string]models.Questions{}, nil)
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse: `{
                 "success": false,
+                "code": "INTERNAL_ERROR",
                 "message": "An unexpected error occurred. Please try again later."
             }`,
 		},
@@ -68,6 +89,95 @@ func TestGetCategories(t *testing.T) {
 	}
 }
 
+// TestGetCategoryStats tests the GetCategoryStats handler function
+func TestGetCategoryStats(t *testing.T) {
+	originalBank, originalScores := globals.Bank, globals.Scores
+	defer func() { globals.Bank, globals.Scores = originalBank, originalScores }()
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {},
+		"music":   {},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"science":      {20.0, 40.0, 60.0, 100.0},
+		"science:hard": {45.0},
+		"music":        {},
+		"random":       {},
+	})
+
+	e := echo.New()
+	e.GET("/categories/:name/stats", GetCategoryStats)
+
+	empty := `"stats": {"count": 0, "mean": 0, "median": 0, "stdDev": 0, "passRate": 0, "lowest": 0, "highest": 0, "histogram": [0, 0, 0, 0, 0, 0, 0, 0, 0, 0]}`
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "success_category",
+			path:               "/categories/Science/stats",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"success": true, "message": "Category statistics retrieved successfully.", "data": {
+				"category": "science", "difficulty": "", "passMark": 50,
+				"stats": {"count": 4, "mean": 55, "median": 50, "stdDev": 29.58039891549808, "passRate": 50, "lowest": 20, "highest": 100, "histogram": [0, 0, 1, 0, 1, 0, 1, 0, 0, 1]}
+			}}`,
+		},
+		{
+			name:               "success_difficulty",
+			path:               "/categories/science/stats?difficulty=hard",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"success": true, "message": "Category statistics retrieved successfully.", "data": {
+				"category": "science", "difficulty": "hard", "passMark": 50,
+				"stats": {"count": 1, "mean": 45, "median": 45, "stdDev": 0, "passRate": 0, "lowest": 45, "highest": 45, "histogram": [0, 0, 0, 0, 1, 0, 0, 0, 0, 0]}
+			}}`,
+		},
+		{
+			name:               "success_empty_category",
+			path:               "/categories/music/stats",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"success": true, "message": "Category statistics retrieved successfully.", "data": {"category": "music", "difficulty": "", "passMark": 50, ` + empty + `}}`,
+		},
+		{
+			name:               "success_unplayed_combination",
+			path:               "/categories/science+music/stats?difficulty=easy",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"success": true, "message": "Category statistics retrieved successfully.", "data": {"category": "music+science", "difficulty": "easy", "passMark": 50, ` + empty + `}}`,
+		},
+		{
+			name:               "failure_due_to_invalid_category",
+			path:               "/categories/history/stats",
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"success": false, "code": "CATEGORY_NOT_FOUND", "message": "history is not a valid category."}`,
+		},
+		{
+			name:               "failure_due_to_combined_random",
+			path:               "/categories/random+music/stats",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"success": false, "code": "INVALID_COMBINATION", "message": "random cannot be combined with other categories."}`,
+		},
+		{
+			name:               "failure_due_to_invalid_difficulty",
+			path:               "/categories/science/stats?difficulty=extreme",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{"success": false, "code": "VALIDATION_FAILED", "message": "extreme is not a valid difficulty. Please choose easy, medium, hard.",
+				"details": [{"field": "difficulty", "message": "extreme is not a valid difficulty. Please choose easy, medium, hard."}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.JSONEq(t, tt.expectedResponse, rec.Body.String())
+		})
+	}
+}
+
 // TestGetQuestions tests the GetQuestions handler function
 func TestGetQuestions(t *testing.T) {
 	e := echo.New()
@@ -76,98 +186,182 @@ func TestGetQuestions(t *testing.T) {
 		name               string
 		setup              func()
 		category           string
+		difficulty         string
 		expectedStatusCode int
 		expectedResponse   string
 	}{
 		{
 			name: "successfully_retrieve_questions_for_science_category",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
+				globals.Bank = bank.New(map[string]models.Questions{
 					"science": {
 						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
 					},
-				}
+				}, nil)
 			},
 			category:           "science",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: `{
                 "success": true,
                 "message": "Questions successfully retrieved from the science category.",
-                "data": [
-                    {
-                        "id": 1,
-                        "category": "science",
-                        "question": "What is the chemical symbol for water?",
-                        "answers": ["H2O", "O2", "H2O2", "HO"],
-                        "correctAnswerIndex": 0
-                    }
-                ]
+                "data": {
+                    "category": "science",
+                    "categories": ["science"],
+                    "questions": [
+                        {
+                            "id": 1,
+                            "category": "science",
+                            "question": "What is the chemical symbol for water?",
+                            "answers": ["H2O", "O2", "H2O2", "HO"],
+                            "difficulty": "medium",
+                            "type": "single_choice"
+                        }
+                    ]
+                }
             }`,
 		},
 		{
 			name: "successfully_retrieve_random_questions_by_default",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
+				globals.Bank = bank.New(map[string]models.Questions{
 					"math": {
 						{ID: 3, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4", "5", "6"}, CorrectAnswerIndex: 1},
 					},
-				}
+				}, nil)
 			},
 			category:           "", // Random category will be selected by default
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: `{
                 "success": true,
                 "message": "Questions successfully retrieved from the random category.",
-                "data": [
-                    {
-                        "id": 3,
-                        "category": "math",
-                        "question": "What is 2 + 2?",
-                        "answers": ["3", "4", "5", "6"],
-                        "correctAnswerIndex": 1
-                    }
-                ]
+                "data": {
+                    "category": "random",
+                    "categories": ["random"],
+                    "questions": [
+                        {
+                            "id": 3,
+                            "category": "math",
+                            "question": "What is 2 + 2?",
+                            "answers": ["3", "4", "5", "6"],
+                            "difficulty": "medium",
+                            "type": "single_choice"
+                        }
+                    ]
+                }
+            }`,
+		},
+		{
+			name: "successfully_retrieve_questions_of_a_difficulty",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{
+					"science": {
+						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0, Difficulty: "easy"},
+						{ID: 2, Category: "science", Question: "What is the atomic number of carbon?", Answers: []string{"6", "12"}, CorrectAnswerIndex: 0, Difficulty: "hard"},
+					},
+				}, nil)
+			},
+			category:           "science",
+			difficulty:         " HARD ",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Questions successfully retrieved from the science (hard) category.",
+                "data": {
+                    "category": "science",
+                    "categories": ["science"],
+                    "difficulty": "hard",
+                    "questions": [
+                        {
+                            "id": 2,
+                            "category": "science",
+                            "question": "What is the atomic number of carbon?",
+                            "answers": ["6", "12"],
+                            "difficulty": "hard",
+                            "type": "single_choice"
+                        }
+                    ]
+                }
+            }`,
+		},
+		{
+			name: "failure_due_to_invalid_difficulty",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{
+					"science": {
+						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+					},
+				}, nil)
+			},
+			category:           "science",
+			difficulty:         "extreme",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "VALIDATION_FAILED",
+                "message": "extreme is not a valid difficulty. Please choose easy, medium, hard.",
+                "details": [{"field": "difficulty", "message": "extreme is not a valid difficulty. Please choose easy, medium, hard."}]
+            }`,
+		},
+		{
+			name: "failure_due_to_no_questions_of_difficulty",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{
+					"science": {
+						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+					},
+				}, nil)
+			},
+			category:           "random",
+			difficulty:         "easy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: `{
+                "success": false,
+                "code": "NO_QUESTIONS",
+                "message": "Currently there are no questions available for the random (easy) category. Please choose a different category or try again later."
             }`,
 		},
 		{
 			name: "failure_due_to_invalid_category",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
+				globals.Bank = bank.New(map[string]models.Questions{
 					"science": {
 						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
 					},
-				}
+				}, nil)
 			},
 			category:           "history",
 			expectedStatusCode: http.StatusNotFound,
 			expectedResponse: `{
                 "success": false,
+                "code": "CATEGORY_NOT_FOUND",
                 "message": "history is not a valid category."
             }`,
 		},
 		{
 			name: "failure_due_to_no_questions_for_specified_category",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
+				globals.Bank = bank.New(map[string]models.Questions{
 					"history": {},
-				}
+				}, nil)
 			},
 			category:           "history",
 			expectedStatusCode: http.StatusNotFound,
 			expectedResponse: `{
                 "success": false,
+                "code": "NO_QUESTIONS",
                 "message": "Currently there are no questions available for the history category. Please choose a different category or try again later."
             }`,
 		},
 		{
 			name: "failure_due_to_empty_questions_map",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{}
+				globals.Bank = bank.New(map[string]models.Questions{}, nil)
 			},
 			category:           "science",
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse: `{
                 "success": false,
+                "code": "INTERNAL_ERROR",
                 "message": "An unexpected error occurred. Please try again later."
             }`,
 		},
@@ -180,12 +374,549 @@ func TestGetQuestions(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/questions", nil)
 			q := req.URL.Query()
 			q.Add("category", tt.category)
+			if tt.difficulty != "" {
+				q.Add("difficulty", tt.difficulty)
+			}
 			req.URL.RawQuery = q.Encode()
 
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
 			if assert.NoError(t, GetQuestions(c)) {
+				assert.Equal(t, tt.expectedStatusCode, rec.Code)
+				assert.JSONEq(t, tt.expectedResponse, withoutSessionID(t, rec.Body.String()))
+			}
+		})
+	}
+}
+
+// TestGetQuestionsCount tests the number of questions issued by the GetQuestions handler function
+func TestGetQuestionsCount(t *testing.T) {
+	e := echo.New()
+	e.GET("/questions", GetQuestions)
+
+	scienceQuestions := models.Questions{}
+	for id := 1; id <= 8; id++ {
+		scienceQuestions = append(scienceQuestions, models.Question{ID: id, Category: "science", Question: fmt.Sprintf("Science question %d", id), Answers: []string{"Yes", "No"}})
+	}
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": scienceQuestions,
+		"math": {
+			{ID: 9, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4"}, CorrectAnswerIndex: 1},
+		},
+		"music": {
+			{ID: 10, Category: "music", Question: "Who is the lead vocalist of the band Queen?", Answers: []string{"Freddie Mercury", "John Lennon"}},
+		},
+	}, nil)
+
+	originalQuizLength := globals.QuizLength
+	globals.QuizLength = models.QuizLength{Min: 1, Max: 6, Default: 5, CategoryDefaults: map[string]int{"math": 1}}
+	defer func() { globals.QuizLength = originalQuizLength }()
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedCount      int
+		expectedMessage    string
+	}{
+		{"success_default_count", "category=science", http.StatusOK, 5, ""},
+		{"success_requested_count", "category=science&count=2", http.StatusOK, 2, ""},
+		{"success_maximum_count", "category=science&count=6", http.StatusOK, 6, ""},
+		{"success_category_default_count", "category=math", http.StatusOK, 1, ""},
+		{"success_fewer_questions_than_requested", "category=music&count=4", http.StatusOK, 1, ""},
+		{"success_stratified_random_quiz", "category=random&count=3", http.StatusOK, 3, ""},
+		{"success_uniform_random_quiz", "category=random&count=4&selection=uniform", http.StatusOK, 4, ""},
+		{"failure_due_to_count_below_minimum", "category=science&count=0", http.StatusBadRequest, 0, "0 is not a valid question count. Please choose a number between 1 and 6."},
+		{"failure_due_to_count_above_maximum", "category=science&count=7", http.StatusBadRequest, 0, "7 is not a valid question count. Please choose a number between 1 and 6."},
+		{"failure_due_to_count_which_is_not_a_number", "category=science&count=lots", http.StatusBadRequest, 0, "lots is not a valid question count. Please choose a number between 1 and 6."},
+		{"failure_due_to_invalid_selection", "category=random&selection=weighted", http.StatusBadRequest, 0, "weighted is not a valid selection. Please choose stratified or uniform."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/questions?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var questionsResponse struct {
+				Message string      `json:"message"`
+				Data    models.Quiz `json:"data"`
+			}
+			if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questionsResponse)) {
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.Len(t, questionsResponse.Data.Questions, tt.expectedCount)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, questionsResponse.Message)
+			}
+		})
+	}
+}
+
+// TestMultipleCategories checks that a quiz can be drawn from several categories and is scored against their combination
+func TestMultipleCategories(t *testing.T) {
+	e := echo.New()
+	e.GET("/questions", GetQuestions)
+	e.POST("/submit", SubmitAnswers)
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"music": {
+			{ID: 1, Category: "music", Question: "Who is the lead vocalist of the band Queen?", Answers: []string{"Freddie Mercury", "John Lennon"}, CorrectAnswerIndex: 0},
+		},
+		"computing": {
+			{ID: 2, Category: "computing", Question: "Which company is known for the search engine 'Bing'?", Answers: []string{"Microsoft", "Google"}, CorrectAnswerIndex: 0},
+		},
+		"science": {
+			{ID: 3, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"random":    {},
+		"music":     {},
+		"computing": {},
+		"science":   {},
+	})
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedCategory   string
+		expectedIDs        []int
+		expectedMessage    string
+	}{
+		{"success_comma_separated_categories", "category=Music, computing", http.StatusOK, "computing+music", []int{1, 2}, ""},
+		{"success_repeated_categories", "category=music&category=computing&category=music", http.StatusOK, "computing+music", []int{1, 2}, ""},
+		{"failure_due_to_invalid_category_within_combination", "category=music,history", http.StatusNotFound, "", nil, "history is not a valid category."},
+		{"failure_due_to_random_within_combination", "category=music,random", http.StatusBadRequest, "", nil, "random cannot be combined with other categories."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/questions?"+strings.ReplaceAll(tt.query, " ", "%20"), nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var questionsResponse struct {
+				Message string      `json:"message"`
+				Data    models.Quiz `json:"data"`
+			}
+			if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questionsResponse)) {
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, questionsResponse.Message)
+				return
+			}
+
+			ids := []int{}
+			for _, question := range questionsResponse.Data.Questions {
+				ids = append(ids, question.ID)
+			}
+			assert.ElementsMatch(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedCategory, questionsResponse.Data.Category)
+			assert.Equal(t, []string{"computing", "music"}, questionsResponse.Data.Categories)
+		})
+	}
+
+	// Submit a combined quiz with the music question answered correctly and the computing question answered incorrectly
+	session, err := globals.Sessions.Create("computing+music", "", models.Questions{globals.Bank.Questions()["music"][0], globals.Bank.Questions()["computing"][0]}, nil, nil, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	requestBody := `{"sessionId": "` + session.ID + `", "questionResponses": [{"questionId": 1, "answer": 0}, {"questionId": 2, "answer": 1}]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+        "success": true,
+        "message": "Submission processed successfully.",
+        "data": {
+            "scoreString": "1/2",
+            "scorePercentage": 50,
+            "comparison": "You are the first quizzer for the computing+music category.",
+            "breakdown": {
+                "computing": {"strategy": "standard", "score": 0, "total": 1, "scoreString": "0/1", "scorePercentage": 0},
+                "music": {"strategy": "standard", "score": 1, "total": 1, "scoreString": "1/1", "scorePercentage": 100}
+            },
+            "points": [
+                {"questionId": 1, "category": "music", "available": 1, "earned": 1, "total": 1},
+                {"questionId": 2, "category": "computing", "available": 1, "earned": 0, "total": 0}
+            ]
+        }
+    }`, rec.Body.String())
+
+	stats, err := globals.Scores.Stats("computing+music")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Count)
+
+	stats, err = globals.Scores.Stats("music")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Count, "Expected combined quizzes to be recorded separately from single categories")
+}
+
+// TestSubcategories checks that quizzes from a category draw from its subcategories, and that subcategory scores are
+// compared at each level of the category tree
+func TestSubcategories(t *testing.T) {
+	e := echo.New()
+	e.GET("/questions", GetQuestions)
+	e.POST("/submit", SubmitAnswers)
+	e.GET("/categories/:name/stats", GetCategoryStats)
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science/physics": {
+			{ID: 1, Category: "science/physics", Question: "What is the unit of force?", Answers: []string{"Newton", "Joule"}, CorrectAnswerIndex: 0},
+		},
+		"science/biology": {
+			{ID: 2, Category: "science/biology", Question: "What is the powerhouse of the cell?", Answers: []string{"Mitochondria", "Nucleus"}, CorrectAnswerIndex: 0},
+		},
+		"music": {
+			{ID: 3, Category: "music", Question: "Who is the lead vocalist of the band Queen?", Answers: []string{"Freddie Mercury", "John Lennon"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"random":          {},
+		"music":           {},
+		"science":         {80.0},
+		"science/physics": {40.0, 60.0},
+		"science/biology": {},
+	})
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedCategory   string
+		expectedIDs        []int
+		expectedMessage    string
+	}{
+		{"success_parent_draws_from_subcategories", "category=science", http.StatusOK, "science", []int{1, 2}, ""},
+		{"success_subcategory", "category=Science/Physics", http.StatusOK, "science/physics", []int{1}, ""},
+		{"success_subcategory_within_combination", "category=music,science/biology", http.StatusOK, "music+science/biology", []int{2, 3}, ""},
+		{"failure_due_to_partial_category_name", "category=sci", http.StatusNotFound, "", nil, "sci is not a valid category."},
+		{"failure_due_to_unknown_subcategory", "category=science/chemistry", http.StatusNotFound, "", nil, "science/chemistry is not a valid category."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/questions?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var questionsResponse struct {
+				Message string      `json:"message"`
+				Data    models.Quiz `json:"data"`
+			}
+			if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questionsResponse)) {
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, tt.expectedMessage, questionsResponse.Message)
+				return
+			}
+
+			ids := []int{}
+			for _, question := range questionsResponse.Data.Questions {
+				ids = append(ids, question.ID)
+			}
+			assert.ElementsMatch(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedCategory, questionsResponse.Data.Category)
+		})
+	}
+
+	// Submit a physics quiz which scores 50%, better than one of the two physics scores but none of the science scores
+	session, err := globals.Sessions.Create("science/physics", "", models.Questions{globals.Bank.Questions()["science/physics"][0], globals.Bank.Questions()["science/biology"][0]}, nil, nil, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	requestBody := `{"sessionId": "` + session.ID + `", "questionResponses": [{"questionId": 1, "answer": 0}, {"questionId": 2, "answer": 1}]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"comparison":"Your score for the science/physics category was better than 50% of all quizzers."`)
+	assert.Contains(t, rec.Body.String(), `"levels":[{"category":"science/physics","percentile":50},{"category":"science","percentile":0}]`)
+
+	// Subcategories are requested with the "/" escaped
+	req = httptest.NewRequest(http.MethodGet, "/categories/science%2Fphysics/stats", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"category":"science/physics"`)
+	assert.Contains(t, rec.Body.String(), `"count":3`)
+
+	stats, err := globals.Scores.Stats("science")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Count, "Expected subcategory scores to be counted towards their parent")
+}
+
+// TestGetQuestionsShufflesAnswers checks that the answers are shuffled differently between quiz sessions
+func TestGetQuestionsShufflesAnswers(t *testing.T) {
+	e := echo.New()
+	e.GET("/questions", GetQuestions)
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+
+	orders := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/questions?category=science", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var questionsResponse struct {
+			Data models.Quiz `json:"data"`
+		}
+		if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questionsResponse)) {
+			return
+		}
+
+		answers := questionsResponse.Data.Questions[0].Answers
+		assert.ElementsMatch(t, []string{"H2O", "O2", "H2O2", "HO"}, answers)
+		orders[strings.Join(answers, ",")] = true
+	}
+
+	assert.Greater(t, len(orders), 1, "Expected the answers to be shuffled differently between sessions")
+}
+
+// TestGetQuestionsTimeLimits checks that each question is issued with its time limit, which the session enforces
+func TestGetQuestionsTimeLimits(t *testing.T) {
+	e := echo.New()
+	e.GET("/questions", GetQuestions)
+
+	original := globals.TimeLimits
+	defer func() { globals.TimeLimits = original }()
+	globals.TimeLimits = models.TimeLimits{CategoryDefaults: map[string]int{"science": 30}}
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars"}, CorrectAnswerIndex: 1, TimeLimit: 15},
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/questions?category=science", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var questionsResponse struct {
+		Data models.Quiz `json:"data"`
+	}
+	if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questionsResponse)) {
+		return
+	}
+
+	limits := map[int]int{}
+	for _, question := range questionsResponse.Data.Questions {
+		limits[question.ID] = question.TimeLimit
+	}
+	assert.Equal(t, map[int]int{1: 30, 2: 15}, limits)
+
+	session, ok := globals.Sessions.Get(questionsResponse.Data.SessionID)
+	if assert.True(t, ok) {
+		assert.Equal(t, map[int]int{1: 30, 2: 15}, session.TimeLimits)
+	}
+}
+
+// TestCheckAnswer tests the CheckAnswer handler function
+func TestCheckAnswer(t *testing.T) {
+	e := echo.New()
+	boilingPoint := 100.0
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+			{ID: 3, Category: "science", Type: models.TypeMultiSelect, Question: "Which of these are noble gases?", Answers: []string{"Helium", "Oxygen", "Neon", "Nitrogen"}, CorrectAnswerIndexes: []int{0, 2}},
+			{ID: 4, Category: "science", Type: models.TypeFreeText, Question: "What is the chemical symbol for gold?", AcceptedAnswers: []string{"Au"}},
+			{ID: 5, Category: "science", Type: models.TypeNumeric, Question: "At what temperature does water boil at sea level?", CorrectValue: &boilingPoint, AbsoluteTolerance: 2, Unit: "degrees Celsius"},
+			{ID: 6, Category: "science", Type: models.TypeOrdering, Question: "Order these planets by distance from the Sun.", Answers: []string{"Mercury", "Venus", "Earth"}},
+		},
+	}, nil)
+
+	session, err := globals.Sessions.Create("science", "", globals.Bank.Questions()["science"], nil, nil, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The answers of each question were shown in a shuffled order
+	shuffled, err := globals.Sessions.Create("science", "", globals.Bank.Questions()["science"], map[int][]int{
+		1: {3, 2, 1, 0},
+		2: {1, 0, 2, 3},
+		6: {2, 0, 1},
+	}, nil, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name               string
+		sessionID          string
+		requestBody        string
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "successfully_checked_correct_answer",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 1, "answer": 0}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 1, "correct": true, "credit": 1, "correctAnswerIndex": 0, "correctAnswer": "H2O"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_incorrect_answer",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 2, "answer": 3}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 2, "correct": false, "credit": 0, "correctAnswerIndex": 1, "correctAnswer": "Mars"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_correct_shuffled_answer",
+			sessionID:          shuffled.ID,
+			requestBody:        `{"questionId": 1, "answer": 3}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 1, "correct": true, "credit": 1, "correctAnswerIndex": 3, "correctAnswer": "H2O"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_incorrect_shuffled_answer",
+			sessionID:          shuffled.ID,
+			requestBody:        `{"questionId": 2, "answer": 1}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 2, "correct": false, "credit": 0, "correctAnswerIndex": 0, "correctAnswer": "Mars"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_partially_correct_multi_select_answer",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 3, "answers": [2]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 3, "correct": false, "credit": 0.5, "correctAnswerIndex": 0, "correctAnswerIndexes": [0, 2], "correctAnswer": "Helium, Neon"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_correct_free_text_answer",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 4, "text": "AU"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 4, "correct": true, "credit": 1, "correctAnswerIndex": 0, "correctAnswer": "Au"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_close_numeric_answer",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 5, "value": 97}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 5, "correct": false, "credit": 0.5, "correctAnswerIndex": 0, "correctAnswer": "100 degrees Celsius"}
+            }`,
+		},
+		{
+			name:               "successfully_checked_correct_shuffled_ordering_answer",
+			sessionID:          shuffled.ID,
+			requestBody:        `{"questionId": 6, "answers": [1, 2, 0]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Answer checked successfully.",
+                "data": {"questionId": 6, "correct": true, "credit": 1, "correctAnswerIndex": 1, "correctAnswerIndexes": [1, 2, 0], "correctAnswer": "Mercury, Venus, Earth"}
+            }`,
+		},
+		{
+			name:               "failure_due_to_question_already_answered",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 2, "answer": 1}`,
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: `{
+                "success": false,
+                "code": "ALREADY_ANSWERED",
+                "message": "Question 2 has already been answered."
+            }`,
+		},
+		{
+			name:               "failure_due_to_question_not_issued_for_session",
+			sessionID:          session.ID,
+			requestBody:        `{"questionId": 7, "answer": 0}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "QUESTION_NOT_ISSUED",
+                "message": "Question 7 was not issued for this quiz session."
+            }`,
+		},
+		{
+			name:               "failure_due_to_unknown_session",
+			sessionID:          "abc123",
+			requestBody:        `{"questionId": 1, "answer": 0}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: `{
+                "success": false,
+                "code": "SESSION_NOT_FOUND",
+                "message": "Quiz session abc123 was not found or has expired."
+            }`,
+		},
+		{
+			name:               "failure_due_to_invalid_request_format",
+			sessionID:          session.ID,
+			requestBody:        `invalid json`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "INVALID_REQUEST",
+                "message": "Invalid request format."
+            }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sessions/"+tt.sessionID+"/answers", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.sessionID)
+
+			if assert.NoError(t, CheckAnswer(c)) {
 				assert.Equal(t, tt.expectedStatusCode, rec.Code)
 				assert.JSONEq(t, tt.expectedResponse, rec.Body.String())
 			}
@@ -197,9 +928,15 @@ func TestGetQuestions(t *testing.T) {
 func TestSubmitAnswers(t *testing.T) {
 	e := echo.New()
 
+	scienceQuestions := models.Questions{
+		{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+		{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+	}
+
 	tests := []struct {
 		name               string
 		setup              func()
+		checkedAnswers     map[int]int
 		requestBody        string
 		expectedStatusCode int
 		expectedResponse   string
@@ -207,39 +944,16 @@ func TestSubmitAnswers(t *testing.T) {
 		{
 			name: "successfully_processed_submission_without_comparison",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
-					"science": {
-						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
-						{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
-					},
-				}
-				globals.CategoryScores = map[string][]float64{
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
 					"science": {},
-				}
+				})
 			},
 			requestBody: `{
-                "category": "science",
+                "sessionId": "{sessionId}",
                 "questionResponses": [
-                    {
-                        "question": {
-                            "id": 1,
-                            "category": "science",
-                            "question": "What is the chemical symbol for water?",
-                            "answers": ["H2O", "O2", "H2O2", "HO"],
-                            "correctAnswerIndex": 0
-                        },
-                        "answer": 0
-                    },
-                    {
-                        "question": {
-                            "id": 2,
-                            "category": "science",
-                            "question": "What planet is known as the Red Planet?",
-                            "answers": ["Earth", "Mars", "Jupiter", "Venus"],
-                            "correctAnswerIndex": 1
-                        },
-                        "answer": 1
-                    }
+                    {"questionId": 1, "answer": 0},
+                    {"questionId": 2, "answer": 1}
                 ]
             }`,
 			expectedStatusCode: http.StatusOK,
@@ -249,46 +963,28 @@ func TestSubmitAnswers(t *testing.T) {
                 "data": {
                     "scoreString": "2/2",
                     "scorePercentage": 100,
-                    "comparison": "You are the first quizzer for the science category."
+                    "comparison": "You are the first quizzer for the science category.",
+                    "breakdown": {"science": {"strategy": "standard", "score": 2, "total": 2, "scoreString": "2/2", "scorePercentage": 100}},
+                    "points": [
+                        {"questionId": 1, "category": "science", "available": 1, "earned": 1, "total": 1},
+                        {"questionId": 2, "category": "science", "available": 1, "earned": 1, "total": 1}
+                    ]
                 }
             }`,
 		},
 		{
 			name: "successfully_processed_submission_with_comparison",
 			setup: func() {
-				globals.Questions = map[string]models.Questions{
-					"science": {
-						{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
-						{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
-					},
-				}
-				globals.CategoryScores = map[string][]float64{
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
 					"science": {20, 30},
-				}
+				})
 			},
 			requestBody: `{
-                "category": "science",
+                "sessionId": "{sessionId}",
                 "questionResponses": [
-                    {
-                        "question": {
-                            "id": 1,
-                            "category": "science",
-                            "question": "What is the chemical symbol for water?",
-                            "answers": ["H2O", "O2", "H2O2", "HO"],
-                            "correctAnswerIndex": 0
-                        },
-                        "answer": 0
-                    },
-                    {
-                        "question": {
-                            "id": 2,
-                            "category": "science",
-                            "question": "What planet is known as the Red Planet?",
-                            "answers": ["Earth", "Mars", "Jupiter", "Venus"],
-                            "correctAnswerIndex": 1
-                        },
-                        "answer": 1
-                    }
+                    {"questionId": 1, "answer": 0},
+                    {"questionId": 2, "answer": 1}
                 ]
             }`,
 			expectedStatusCode: http.StatusOK,
@@ -298,120 +994,232 @@ func TestSubmitAnswers(t *testing.T) {
                 "data": {
                     "scoreString": "2/2",
                     "scorePercentage": 100,
-                    "comparison": "Your score for the science category was better than 100% of all quizzers."
+                    "comparison": "Your score for the science category was better than 100% of all quizzers.",
+                    "breakdown": {"science": {"strategy": "standard", "score": 2, "total": 2, "scoreString": "2/2", "scorePercentage": 100}},
+                    "points": [
+                        {"questionId": 1, "category": "science", "available": 1, "earned": 1, "total": 1},
+                        {"questionId": 2, "category": "science", "available": 1, "earned": 1, "total": 1}
+                    ]
+                }
+            }`,
+		},
+		{
+			name: "forged_question_data_is_ignored",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
+					"science": {},
+				})
+			},
+			requestBody: `{
+                "sessionId": "{sessionId}",
+                "questionResponses": [
+                    {"questionId": 1, "answer": 3, "question": {"id": 1, "correctAnswerIndex": 3}},
+                    {"questionId": 2, "answer": 1}
+                ]
+            }`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Submission processed successfully.",
+                "data": {
+                    "scoreString": "1/2",
+                    "scorePercentage": 50,
+                    "comparison": "You are the first quizzer for the science category.",
+                    "breakdown": {"science": {"strategy": "standard", "score": 1, "total": 2, "scoreString": "1/2", "scorePercentage": 50}},
+                    "points": [
+                        {"questionId": 1, "category": "science", "available": 1, "earned": 0, "total": 0},
+                        {"questionId": 2, "category": "science", "available": 1, "earned": 1, "total": 1}
+                    ]
+                }
+            }`,
+		},
+		{
+			name: "checked_answers_are_locked_in",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
+					"science": {},
+				})
+			},
+			checkedAnswers: map[int]int{1: 2},
+			requestBody: `{
+                "sessionId": "{sessionId}",
+                "questionResponses": [
+                    {"questionId": 1, "answer": 0},
+                    {"questionId": 2, "answer": 1}
+                ]
+            }`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{
+                "success": true,
+                "message": "Submission processed successfully.",
+                "data": {
+                    "scoreString": "1/2",
+                    "scorePercentage": 50,
+                    "comparison": "You are the first quizzer for the science category.",
+                    "breakdown": {"science": {"strategy": "standard", "score": 1, "total": 2, "scoreString": "1/2", "scorePercentage": 50}},
+                    "points": [
+                        {"questionId": 1, "category": "science", "available": 1, "earned": 0, "total": 0},
+                        {"questionId": 2, "category": "science", "available": 1, "earned": 1, "total": 1}
+                    ]
                 }
+            }`,
+		},
+		{
+			name: "failure_due_to_question_not_issued_for_session",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
+					"science": {},
+				})
+			},
+			requestBody: `{
+                "sessionId": "{sessionId}",
+                "questionResponses": [
+                    {"questionId": 7, "answer": 0}
+                ]
+            }`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "INVALID_SUBMISSION",
+                "message": "Failed to process submission: one or more answers were invalid"
             }`,
 		},
 		{
 			name: "failure_due_to_invalid_request_format",
 			setup: func() {
-				globals.CategoryScores = map[string][]float64{
+				globals.Scores = newScoreStore(map[string][]float64{
 					"science": {50.0, 60.0},
-				}
+				})
 			},
 			requestBody:        `invalid json`,
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse: `{
                 "success": false,
+                "code": "INVALID_REQUEST",
                 "message": "Invalid request format."
             }`,
 		},
 		{
 			name: "failure_due_to_no_answers_submitted",
 			setup: func() {
-				globals.CategoryScores = map[string][]float64{
+				globals.Scores = newScoreStore(map[string][]float64{
 					"science": {50.0, 60.0},
-				}
+				})
 			},
 			requestBody: `{
-                "category": "science",
+                "sessionId": "{sessionId}",
                 "questionResponses": []
             }`,
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse: `{
                 "success": false,
+                "code": "INVALID_SUBMISSION",
                 "message": "No answers were submitted."
             }`,
 		},
 		{
-			name: "failure_due_to_empty_category_string",
+			name: "failure_due_to_empty_session_id",
 			setup: func() {
-				globals.CategoryScores = map[string][]float64{
+				globals.Scores = newScoreStore(map[string][]float64{
 					"science": {50.0, 60.0},
-				}
+				})
 			},
 			requestBody: `{
-                "category": "",
+                "sessionId": "",
                 "questionResponses": [
-                    {
-                        "question": {
-                            "id": 1,
-                            "category": "science",
-                            "question": "What is the chemical symbol for water?",
-                            "answers": ["H2O", "O2", "H2O2", "HO"],
-                            "correctAnswerIndex": 0
-                        },
-                        "answer": 0
-                    }
+                    {"questionId": 1, "answer": 0}
                 ]
             }`,
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse: `{
                 "success": false,
-                "message": "A category must be provided."
+                "code": "VALIDATION_FAILED",
+                "message": "A session ID must be provided.",
+                "details": [{"field": "sessionId", "message": "A session ID must be provided."}]
             }`,
 		},
 		{
-			name: "failure_due_to_invalid_category",
+			name: "failure_due_to_unknown_session",
 			setup: func() {
-				globals.CategoryScores = map[string][]float64{
+				globals.Scores = newScoreStore(map[string][]float64{
 					"science": {50.0, 60.0},
-				}
+				})
 			},
 			requestBody: `{
-                "category": "history",
+                "sessionId": "abc123",
                 "questionResponses": [
-                    {
-                        "question": {
-                            "id": 1,
-                            "category": "science",
-                            "question": "What is the chemical symbol for water?",
-                            "answers": ["H2O", "O2", "H2O2", "HO"],
-                            "correctAnswerIndex": 0
-                        },
-                        "answer": 0
-                    }
+                    {"questionId": 1, "answer": 0}
                 ]
             }`,
 			expectedStatusCode: http.StatusNotFound,
 			expectedResponse: `{
                 "success": false,
-                "message": "history is not a valid category."
+                "code": "SESSION_NOT_FOUND",
+                "message": "Quiz session abc123 was not found or has expired."
+            }`,
+		},
+		{
+			name: "failure_due_to_invalid_category",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
+					"history": {50.0, 60.0},
+				})
+			},
+			requestBody: `{
+                "sessionId": "{sessionId}",
+                "questionResponses": [
+                    {"questionId": 1, "answer": 0}
+                ]
+            }`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: `{
+                "success": false,
+                "code": "CATEGORY_NOT_FOUND",
+                "message": "science is not a valid category."
+            }`,
+		},
+		{
+			name: "failure_due_to_invalid_player_name",
+			setup: func() {
+				globals.Bank = bank.New(map[string]models.Questions{"science": scienceQuestions}, nil)
+				globals.Scores = newScoreStore(map[string][]float64{
+					"science": {},
+				})
+			},
+			requestBody: `{
+                "sessionId": "{sessionId}",
+                "playerName": "A name which is far too long for the leaderboard",
+                "questionResponses": [
+                    {"questionId": 1, "answer": 0}
+                ]
+            }`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse: `{
+                "success": false,
+                "code": "VALIDATION_FAILED",
+                "message": "Invalid player name: player name must be at most 32 characters.",
+                "details": [{"field": "playerName", "message": "Invalid player name: player name must be at most 32 characters."}]
             }`,
 		},
 		{
 			name: "failure_due_to_uninitialized_category_scores",
 			setup: func() {
-				globals.CategoryScores = map[string][]float64{}
+				globals.Scores = newScoreStore(map[string][]float64{})
 			},
 			requestBody: `{
-                "category": "science",
+                "sessionId": "{sessionId}",
                 "questionResponses": [
-                    {
-                        "question": {
-                            "id": 1,
-                            "category": "science",
-                            "question": "What is the chemical symbol for water?",
-                            "answers": ["H2O", "O2", "H2O2", "HO"],
-                            "correctAnswerIndex": 0
-                        },
-                        "answer": 0
-                    }
+                    {"questionId": 1, "answer": 0}
                 ]
             }`,
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse: `{
                 "success": false,
+                "code": "INTERNAL_ERROR",
                 "message": "An unexpected error occurred. Please try again later."
             }`,
 		},
@@ -421,7 +1229,16 @@ func TestSubmitAnswers(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setup()
 
-			req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(tt.requestBody))
+			session, err := globals.Sessions.Create("science", "", scienceQuestions, nil, nil, "")
+			if !assert.NoError(t, err) {
+				return
+			}
+			for questionID, answer := range tt.checkedAnswers {
+				assert.NoError(t, globals.Sessions.RecordAnswer(session.ID, models.QuestionResponse{QuestionID: questionID, Answer: answer}))
+			}
+			requestBody := strings.ReplaceAll(tt.requestBody, "{sessionId}", session.ID)
+
+			req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(requestBody))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
@@ -433,3 +1250,181 @@ func TestSubmitAnswers(t *testing.T) {
 		})
 	}
 }
+
+// TestSubmitAnswersOnlyOncePerSession checks that a quiz session cannot be submitted twice
+func TestSubmitAnswersOnlyOncePerSession(t *testing.T) {
+	e := echo.New()
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"science": {},
+	})
+
+	session, err := globals.Sessions.Create("science", "", globals.Bank.Questions()["science"], nil, nil, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	requestBody := `{"sessionId": "` + session.ID + `", "questionResponses": [{"questionId": 1, "answer": 0}]}`
+
+	expectedStatusCodes := []int{http.StatusOK, http.StatusNotFound}
+	for _, expectedStatusCode := range expectedStatusCodes {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(requestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if assert.NoError(t, SubmitAnswers(c)) {
+			assert.Equal(t, expectedStatusCode, rec.Code)
+		}
+	}
+}
+
+// TestSubmitAnswersByDifficulty checks that quizzes of a difficulty are only compared against the same difficulty
+func TestSubmitAnswersByDifficulty(t *testing.T) {
+	e := echo.New()
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0, Difficulty: "hard"},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"science": {0.0, 0.0, 0.0},
+	})
+
+	expectedComparisons := []string{
+		"You are the first quizzer for the science (hard) category.",
+		"Your score for the science (hard) category was better than 0% of all quizzers.",
+	}
+	for _, expectedComparison := range expectedComparisons {
+		session, err := globals.Sessions.Create("science", "hard", globals.Bank.Questions()["science"], nil, nil, "")
+		if !assert.NoError(t, err) {
+			return
+		}
+		requestBody := `{"sessionId": "` + session.ID + `", "questionResponses": [{"questionId": 1, "answer": 0}]}`
+
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(requestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if assert.NoError(t, SubmitAnswers(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), expectedComparison)
+		}
+	}
+
+	stats, err := globals.Scores.Stats("science")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.Count)
+
+	stats, err = globals.Scores.Stats("science:hard")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+}
+
+// TestSubmitAnswersConcurrently hammers the submit endpoint from many goroutines. Run with -race to detect data races.
+func TestSubmitAnswersConcurrently(t *testing.T) {
+	e := echo.New()
+	e.GET("/questions", GetQuestions)
+	e.POST("/submit", SubmitAnswers)
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{
+		"random":  {},
+		"science": {},
+	})
+
+	const quizzers = 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < quizzers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodGet, "/questions?category=science", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var questionsResponse struct {
+				Data models.Quiz `json:"data"`
+			}
+			if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questionsResponse)) {
+				return
+			}
+
+			requestBody := fmt.Sprintf(`{"sessionId": "%s", "questionResponses": [{"questionId": 1, "answer": %d}]}`, questionsResponse.Data.SessionID, i%2)
+			req = httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec = httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}(i)
+	}
+	wg.Wait()
+
+	stats, err := globals.Scores.Stats("science")
+	assert.NoError(t, err)
+	assert.Equal(t, quizzers, stats.Count)
+}
+
+// newScoreStore is a helper function which returns an in-memory score store populated with the specified scores
+func newScoreStore(categoryScores map[string][]float64) scores.ScoreStore {
+	store := scores.NewMemoryStore()
+	for category, categoryScores := range categoryScores {
+		store.AddCategory(category)
+		for _, score := range categoryScores {
+			store.Append(category, score)
+		}
+	}
+	return store
+}
+
+// withoutSessionID is a helper function which removes the randomly generated session ID from a questions response.
+// The shuffled answers are restored to their original order using the order recorded for the session.
+func withoutSessionID(t *testing.T, body string) string {
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("failed to unmarshal response body: %v", err)
+	}
+
+	if data, ok := payload["data"].(map[string]interface{}); ok {
+		assert.NotEmpty(t, data["sessionId"], "Expected a session ID to be issued")
+		session, ok := globals.Sessions.Get(data["sessionId"].(string))
+		assert.True(t, ok, "Expected the session to be stored")
+		delete(data, "sessionId")
+
+		questions, _ := data["questions"].([]interface{})
+		for _, q := range questions {
+			question := q.(map[string]interface{})
+			shown := question["answers"].([]interface{})
+			order := session.AnswerOrders[int(question["id"].(float64))]
+			if !assert.Len(t, order, len(shown), "Expected the answer order to be recorded") {
+				continue
+			}
+
+			original := make([]interface{}, len(shown))
+			for i, answer := range shown {
+				original[order[i]] = answer
+			}
+			question["answers"] = original
+		}
+	}
+
+	res, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal response body: %v", err)
+	}
+	return string(res)
+}
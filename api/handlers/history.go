@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/globals"
+
+	"github.com/labstack/echo"
+)
+
+const (
+	// DefaultHistoryPageSize is the number of quizzes shown on a page of history when no limit is requested
+	DefaultHistoryPageSize = 10
+	// MaxHistoryPageSize is the most quizzes which may be requested for a page of history
+	MaxHistoryPageSize = 50
+)
+
+// GetHistory returns a page of the authenticated user's past quizzes, newest first, with the outcome of each question
+func GetHistory(c echo.Context) error {
+	page := 1
+	pageParam := strings.Trim(c.QueryParam("page"), " ")
+	if len(pageParam) > 0 {
+		requested, err := strconv.Atoi(pageParam)
+		if err != nil || requested < 1 {
+			msg := pageParam + " is not a valid page. Please choose a number of at least 1."
+			return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "page", Message: msg})
+		}
+		page = requested
+	}
+
+	limit := DefaultHistoryPageSize
+	limitParam := strings.Trim(c.QueryParam("limit"), " ")
+	if len(limitParam) > 0 {
+		requested, err := strconv.Atoi(limitParam)
+		if err != nil || requested < 1 || requested > MaxHistoryPageSize {
+			msg := fmt.Sprintf("%s is not a valid limit. Please choose a number between 1 and %d.", limitParam, MaxHistoryPageSize)
+			return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "limit", Message: msg})
+		}
+		limit = requested
+	}
+
+	results, err := globals.Accounts.Results(currentUser(c))
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	// Results are stored oldest first, so pages are counted back from the end. Pages beyond the last are empty, and
+	// are checked before multiplying so that very large pages cannot overflow.
+	history := []accounts.Result{}
+	if pages := (len(results) + limit - 1) / limit; page-1 < pages {
+		end := len(results) - (page-1)*limit
+		for i := end - 1; i >= 0 && i >= end-limit; i-- {
+			history = append(history, results[i])
+		}
+	}
+
+	res := map[string]interface{}{
+		"page":    page,
+		"limit":   limit,
+		"total":   len(results),
+		"results": history,
+	}
+	return prepareResponse(c, true, "History retrieved successfully.", http.StatusOK, res)
+}
+
+// GetStats returns the authenticated user's statistics, calculated from every quiz they have submitted
+func GetStats(c echo.Context) error {
+	results, err := globals.Accounts.Results(currentUser(c))
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	return prepareResponse(c, true, "Statistics retrieved successfully.", http.StatusOK, accounts.Summarise(results))
+}
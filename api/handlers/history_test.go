@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/globals"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetHistory tests paging through the GetHistory handler function, newest quizzes first
+func TestGetHistory(t *testing.T) {
+	tokens := useTestAccounts(t)
+	_, err := globals.Accounts.Create(accounts.User{Username: "bob"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 12; i++ {
+		result := accounts.Result{
+			Username:    "ada",
+			Category:    "science",
+			Score:       float64(i),
+			ScoreString: fmt.Sprintf("%d/100", i),
+			Outcomes:    []accounts.Outcome{{QuestionID: 1, Category: "science", Credit: 1, Points: 1, Available: 1}},
+			CreatedAt:   start.AddDate(0, 0, i),
+		}
+		if !assert.NoError(t, globals.Accounts.RecordResult(result)) {
+			return
+		}
+	}
+
+	e := echo.New()
+	e.GET("/me/history", GetHistory, Authenticate(tokens, true))
+
+	adaToken := issueTestToken(t, tokens, "ada", time.Now())
+	bobToken := issueTestToken(t, tokens, "bob", time.Now())
+
+	tests := []struct {
+		name           string
+		query          string
+		token          string
+		expectedStatus int
+		expectedScores []float64
+		expectedBody   string
+	}{
+		{"success_first_page", "", adaToken, http.StatusOK, []float64{12, 11, 10, 9, 8, 7, 6, 5, 4, 3}, `"total":12`},
+		{"success_last_page", "?page=2", adaToken, http.StatusOK, []float64{2, 1}, `"limit":10,"page":2`},
+		{"success_custom_limit", "?page=3&limit=5", adaToken, http.StatusOK, []float64{2, 1}, `"limit":5,"page":3`},
+		{"success_beyond_last_page", "?page=4&limit=5", adaToken, http.StatusOK, []float64{}, `"limit":5,"page":4`},
+		{"success_very_large_page", "?page=6148914691236517206&limit=3", adaToken, http.StatusOK, []float64{}, `"limit":3,"page":6148914691236517206`},
+		{"success_no_quizzes", "", bobToken, http.StatusOK, []float64{}, `"limit":10,"page":1,"results":[],"total":0`},
+		{"failure_due_to_invalid_page", "?page=0", adaToken, http.StatusBadRequest, nil, `{"success":false,"code":"VALIDATION_FAILED","message":"0 is not a valid page. Please choose a number of at least 1.","details":[{"field":"page","message":"0 is not a valid page. Please choose a number of at least 1."}]}`},
+		{"failure_due_to_invalid_limit", "?limit=51", adaToken, http.StatusBadRequest, nil, `{"success":false,"code":"VALIDATION_FAILED","message":"51 is not a valid limit. Please choose a number between 1 and 50.","details":[{"field":"limit","message":"51 is not a valid limit. Please choose a number between 1 and 50."}]}`},
+		{"failure_due_to_missing_token", "", "", http.StatusUnauthorized, nil, `{"success":false,"code":"UNAUTHORIZED","message":"An access token must be provided. Please log in first."}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me/history"+tt.query, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			if tt.expectedScores != nil {
+				var history struct {
+					Data struct {
+						Results []accounts.Result `json:"results"`
+					} `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+
+				scores := []float64{}
+				for _, result := range history.Data.Results {
+					scores = append(scores, result.Score)
+					assert.Len(t, result.Outcomes, 1, "Expected the outcome of each question")
+				}
+				assert.Equal(t, tt.expectedScores, scores)
+			}
+		})
+	}
+}
+
+// TestGetStats checks that the GetStats handler function summarises only the authenticated user's results
+func TestGetStats(t *testing.T) {
+	tokens := useTestAccounts(t)
+	_, err := globals.Accounts.Create(accounts.User{Username: "bob"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	results := []accounts.Result{
+		{Username: "ada", Category: "science", Score: 50, CreatedAt: now, Outcomes: []accounts.Outcome{
+			{QuestionID: 1, Category: "science", Credit: 1},
+			{QuestionID: 2, Category: "science", Credit: 0},
+		}},
+		{Username: "bob", Category: "music", Score: 100, CreatedAt: now, Outcomes: []accounts.Outcome{
+			{QuestionID: 3, Category: "music", Credit: 1},
+		}},
+		{Username: "ada", Category: "music", Score: 100, CreatedAt: now.Add(time.Hour), Outcomes: []accounts.Outcome{
+			{QuestionID: 3, Category: "music", Credit: 1},
+		}},
+	}
+	for _, result := range results {
+		if !assert.NoError(t, globals.Accounts.RecordResult(result)) {
+			return
+		}
+	}
+
+	e := echo.New()
+	e.GET("/me/stats", GetStats, Authenticate(tokens, true))
+
+	req := httptest.NewRequest(http.MethodGet, "/me/stats", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+issueTestToken(t, tokens, "ada", time.Now()))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var res struct {
+		Success bool           `json:"success"`
+		Message string         `json:"message"`
+		Data    accounts.Stats `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.True(t, res.Success)
+	assert.Equal(t, "Statistics retrieved successfully.", res.Message)
+	assert.Equal(t, 2, res.Data.Quizzes)
+	assert.Equal(t, 75.0, res.Data.AverageScore)
+	assert.Equal(t, "music", res.Data.BestCategory)
+	assert.Equal(t, "science", res.Data.WorstCategory)
+	assert.Equal(t, []float64{50, 100}, res.Data.Recent)
+
+	req = httptest.NewRequest(http.MethodGet, "/me/stats", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
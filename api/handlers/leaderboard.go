@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quizwizard/api/bank"
+	"quizwizard/api/globals"
+	"quizwizard/api/scores"
+
+	"github.com/labstack/echo"
+)
+
+const (
+	// DefaultLeaderboardSize is the number of places shown on a leaderboard when no limit is requested
+	DefaultLeaderboardSize = 10
+	// MaxLeaderboardSize is the most places which may be requested for a leaderboard
+	MaxLeaderboardSize = 100
+)
+
+// GetLeaderboard ranks the best score of each named player for a category, or combination of categories, over a period.
+// Every category is ranked together if none is specified, and every score is ranked if no period is specified.
+func GetLeaderboard(c echo.Context) error {
+	categories := parseCategories(c.QueryParams()["category"])
+	questions := globals.Bank.Questions()
+	for _, name := range categories {
+		if name == "random" && len(categories) > 1 {
+			msg := "random cannot be combined with other categories."
+			return prepareErrorResponse(c, CodeInvalidCombination, msg, http.StatusBadRequest)
+		}
+
+		if len(bank.Subtree(questions, name)) == 0 && name != "random" {
+			msg := name + " is not a valid category."
+			return prepareErrorResponse(c, CodeCategoryNotFound, msg, http.StatusNotFound)
+		}
+	}
+	category := scores.Combination(categories)
+
+	period := c.QueryParam("period")
+	period = strings.Trim(period, " ")
+	period = strings.ToLower(period)
+	if len(period) == 0 {
+		period = scores.PeriodAll
+	}
+
+	since, err := scores.PeriodStart(period, time.Now())
+	if err != nil {
+		msg := period + " is not a valid period. Please choose " + strings.Join(scores.Periods, ", ") + "."
+		return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "period", Message: msg})
+	}
+
+	limit := DefaultLeaderboardSize
+	limitParam := strings.Trim(c.QueryParam("limit"), " ")
+	if len(limitParam) > 0 {
+		requested, err := strconv.Atoi(limitParam)
+		if err != nil || requested < 1 || requested > MaxLeaderboardSize {
+			msg := fmt.Sprintf("%s is not a valid limit. Please choose a number between 1 and %d.", limitParam, MaxLeaderboardSize)
+			return prepareErrorResponse(c, CodeValidationFailed, msg, http.StatusBadRequest, FieldError{Field: "limit", Message: msg})
+		}
+		limit = requested
+	}
+
+	rankings, err := globals.Leaderboard.Top(category, since, limit)
+	if err != nil {
+		return prepareInternalErrorResponse(c)
+	}
+
+	res := map[string]interface{}{
+		"category": category,
+		"period":   period,
+		"rankings": rankings,
+	}
+	return prepareResponse(c, true, "Leaderboard retrieved successfully.", http.StatusOK, res)
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/bank"
+	"quizwizard/api/globals"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSubmitAnswersRecordsPlayer checks that only submissions with a player name are ranked on the leaderboard
+func TestSubmitAnswersRecordsPlayer(t *testing.T) {
+	e := echo.New()
+	e.POST("/submit", SubmitAnswers)
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars"}, CorrectAnswerIndex: 1},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{"science": {}})
+	globals.Leaderboard = scores.NewMemoryLeaderboard()
+
+	for _, player := range []string{"  Ada   Lovelace ", ""} {
+		session, err := globals.Sessions.Create("science", "", globals.Bank.Questions()["science"], nil, nil, "")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		body, _ := json.Marshal(models.QuizResponse{
+			SessionID:         session.ID,
+			PlayerName:        player,
+			QuestionResponses: []models.QuestionResponse{{QuestionID: 1, Answer: 0}, {QuestionID: 2, Answer: 0}},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(string(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rankings, err := globals.Leaderboard.Top("", time.Time{}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, rankings, 1, "Expected anonymous submissions to be left off the leaderboard") {
+		assert.Equal(t, "Ada Lovelace", rankings[0].Player)
+		assert.Equal(t, "science", rankings[0].Category)
+		assert.Equal(t, 50.0, rankings[0].Score)
+		assert.Equal(t, "1/2", rankings[0].ScoreString)
+	}
+}
+
+// failingLeaderboard is a leaderboard which cannot store entries
+type failingLeaderboard struct {
+	scores.Leaderboard
+}
+
+func (failingLeaderboard) Record(scores.Entry) error {
+	return errors.New("database is locked")
+}
+
+// TestSubmitAnswersKeepsSessionWhenSaveFails checks that a submission which cannot be saved is reported as an internal
+// error and can be submitted again without saving any part of it twice
+func TestSubmitAnswersKeepsSessionWhenSaveFails(t *testing.T) {
+	e := echo.New()
+	e.POST("/submit", SubmitAnswers, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(userContextKey, "ada")
+			return next(c)
+		}
+	})
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+	globals.Scores = newScoreStore(map[string][]float64{"science": {}})
+	globals.Leaderboard = failingLeaderboard{Leaderboard: scores.NewMemoryLeaderboard()}
+	globals.Accounts = accounts.NewMemoryStore()
+
+	session, err := globals.Sessions.Create("science", "", globals.Bank.Questions()["science"], nil, nil, "ada")
+	if !assert.NoError(t, err) {
+		return
+	}
+	body := `{"sessionId": "` + session.ID + `", "playerName": "Ada", "questionResponses": [{"questionId": 1, "answer": 0}]}`
+
+	submit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	scoreCount := func() int {
+		stats, err := globals.Scores.Stats("science")
+		assert.NoError(t, err)
+		return stats.Count
+	}
+	leaderboardRows := func() int {
+		rankings, err := globals.Leaderboard.Top("", time.Time{}, 10)
+		assert.NoError(t, err)
+		return len(rankings)
+	}
+
+	// The leaderboard fails after the score has been saved
+	rec := submit()
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"success": false, "code": "INTERNAL_ERROR", "message": "An unexpected error occurred. Please try again later."}`, rec.Body.String())
+	_, ok := globals.Sessions.Get(session.ID)
+	assert.True(t, ok, "Expected the session to be kept")
+	assert.Equal(t, 1, scoreCount())
+
+	// The result cannot be recorded for a user who does not exist, after the leaderboard entry has been saved
+	globals.Leaderboard = scores.NewMemoryLeaderboard()
+	rec = submit()
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, 1, scoreCount(), "Expected the score to be saved once")
+	assert.Equal(t, 1, leaderboardRows())
+
+	_, err = globals.Accounts.Create(accounts.User{Username: "ada", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	rec = submit()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, scoreCount(), "Expected the score to be saved once")
+	assert.Equal(t, 1, leaderboardRows(), "Expected the leaderboard entry to be saved once")
+	results, err := globals.Accounts.Results("ada")
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	_, ok = globals.Sessions.Get(session.ID)
+	assert.False(t, ok, "Expected the session to be deleted once saved")
+}
+
+// TestGetLeaderboard tests the GetLeaderboard handler function
+func TestGetLeaderboard(t *testing.T) {
+	e := echo.New()
+	e.GET("/leaderboard", GetLeaderboard)
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science": {{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0}},
+		"music":   {{ID: 2, Category: "music", Question: "Who wrote Imagine?", Answers: []string{"John Lennon", "Paul McCartney"}, CorrectAnswerIndex: 0}},
+	}, nil)
+
+	now := time.Now().UTC()
+	globals.Leaderboard = scores.NewMemoryLeaderboard()
+	entries := []scores.Entry{
+		{Player: "ada", Category: "science", Score: 100, ScoreString: "5/5", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+		{Player: "bob", Category: "science", Score: 80, ScoreString: "4/5", CreatedAt: now.Add(-time.Hour)},
+		{Player: "cy", Category: "science", Score: 80, ScoreString: "4/5", CreatedAt: now},
+		{Player: "dee", Category: "music", Score: 60, ScoreString: "3/5", CreatedAt: now},
+		{Player: "eve", Category: "music+science", Score: 40, ScoreString: "2/5", CreatedAt: now},
+	}
+	for _, entry := range entries {
+		assert.NoError(t, globals.Leaderboard.Record(entry))
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+		expectedMessage    string
+		expectedCategory   string
+		expectedPeriod     string
+		expectedPlayers    []string
+		expectedRanks      []int
+	}{
+		{
+			name:               "success_every_category",
+			query:              "",
+			expectedStatusCode: http.StatusOK,
+			expectedPeriod:     "all",
+			expectedPlayers:    []string{"ada", "bob", "cy", "dee", "eve"},
+			expectedRanks:      []int{1, 2, 2, 4, 5},
+		},
+		{
+			name:               "success_category_for_the_week",
+			query:              "?category=Science&period=week",
+			expectedStatusCode: http.StatusOK,
+			expectedCategory:   "science",
+			expectedPeriod:     "week",
+			expectedPlayers:    []string{"bob", "cy"},
+			expectedRanks:      []int{1, 1},
+		},
+		{
+			name:               "success_limit_includes_ties",
+			query:              "?category=science&limit=2",
+			expectedStatusCode: http.StatusOK,
+			expectedCategory:   "science",
+			expectedPeriod:     "all",
+			expectedPlayers:    []string{"ada", "bob", "cy"},
+			expectedRanks:      []int{1, 2, 2},
+		},
+		{
+			name:               "success_combined_categories",
+			query:              "?category=science,music",
+			expectedStatusCode: http.StatusOK,
+			expectedCategory:   "music+science",
+			expectedPeriod:     "all",
+			expectedPlayers:    []string{"eve"},
+			expectedRanks:      []int{1},
+		},
+		{
+			name:               "failure_due_to_invalid_category",
+			query:              "?category=history",
+			expectedStatusCode: http.StatusNotFound,
+			expectedMessage:    "history is not a valid category.",
+		},
+		{
+			name:               "failure_due_to_invalid_period",
+			query:              "?period=year",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMessage:    "year is not a valid period. Please choose day, week, month, all.",
+		},
+		{
+			name:               "failure_due_to_invalid_limit",
+			query:              "?limit=0",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedMessage:    "0 is not a valid limit. Please choose a number between 1 and 100.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/leaderboard"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var leaderboardResponse struct {
+				Success bool   `json:"success"`
+				Message string `json:"message"`
+				Data    struct {
+					Category string           `json:"category"`
+					Period   string           `json:"period"`
+					Rankings []scores.Ranking `json:"rankings"`
+				} `json:"data"`
+			}
+			if !assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &leaderboardResponse)) {
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			if tt.expectedMessage != "" {
+				assert.False(t, leaderboardResponse.Success)
+				assert.Equal(t, tt.expectedMessage, leaderboardResponse.Message)
+				return
+			}
+
+			players := []string{}
+			ranks := []int{}
+			for _, ranking := range leaderboardResponse.Data.Rankings {
+				players = append(players, ranking.Player)
+				ranks = append(ranks, ranking.Rank)
+			}
+			assert.Equal(t, tt.expectedCategory, leaderboardResponse.Data.Category)
+			assert.Equal(t, tt.expectedPeriod, leaderboardResponse.Data.Period)
+			assert.Equal(t, tt.expectedPlayers, players)
+			assert.Equal(t, tt.expectedRanks, ranks)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo"
+)
+
+// APIVersion is the path prefix under which the current version of the API is served
+const APIVersion = "/v1"
+
+// DeprecationHeader marks responses to requests for deprecated paths
+const DeprecationHeader = "Deprecation"
+
+// OpenAPIPath is the path of the OpenAPI document within the current version of the API
+const OpenAPIPath = "/openapi.json"
+
+// openAPISpec is the OpenAPI document describing every endpoint of the current version of the API
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// GetOpenAPI returns the OpenAPI document describing the current version of the API
+func GetOpenAPI(c echo.Context) error {
+	return c.JSONBlob(http.StatusOK, openAPISpec)
+}
+
+// LegacyPaths returns middleware which serves the unversioned paths used before the API was versioned from the current
+// version. It must run before routing. Only paths which match a route of the current version are rewritten, and their
+// responses are marked as deprecated and link to the path which replaces them. Other paths are left for the router to
+// reject.
+func LegacyPaths(e *echo.Echo) echo.MiddlewareFunc {
+	var once sync.Once
+	var routes map[string]bool
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Routes are registered after the middleware, so they are collected when the first request arrives
+			once.Do(func() { routes = legacyRoutes(e) })
+
+			req := c.Request()
+			if req.URL.Path == APIVersion || strings.HasPrefix(req.URL.Path, APIVersion+"/") {
+				return next(c)
+			}
+
+			// The router matches the escaped path when there is one
+			path := req.URL.RawPath
+			if path == "" {
+				path = req.URL.Path
+			}
+			lookup := e.NewContext(nil, nil)
+			e.Router().Find(req.Method, APIVersion+path, lookup)
+			if !routes[lookup.Path()] {
+				return next(c)
+			}
+
+			req.URL.Path = APIVersion + req.URL.Path
+			if req.URL.RawPath != "" {
+				req.URL.RawPath = APIVersion + req.URL.RawPath
+			}
+
+			c.Response().Header().Set(DeprecationHeader, "true")
+			c.Response().Header().Set("Link", "<"+req.URL.EscapedPath()+`>; rel="successor-version"`)
+			return next(c)
+		}
+	}
+}
+
+// legacyRoutes returns the paths of the routes of the current version which were served before the API was
+// versioned. The OpenAPI document was added with the current version, and the catch-all routes which groups register
+// for their middleware are not endpoints.
+func legacyRoutes(e *echo.Echo) map[string]bool {
+	routes := make(map[string]bool)
+	for _, route := range e.Routes() {
+		if strings.HasPrefix(route.Path, APIVersion+"/") && !strings.HasSuffix(route.Path, "/*") && route.Path != APIVersion+OpenAPIPath {
+			routes[route.Path] = true
+		}
+	}
+	return routes
+}
@@ -0,0 +1,342 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"quizwizard/api/bank"
+	"quizwizard/api/models"
+)
+
+// Problem represents a single issue found within a questions file
+type Problem struct {
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Category   string `json:"category,omitempty"`
+	QuestionID int    `json:"questionId,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Report represents the outcome of linting a questions file
+type Report struct {
+	File       string    `json:"file"`
+	Valid      bool      `json:"valid"`
+	Categories int       `json:"categories"`
+	Questions  int       `json:"questions"`
+	Problems   []Problem `json:"problems"`
+}
+
+// knownFields lists the fields which a question may contain
+var knownFields = map[string]bool{
+	"id":                   true,
+	"category":             true,
+	"type":                 true,
+	"question":             true,
+	"prompts":              true,
+	"answers":              true,
+	"correctAnswerIndex":   true,
+	"correctAnswerIndexes": true,
+	"acceptedAnswers":      true,
+	"correctValue":         true,
+	"absoluteTolerance":    true,
+	"relativeTolerance":    true,
+	"unit":                 true,
+	"difficulty":           true,
+	"timeLimit":            true,
+	"points":               true,
+}
+
+// LintFile reads and lints a questions file. An error is only returned if the file cannot be read.
+func LintFile(filename string) (Report, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	report := Lint(data)
+	report.File = filename
+	return report, nil
+}
+
+// Lint checks the contents of a questions file and reports every problem found with its position
+func Lint(data []byte) Report {
+	l := &linter{
+		data:     data,
+		dec:      json.NewDecoder(bytes.NewReader(data)),
+		seenIDs:  make(map[int]int),
+		problems: []Problem{},
+	}
+	l.lintFile()
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].Line != l.problems[j].Line {
+			return l.problems[i].Line < l.problems[j].Line
+		}
+		return l.problems[i].Column < l.problems[j].Column
+	})
+
+	return Report{
+		Valid:      len(l.problems) == 0,
+		Categories: l.categories,
+		Questions:  l.questions,
+		Problems:   l.problems,
+	}
+}
+
+// Err returns an error describing every problem in the report, or nil if the file is valid
+func (r Report) Err() error {
+	if r.Valid {
+		return nil
+	}
+
+	lines := make([]string, len(r.Problems))
+	for i, problem := range r.Problems {
+		lines[i] = r.File + ":" + problem.String()
+	}
+	summary := fmt.Sprintf("%d problems found", len(r.Problems))
+	if len(r.Problems) == 1 {
+		summary = "1 problem found"
+	}
+	return fmt.Errorf("%s:\n%s", summary, strings.Join(lines, "\n"))
+}
+
+// String formats the problem as line:column: message
+func (p Problem) String() string {
+	prefix := fmt.Sprintf("%d:%d: ", p.Line, p.Column)
+	if p.QuestionID != 0 {
+		prefix += fmt.Sprintf("question %d: ", p.QuestionID)
+	} else if p.Category != "" {
+		prefix += fmt.Sprintf("category '%s': ", p.Category)
+	}
+	return prefix + p.Message
+}
+
+// linter walks the JSON tokens of a questions file, recording where each category and question starts
+type linter struct {
+	data       []byte
+	dec        *json.Decoder
+	seenIDs    map[int]int
+	categories int
+	questions  int
+	problems   []Problem
+}
+
+// errStop is returned once the file is too malformed to continue
+var errStop = errors.New("stop linting")
+
+// lintFile expects an object mapping each category to an array of questions
+func (l *linter) lintFile() {
+	if err := l.expectDelim('{', "the file must contain an object mapping each category to its questions"); err != nil {
+		return
+	}
+
+	for l.dec.More() {
+		offset := l.nextOffset()
+		token, err := l.dec.Token()
+		if err != nil {
+			l.addSyntaxProblem(err)
+			return
+		}
+
+		category, _ := token.(string)
+		l.categories++
+		if err := bank.ValidateCategoryName(category); err != nil {
+			l.add(offset, category, 0, err.Error())
+		}
+
+		if err := l.lintCategory(category); err != nil {
+			return
+		}
+	}
+
+	if _, err := l.dec.Token(); err != nil {
+		l.addSyntaxProblem(err)
+	}
+}
+
+// lintCategory expects an array of questions for a category
+func (l *linter) lintCategory(category string) error {
+	if err := l.expectDelim('[', "category '"+category+"' must contain an array of questions"); err != nil {
+		return err
+	}
+
+	for l.dec.More() {
+		if err := l.lintQuestion(category); err != nil {
+			return err
+		}
+	}
+
+	if _, err := l.dec.Token(); err != nil {
+		l.addSyntaxProblem(err)
+		return errStop
+	}
+	return nil
+}
+
+// lintQuestion decodes a single question object and checks it
+func (l *linter) lintQuestion(category string) error {
+	start := l.nextOffset()
+	if err := l.expectDelim('{', "each question must be an object"); err != nil {
+		return err
+	}
+
+	fieldOffsets := make(map[string]int64)
+	for l.dec.More() {
+		offset := l.nextOffset()
+		token, err := l.dec.Token()
+		if err != nil {
+			l.addSyntaxProblem(err)
+			return errStop
+		}
+		field, _ := token.(string)
+		fieldOffsets[field] = offset
+
+		var value json.RawMessage
+		if err := l.dec.Decode(&value); err != nil {
+			l.addSyntaxProblem(err)
+			return errStop
+		}
+	}
+
+	if _, err := l.dec.Token(); err != nil {
+		l.addSyntaxProblem(err)
+		return errStop
+	}
+	end := l.dec.InputOffset()
+	l.questions++
+
+	position := func(field string) int64 {
+		if offset, ok := fieldOffsets[field]; ok {
+			return offset
+		}
+		return start
+	}
+
+	// Type errors do not stop the remaining fields being decoded, so the other checks still run
+	var question models.Question
+	typeErrors := make(map[string]bool)
+	if err := json.Unmarshal(l.data[start:end], &question); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			l.add(start, category, 0, err.Error())
+			return nil
+		}
+		typeErrors[typeErr.Field] = true
+		l.add(position(typeErr.Field), category, question.ID, fmt.Sprintf("%s must be %s", typeErr.Field, describeKind(typeErr.Type.Kind())))
+	}
+
+	for field, offset := range fieldOffsets {
+		if !knownFields[field] {
+			l.add(offset, category, question.ID, fmt.Sprintf("unknown field '%s'", field))
+		}
+	}
+
+	for _, problem := range question.Problems() {
+		if !typeErrors[problem.Field] {
+			l.add(position(problem.Field), category, question.ID, problem.Message)
+		}
+	}
+
+	if question.Category != "" && question.Category != category {
+		msg := fmt.Sprintf("category '%s' does not match the '%s' category it is listed under", question.Category, category)
+		l.add(position("category"), category, question.ID, msg)
+	}
+
+	if question.ID > 0 {
+		if firstLine, ok := l.seenIDs[question.ID]; ok {
+			msg := fmt.Sprintf("id %d is already used by the question at line %d", question.ID, firstLine)
+			l.add(position("id"), category, question.ID, msg)
+		} else {
+			line, _ := l.lineAndColumn(start)
+			l.seenIDs[question.ID] = line
+		}
+	}
+
+	return nil
+}
+
+// describeKind describes the JSON type which corresponds to a Go kind
+func describeKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// expectDelim reads the next token and records a problem if it is not the expected delimiter
+func (l *linter) expectDelim(expected json.Delim, msg string) error {
+	offset := l.nextOffset()
+	token, err := l.dec.Token()
+	if err != nil {
+		l.addSyntaxProblem(err)
+		return errStop
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		l.add(offset, "", 0, msg)
+		return errStop
+	}
+	return nil
+}
+
+// nextOffset returns the offset of the next token, skipping whitespace and separators
+func (l *linter) nextOffset() int64 {
+	offset := l.dec.InputOffset()
+	for offset < int64(len(l.data)) {
+		switch l.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// addSyntaxProblem records a problem for malformed JSON
+func (l *linter) addSyntaxProblem(err error) {
+	offset := l.dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	}
+	l.add(offset, "", 0, "invalid JSON: "+err.Error())
+}
+
+// add records a problem at the specified offset
+func (l *linter) add(offset int64, category string, questionID int, msg string) {
+	line, column := l.lineAndColumn(offset)
+	l.problems = append(l.problems, Problem{
+		Line:       line,
+		Column:     column,
+		Category:   category,
+		QuestionID: questionID,
+		Message:    msg,
+	})
+}
+
+// lineAndColumn converts a byte offset into a one-based line and column
+func (l *linter) lineAndColumn(offset int64) (int, int) {
+	if offset > int64(len(l.data)) {
+		offset = int64(len(l.data))
+	}
+
+	before := l.data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,212 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validQuestions = `{
+  "science": [
+    {"id": 1, "category": "science", "question": "What is the chemical symbol for water?", "answers": ["H2O", "O2"], "correctAnswerIndex": 0}
+  ],
+  "music": [
+    {"id": 2, "category": "music", "question": "Who is the lead vocalist of the band Queen?", "answers": ["Freddie Mercury", "John Lennon"], "correctAnswerIndex": 0}
+  ]
+}`
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected Report
+	}{
+		{
+			name: "success_valid_file",
+			data: validQuestions,
+			expected: Report{
+				Valid:      true,
+				Categories: 2,
+				Questions:  2,
+				Problems:   []Problem{},
+			},
+		},
+		{
+			name: "failure_due_to_out_of_range_correct_answer_index",
+			data: `{
+  "science": [
+    {"id": 1, "category": "science", "question": "What is the chemical symbol for water?", "answers": ["H2O", "O2"], "correctAnswerIndex": 2}
+  ]
+}`,
+			expected: Report{
+				Categories: 1,
+				Questions:  1,
+				Problems: []Problem{
+					{Line: 3, Column: 118, Category: "science", QuestionID: 1, Message: "correctAnswerIndex 2 is out of range"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_free_text_question_without_accepted_answers",
+			data: `{
+  "music": [
+    {"id": 1, "category": "music", "type": "free_text", "question": "Who wrote Imagine?"}
+  ]
+}`,
+			expected: Report{
+				Categories: 1,
+				Questions:  1,
+				Problems: []Problem{
+					{Line: 3, Column: 5, Category: "music", QuestionID: 1, Message: "at least one accepted answer must be provided"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_duplicate_ids",
+			data: `{
+  "science": [
+    {"id": 1, "category": "science", "question": "What is the chemical symbol for water?", "answers": ["H2O", "O2"], "correctAnswerIndex": 0}
+  ],
+  "music": [
+    {"id": 1, "category": "music", "question": "Who is the lead vocalist of the band Queen?", "answers": ["Freddie Mercury", "John Lennon"], "correctAnswerIndex": 0}
+  ]
+}`,
+			expected: Report{
+				Categories: 2,
+				Questions:  2,
+				Problems: []Problem{
+					{Line: 6, Column: 6, Category: "music", QuestionID: 1, Message: "id 1 is already used by the question at line 3"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_mismatched_category",
+			data: `{
+  "science": [
+    {"id": 1, "category": "music", "question": "What is the chemical symbol for water?", "answers": ["H2O", "O2"], "correctAnswerIndex": 0}
+  ]
+}`,
+			expected: Report{
+				Categories: 1,
+				Questions:  1,
+				Problems: []Problem{
+					{Line: 3, Column: 15, Category: "science", QuestionID: 1, Message: "category 'music' does not match the 'science' category it is listed under"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_duplicate_and_empty_answers",
+			data: `{
+  "science": [
+    {
+      "id": 1,
+      "category": "science",
+      "question": "",
+      "answers": ["H2O", " h2o", ""],
+      "correctAnswerIndex": 0
+    }
+  ]
+}`,
+			expected: Report{
+				Categories: 1,
+				Questions:  1,
+				Problems: []Problem{
+					{Line: 6, Column: 7, Category: "science", QuestionID: 1, Message: "question must not be empty"},
+					{Line: 7, Column: 7, Category: "science", QuestionID: 1, Message: "answer 2 duplicates answer 1"},
+					{Line: 7, Column: 7, Category: "science", QuestionID: 1, Message: "answer 3 must not be empty"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_unknown_field_and_wrong_type",
+			data: `{
+  "science": [
+    {
+      "id": 1,
+      "category": "science",
+      "question": "What is the chemical symbol for water?",
+      "answers": ["H2O", "O2"],
+      "correctAnswer": 0,
+      "correctAnswerIndex": "0"
+    }
+  ]
+}`,
+			expected: Report{
+				Categories: 1,
+				Questions:  1,
+				Problems: []Problem{
+					{Line: 8, Column: 7, Category: "science", QuestionID: 1, Message: "unknown field 'correctAnswer'"},
+					{Line: 9, Column: 7, Category: "science", QuestionID: 1, Message: "correctAnswerIndex must be a number"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_invalid_category_name",
+			data: `{
+  "Science": []
+}`,
+			expected: Report{
+				Categories: 1,
+				Problems: []Problem{
+					{Line: 2, Column: 3, Category: "Science", Message: "category name is invalid"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_invalid_json",
+			data: `{
+  "science": [
+    {"id": 1,}
+  ]
+}`,
+			expected: Report{
+				Categories: 1,
+				Problems: []Problem{
+					{Line: 3, Column: 14, Message: "invalid JSON: invalid character ',' looking for beginning of value"},
+				},
+			},
+		},
+		{
+			name: "failure_due_to_array_instead_of_object",
+			data: `[]`,
+			expected: Report{
+				Problems: []Problem{
+					{Line: 1, Column: 1, Message: "the file must contain an object mapping each category to its questions"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Lint([]byte(test.data))
+
+			assert.Equal(t, test.expected, report)
+		})
+	}
+}
+
+func TestLintFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "questions.json")
+	err := os.WriteFile(filename, []byte(`{"science": [{"id": 1, "category": "science", "question": "Why?", "answers": ["Yes"], "correctAnswerIndex": 0}]}`), 0o644)
+	assert.NoError(t, err)
+
+	report, err := LintFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, filename, report.File)
+	assert.False(t, report.Valid)
+	assert.EqualError(t, report.Err(), "1 problem found:\n"+filename+":1:67: question 1: at least two answers must be provided")
+
+	_, err = LintFile(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read file")
+}
+
+func TestLintRealQuestionsFile(t *testing.T) {
+	report, err := LintFile("../questions.json")
+	assert.NoError(t, err)
+	assert.True(t, report.Valid, report.Err())
+	assert.NoError(t, report.Err())
+}
@@ -1,63 +1,369 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"quizwizard/api/accounts"
+	"quizwizard/api/bank"
 	"quizwizard/api/globals"
 	"quizwizard/api/handlers"
+	"quizwizard/api/lint"
+	"quizwizard/api/models"
+	"quizwizard/api/reload"
+	"quizwizard/api/scores"
+	"quizwizard/api/scoring"
+	"quizwizard/api/storage"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 )
 
+// questionsFile is the questions file which is imported on first start and reloaded when it changes
+const questionsFile = "questions.json"
+
+// categoriesFile holds the optional display names and descriptions of categories, keyed by category
+const categoriesFile = "categories.json"
+
 func main() {
-	err := loadQuestions("questions.json")
+	dbPath := flag.String("db", "quizwizard.db", "Path to the SQLite database file")
+	adminKey := flag.String("admin-key", os.Getenv("QUIZWIZARD_ADMIN_KEY"), "API key required by the admin endpoints")
+	watchInterval := flag.Duration("watch-interval", 2*time.Second, "How often to check the questions file for changes (0 disables)")
+	minQuestions := flag.Int("min-questions", 1, "Fewest questions which may be requested for a quiz")
+	maxQuestions := flag.Int("max-questions", 20, "Most questions which may be requested for a quiz")
+	defaultQuestions := flag.Int("default-questions", 5, "Number of questions issued when no count is requested")
+	categoryQuestions := flag.String("category-questions", "", "Number of questions issued for specific categories when no count is requested, such as computing=10,music=3")
+	timeLimit := flag.Int("time-limit", 0, "Seconds allowed to answer each question (0 disables)")
+	categoryTimeLimits := flag.String("category-time-limits", "", "Seconds allowed to answer the questions of specific categories, such as computing=30,music=20")
+	scoringStrategy := flag.String("scoring", scoring.StrategyStandard, "Scoring strategy, combining standard or weighted with negative, speed and streak, such as weighted+negative")
+	categoryScoring := flag.String("category-scoring", "", "Scoring strategies for specific categories, such as computing=weighted+speed,music=negative")
+	tokenSecret := flag.String("token-secret", os.Getenv("QUIZWIZARD_TOKEN_SECRET"), "Secret used to sign access tokens (a random secret is generated if empty)")
+	tokenTTL := flag.Duration("token-ttl", accounts.DefaultTokenTTL, "How long access tokens remain valid")
+	flag.Parse()
+
+	quizLength, err := parseQuizLength(*minQuestions, *maxQuestions, *defaultQuestions, *categoryQuestions)
+	if err != nil {
+		log.Fatalf("Invalid quiz length: %v", err)
+	}
+	globals.QuizLength = quizLength
+
+	timeLimits, err := parseTimeLimits(*timeLimit, *categoryTimeLimits)
+	if err != nil {
+		log.Fatalf("Invalid time limits: %v", err)
+	}
+	globals.TimeLimits = timeLimits
+
+	strategies, err := parseScoring(*scoringStrategy, *categoryScoring)
+	if err != nil {
+		log.Fatalf("Invalid scoring: %v", err)
+	}
+	globals.Scoring = strategies
+
+	tokens, err := parseTokens(*tokenSecret, *tokenTTL)
+	if err != nil {
+		log.Fatalf("Invalid access tokens: %v", err)
+	}
+	globals.Tokens = tokens
+
+	db, err := storage.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	err = loadQuestions(db, questionsFile)
 	if err != nil {
 		log.Fatalf("Failed to load questions: %v", err)
 	}
 
-	initialiseScoresMap()
+	details, err := bank.ReadDetailsFile(categoriesFile)
+	if err != nil {
+		log.Fatalf("Failed to load category details: %v", err)
+	}
+	globals.Bank.SetDetails(details)
+
+	err = initialiseScores(db)
+	if err != nil {
+		log.Fatalf("Failed to load scores: %v", err)
+	}
+
+	err = initialiseAccounts(db)
+	if err != nil {
+		log.Fatalf("Failed to load accounts: %v", err)
+	}
+
+	err = watchQuestions(db, questionsFile, *watchInterval)
+	if err != nil {
+		log.Fatalf("Failed to watch questions: %v", err)
+	}
 
-	err = startServer(":1323")
+	err = startServer(":1323", *adminKey)
 	if err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
-func loadQuestions(filename string) error {
+// parseQuizLength builds the quiz length limits from the command line flags.
+// Category defaults are written as comma separated category=count pairs.
+func parseQuizLength(minCount int, maxCount int, defaultCount int, categoryCounts string) (models.QuizLength, error) {
+	categoryDefaults, err := parseCategoryValues(categoryCounts, "count")
+	if err != nil {
+		return models.QuizLength{}, err
+	}
+
+	quizLength := models.QuizLength{
+		Min:              minCount,
+		Max:              maxCount,
+		Default:          defaultCount,
+		CategoryDefaults: categoryDefaults,
+	}
+
+	return quizLength, quizLength.Validate()
+}
+
+// parseTimeLimits builds the question time limits from the command line flags.
+// Category limits are written as comma separated category=seconds pairs.
+func parseTimeLimits(defaultLimit int, categoryLimits string) (models.TimeLimits, error) {
+	categoryDefaults, err := parseCategoryValues(categoryLimits, "seconds")
+	if err != nil {
+		return models.TimeLimits{}, err
+	}
+
+	timeLimits := models.TimeLimits{
+		Default:          defaultLimit,
+		CategoryDefaults: categoryDefaults,
+	}
+
+	return timeLimits, timeLimits.Validate()
+}
+
+// parseScoring builds the scoring strategies from the command line flags.
+// Category strategies are written as comma separated category=strategy pairs.
+func parseScoring(defaultStrategy string, categoryStrategies string) (scoring.Strategies, error) {
+	categories, err := parseCategoryPairs(categoryStrategies, "strategy")
+	if err != nil {
+		return scoring.Strategies{}, err
+	}
+
+	strategies := scoring.Strategies{
+		Default:    strings.ToLower(strings.TrimSpace(defaultStrategy)),
+		Categories: make(map[string]string, len(categories)),
+	}
+	for category, strategy := range categories {
+		strategies.Categories[category] = strings.ToLower(strategy)
+	}
+
+	return strategies, strategies.Validate()
+}
+
+// parseTokens builds the access token settings from the command line flags. Without a secret, a random one is
+// generated, so tokens stop working whenever the server restarts.
+func parseTokens(secret string, ttl time.Duration) (accounts.Tokens, error) {
+	if ttl <= 0 {
+		return accounts.Tokens{}, fmt.Errorf("token lifetime %v must be positive", ttl)
+	}
+
+	if len(secret) > 0 {
+		return accounts.Tokens{Secret: []byte(secret), TTL: ttl}, nil
+	}
+
+	log.Println("No token secret configured, access tokens will be invalidated when the server restarts")
+	generated, err := accounts.NewSecret()
+	if err != nil {
+		return accounts.Tokens{}, err
+	}
+	return accounts.Tokens{Secret: generated, TTL: ttl}, nil
+}
+
+// parseCategoryValues parses comma separated category=value pairs, where each value is a whole number
+func parseCategoryValues(pairs string, valueName string) (map[string]int, error) {
+	categories, err := parseCategoryPairs(pairs, valueName)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int, len(categories))
+	for category, value := range categories {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s=%s' must be written as category=%s", category, value, valueName)
+		}
+		values[category] = n
+	}
+
+	return values, nil
+}
+
+// parseCategoryPairs parses comma separated category=value pairs into a map of lowercase categories to trimmed values
+func parseCategoryPairs(pairs string, valueName string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for _, pair := range strings.Split(pairs, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		category, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("'%s' must be written as category=%s", pair, valueName)
+		}
+		values[strings.ToLower(strings.TrimSpace(category))] = strings.TrimSpace(value)
+	}
+
+	return values, nil
+}
+
+// loadQuestions loads the question bank from the database, importing the questions file on first start
+func loadQuestions(db *storage.DB, filename string) error {
 	log.Println("Preparing to load config...")
 
-	data, err := os.ReadFile(filename)
+	imported, err := db.QuestionsImported()
 	if err != nil {
-		return fmt.Errorf("failed to read file %s: %w", filename, err)
+		return err
+	}
+
+	if !imported {
+		log.Printf("Importing questions from %s...", filename)
+
+		report, err := lint.LintFile(filename)
+		if err != nil {
+			return err
+		}
+		if err := report.Err(); err != nil {
+			return fmt.Errorf("questions within file %s are invalid: %w", filename, err)
+		}
+
+		questions, err := bank.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+
+		if err := db.ImportQuestions(questions, filename); err != nil {
+			return fmt.Errorf("failed to import questions from %s: %w", filename, err)
+		}
 	}
 
-	if err := json.Unmarshal(data, &globals.Questions); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON within file %s: %w", filename, err)
+	questions, err := db.LoadQuestions()
+	if err != nil {
+		return err
 	}
+	globals.Bank = bank.New(questions, db)
 
 	log.Println("Config loaded successfully")
 	return nil
 }
 
-func initialiseScoresMap() {
-	globals.CategoryScores["random"] = []float64{}
-	for category := range globals.Questions {
-		globals.CategoryScores[category] = []float64{}
+// initialiseScores loads the stored scores and ensures each category has a score bucket
+func initialiseScores(db *storage.DB) error {
+	store, err := storage.NewScoreStore(db, scores.NewMemoryStore())
+	if err != nil {
+		return err
+	}
+
+	store.AddCategory("random")
+	for category := range globals.Bank.Questions() {
+		for _, level := range models.CategoryLevels(category) {
+			store.AddCategory(level)
+		}
+	}
+	globals.Scores = store
+
+	leaderboard, err := storage.NewLeaderboard(db, scores.NewMemoryLeaderboard())
+	if err != nil {
+		return err
 	}
+	globals.Leaderboard = leaderboard
+
+	return nil
+}
+
+// initialiseAccounts loads the registered users and their results
+func initialiseAccounts(db *storage.DB) error {
+	store, err := storage.NewAccountStore(db, accounts.NewMemoryStore())
+	if err != nil {
+		return err
+	}
+	globals.Accounts = store
+
+	return nil
 }
 
-func startServer(port string) error {
+// watchQuestions loads any changes made to the questions file while the API was stopped, and then reloads the question
+// bank from the file on SIGHUP and whenever it changes
+func watchQuestions(db *storage.DB, filename string, interval time.Duration) error {
+	reloader, err := reload.New(filename, globals.Bank, globals.Scores, db)
+	if err != nil {
+		return err
+	}
+	reloader.Sync()
+
+	ctx := context.Background()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go reloader.HandleSignals(ctx, signals)
+
+	if interval > 0 {
+		go reloader.Watch(ctx, interval)
+	}
+
+	return nil
+}
+
+func startServer(port string, adminKey string) error {
 	log.Println("Preparing to start server...")
 
+	e := newServer(adminKey)
+	return e.Start(port)
+}
+
+// newServer creates the server and registers every endpoint under the current API version. The unversioned paths
+// remain available as deprecated aliases.
+func newServer(adminKey string) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = handlers.HTTPErrorHandler
+	e.Pre(handlers.LegacyPaths(e))
 	e.Use(middleware.Logger())
-	e.GET("/categories", handlers.GetCategories)
-	e.GET("/questions", handlers.GetQuestions)
-	e.POST("/submit", handlers.SubmitAnswers)
+	e.Use(middleware.Recover())
 
-	return e.Start(port)
+	v1 := e.Group(handlers.APIVersion)
+	v1.GET(handlers.OpenAPIPath, handlers.GetOpenAPI)
+	v1.GET("/categories", handlers.GetCategories)
+	v1.GET("/categories/:name/stats", handlers.GetCategoryStats)
+	v1.GET("/leaderboard", handlers.GetLeaderboard)
+	v1.POST("/register", handlers.Register)
+	v1.POST("/login", handlers.Login)
+
+	// Quizzes may be taken anonymously, but are attached to the user when an access token is sent
+	optionalAuth := handlers.Authenticate(globals.Tokens, false)
+	v1.GET("/questions", handlers.GetQuestions, optionalAuth)
+	v1.POST("/sessions/:id/answers", handlers.CheckAnswer, optionalAuth)
+	v1.POST("/submit", handlers.SubmitAnswers, optionalAuth)
+
+	me := v1.Group("/me", handlers.Authenticate(globals.Tokens, true))
+	me.GET("", handlers.GetAccount)
+	me.GET("/history", handlers.GetHistory)
+	me.GET("/stats", handlers.GetStats)
+
+	if len(adminKey) == 0 {
+		log.Println("No admin API key configured, the admin endpoints are disabled")
+	} else {
+		admin := v1.Group("/admin", handlers.AdminAuth(adminKey))
+		admin.POST("/questions", handlers.CreateQuestion)
+		admin.PUT("/questions/:id", handlers.UpdateQuestion)
+		admin.DELETE("/questions/:id", handlers.DeleteQuestion)
+		admin.POST("/categories", handlers.CreateCategory)
+		admin.PUT("/categories/:name", handlers.RenameCategory)
+		admin.DELETE("/categories/:name", handlers.DeleteCategory)
+	}
+
+	return e
 }
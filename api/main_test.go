@@ -0,0 +1,532 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/bank"
+	"quizwizard/api/globals"
+	"quizwizard/api/handlers"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+	"quizwizard/api/sessions"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testAdminKey is the admin API key of the test server
+const testAdminKey = "test admin key"
+
+// useTestServer is a helper function which replaces the globals used by the handlers with fresh ones containing
+// questions for science/physics and music, restoring the originals when the test ends
+func useTestServer(t *testing.T) *echo.Echo {
+	originalBank, originalScores, originalLeaderboard := globals.Bank, globals.Scores, globals.Leaderboard
+	originalSessions, originalAccounts, originalTokens := globals.Sessions, globals.Accounts, globals.Tokens
+	originalCost := accounts.PasswordCost
+	t.Cleanup(func() {
+		globals.Bank, globals.Scores, globals.Leaderboard = originalBank, originalScores, originalLeaderboard
+		globals.Sessions, globals.Accounts, globals.Tokens = originalSessions, originalAccounts, originalTokens
+		accounts.PasswordCost = originalCost
+	})
+
+	globals.Bank = bank.New(map[string]models.Questions{
+		"science/physics": {
+			{ID: 1, Category: "science/physics", Question: "What is the unit of force?", Answers: []string{"Newton", "Joule", "Watt"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science/physics", Question: "What is the unit of power?", Answers: []string{"Newton", "Joule", "Watt"}, CorrectAnswerIndex: 2},
+		},
+		"music": {
+			{ID: 3, Category: "music", Question: "Who wrote Imagine?", Answers: []string{"John Lennon", "Paul McCartney"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+
+	store := scores.NewMemoryStore()
+	for _, category := range []string{"random", "science", "science/physics", "music"} {
+		store.AddCategory(category)
+	}
+	globals.Scores = store
+	globals.Leaderboard = scores.NewMemoryLeaderboard()
+	globals.Sessions = sessions.NewStore(time.Hour)
+	globals.Accounts = accounts.NewMemoryStore()
+	globals.Tokens = accounts.Tokens{Secret: []byte("test secret"), TTL: time.Hour}
+	accounts.PasswordCost = bcrypt.MinCost
+
+	e := newServer(testAdminKey)
+	e.Logger.SetOutput(&strings.Builder{})
+	return e
+}
+
+// specChecker sends requests to the server and checks each request and response against the OpenAPI document,
+// recording which operations have been checked
+type specChecker struct {
+	t       *testing.T
+	server  *echo.Echo
+	spec    map[string]interface{}
+	checked map[string]bool
+}
+
+// send makes a request to the server and checks it against the operation documented for its path. Successful
+// requests must match the documented parameters and request body, and every response must match the documented
+// response for its status code. The decoded response payload is returned.
+func (s *specChecker) send(method string, target string, body string, header http.Header) (int, map[string]interface{}) {
+	s.t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	s.server.ServeHTTP(rec, req)
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		s.t.Fatalf("%s %s returned invalid JSON: %v", method, target, err)
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		s.t.Fatalf("invalid target %s: %v", target, err)
+	}
+	specPath, operation := s.operation(method, strings.TrimPrefix(u.EscapedPath(), handlers.APIVersion))
+	if operation == nil {
+		s.t.Errorf("%s %s is not documented", method, target)
+		return rec.Code, payload
+	}
+	s.checked[strings.ToUpper(method)+" "+specPath] = true
+
+	if rec.Code < http.StatusBadRequest {
+		for name := range u.Query() {
+			if !s.hasParameter(specPath, operation, name) {
+				s.t.Errorf("%s %s sent the undocumented parameter %s", method, target, name)
+			}
+		}
+
+		if body != "" {
+			schema := s.lookup(operation, "requestBody", "content", echo.MIMEApplicationJSON, "schema")
+			var value interface{}
+			if err := json.Unmarshal([]byte(body), &value); err != nil {
+				s.t.Fatalf("invalid request body %s: %v", body, err)
+			}
+			for _, problem := range checkSchema(s.spec, schema, value, "request") {
+				s.t.Errorf("%s %s: %s", method, target, problem)
+			}
+		}
+	}
+
+	response := s.lookup(operation, "responses", fmt.Sprint(rec.Code))
+	if response == nil {
+		s.t.Errorf("%s %s returned the undocumented status %d", method, target, rec.Code)
+		return rec.Code, payload
+	}
+	schema := s.lookup(response, "content", echo.MIMEApplicationJSON, "schema")
+	for _, problem := range checkSchema(s.spec, schema, payload, "response") {
+		s.t.Errorf("%s %s: %s", method, target, problem)
+	}
+
+	return rec.Code, payload
+}
+
+// operation finds the documented path matching a request path, along with the operation for the method
+func (s *specChecker) operation(method string, requestPath string) (string, map[string]interface{}) {
+	requested := strings.Split(requestPath, "/")
+	for specPath, item := range s.spec["paths"].(map[string]interface{}) {
+		documented := strings.Split(specPath, "/")
+		if len(documented) != len(requested) {
+			continue
+		}
+
+		matches := true
+		for i, segment := range documented {
+			if !strings.HasPrefix(segment, "{") && segment != requested[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			operation, _ := item.(map[string]interface{})[strings.ToLower(method)].(map[string]interface{})
+			return specPath, operation
+		}
+	}
+	return "", nil
+}
+
+// hasParameter reports whether the operation, or the path it belongs to, documents a query parameter
+func (s *specChecker) hasParameter(specPath string, operation map[string]interface{}, name string) bool {
+	item := s.lookup(s.spec, "paths", specPath)
+	for _, node := range []map[string]interface{}{item, operation} {
+		parameters, _ := node["parameters"].([]interface{})
+		for _, parameter := range parameters {
+			parameter := s.lookup(parameter.(map[string]interface{}))
+			if parameter["in"] == "query" && parameter["name"] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup follows a path of keys through the document, resolving references along the way, and returns nil if any
+// key is missing
+func (s *specChecker) lookup(node map[string]interface{}, keys ...string) map[string]interface{} {
+	node = resolve(s.spec, node)
+	for _, key := range keys {
+		next, ok := node[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = resolve(s.spec, next)
+	}
+	return node
+}
+
+// resolve follows a local reference such as #/components/schemas/Category to the node it refers to
+func resolve(spec map[string]interface{}, node map[string]interface{}) map[string]interface{} {
+	for node != nil {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+
+		node = spec
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			node, _ = node[key].(map[string]interface{})
+		}
+	}
+	return node
+}
+
+// checkSchema returns a problem for every way a decoded JSON value does not match a schema. Objects may only have the
+// properties which are documented, so undocumented fields are caught as well as missing ones.
+func checkSchema(spec map[string]interface{}, schema map[string]interface{}, value interface{}, at string) []string {
+	schema = resolve(spec, schema)
+	if schema == nil {
+		return []string{at + " has no schema"}
+	}
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return []string{at + " is null"}
+	}
+
+	var problems []string
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{at + " is not an object"}
+		}
+
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is missing", at, name))
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range object {
+			if documented, ok := properties[name].(map[string]interface{}); ok {
+				problems = append(problems, checkSchema(spec, documented, property, at+"."+name)...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				problems = append(problems, checkSchema(spec, additional, property, at+"."+name)...)
+			case bool:
+				if !additional {
+					problems = append(problems, at+"."+name+" is not documented")
+				}
+			default:
+				problems = append(problems, at+"."+name+" is not documented")
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{at + " is not an array"}
+		}
+		for i, item := range items {
+			problems = append(problems, checkSchema(spec, schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return []string{at + " is not a string"}
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, text) {
+			problems = append(problems, fmt.Sprintf("%s has the undocumented value %q", at, text))
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || math.Trunc(number) != number {
+			return []string{at + " is not an integer"}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{at + " is not a number"}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{at + " is not a boolean"}
+		}
+	}
+	return problems
+}
+
+// containsValue reports whether a list of decoded JSON values contains a string
+func containsValue(values []interface{}, text string) bool {
+	for _, value := range values {
+		if value == text {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchSpec retrieves the OpenAPI document served by the API
+func fetchSpec(t *testing.T, e *echo.Echo) map[string]interface{} {
+	req := httptest.NewRequest(http.MethodGet, handlers.APIVersion+"/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to fetch the OpenAPI document: status %d", rec.Code)
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("the OpenAPI document is invalid JSON: %v", err)
+	}
+	return spec
+}
+
+// documentedOperations lists the method and path of every operation in the OpenAPI document
+func documentedOperations(spec map[string]interface{}) []string {
+	methods := []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+	var operations []string
+	for specPath, item := range spec["paths"].(map[string]interface{}) {
+		for _, method := range methods {
+			if _, ok := item.(map[string]interface{})[method]; ok {
+				operations = append(operations, strings.ToUpper(method)+" "+specPath)
+			}
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// TestOpenAPIRoutes tests that every route of the API is documented and every documented operation has a route
+func TestOpenAPIRoutes(t *testing.T) {
+	e := useTestServer(t)
+	spec := fetchSpec(t, e)
+
+	var routes []string
+	for _, route := range e.Routes() {
+		// Groups also register catch-all routes so that their middleware runs for unknown paths
+		if !strings.HasPrefix(route.Name, "quizwizard/api/handlers.") {
+			continue
+		}
+
+		segments := strings.Split(strings.TrimPrefix(route.Path, handlers.APIVersion), "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+			}
+		}
+		routes = append(routes, route.Method+" "+strings.Join(segments, "/"))
+	}
+	sort.Strings(routes)
+
+	assert.Equal(t, documentedOperations(spec), routes)
+}
+
+// TestOpenAPIResponses tests that the requests and responses of every documented operation match the OpenAPI document
+func TestOpenAPIResponses(t *testing.T) {
+	e := useTestServer(t)
+	s := &specChecker{t: t, server: e, spec: fetchSpec(t, e), checked: map[string]bool{}}
+	v1 := handlers.APIVersion
+	admin := http.Header{handlers.AdminKeyHeader: {testAdminKey}}
+
+	s.send(http.MethodGet, v1+"/openapi.json", "", nil)
+	s.send(http.MethodGet, v1+"/categories", "", nil)
+
+	// Accounts
+	credentials := `{"username": "ada", "password": "correct horse"}`
+	status, _ := s.send(http.MethodPost, v1+"/register", credentials, nil)
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = s.send(http.MethodPost, v1+"/register", credentials, nil)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = s.send(http.MethodPost, v1+"/login", `{"username": "ada", "password": 1}`, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = s.send(http.MethodPost, v1+"/login", `{"username": "ada", "password": "wrong horse"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, payload := s.send(http.MethodPost, v1+"/login", credentials, nil)
+	if !assert.Equal(t, http.StatusOK, status) {
+		return
+	}
+	token := payload["data"].(map[string]interface{})["token"].(string)
+	user := http.Header{echo.HeaderAuthorization: {"Bearer " + token}}
+
+	// A quiz on a subcategory is compared at each level
+	status, payload = s.send(http.MethodGet, v1+"/questions?category=science%2Fphysics&count=2&difficulty=medium", "", user)
+	if !assert.Equal(t, http.StatusOK, status) {
+		return
+	}
+	quiz := payload["data"].(map[string]interface{})
+	sessionID := quiz["sessionId"].(string)
+	questions := quiz["questions"].([]interface{})
+
+	answers := make([]string, len(questions))
+	for i, question := range questions {
+		answers[i] = fmt.Sprintf(`{"questionId": %v, "answer": 0}`, question.(map[string]interface{})["id"])
+	}
+	status, _ = s.send(http.MethodPost, v1+"/sessions/"+sessionID+"/answers", answers[0], user)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodPost, v1+"/sessions/"+sessionID+"/answers", answers[0], user)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = s.send(http.MethodPost, v1+"/sessions/unknown/answers", answers[0], nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	submission := fmt.Sprintf(`{"sessionId": %q, "playerName": "Ada", "questionResponses": [%s]}`, sessionID, strings.Join(answers, ", "))
+	status, payload = s.send(http.MethodPost, v1+"/submit", submission, user)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, payload["data"], "levels")
+	status, _ = s.send(http.MethodPost, v1+"/submit", submission, user)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = s.send(http.MethodGet, v1+"/questions?category=random%2Cmusic", "", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = s.send(http.MethodGet, v1+"/categories/science%2Fphysics/stats?difficulty=medium", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodGet, v1+"/categories/history/stats", "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = s.send(http.MethodGet, v1+"/leaderboard?category=science%2Fphysics&period=week&limit=5", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodGet, v1+"/leaderboard?period=year", "", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// The logged in user's account
+	status, _ = s.send(http.MethodGet, v1+"/me", "", user)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodGet, v1+"/me", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = s.send(http.MethodGet, v1+"/me/history?page=1&limit=5", "", user)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodGet, v1+"/me/history?limit=500", "", user)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = s.send(http.MethodGet, v1+"/me/stats", "", user)
+	assert.Equal(t, http.StatusOK, status)
+
+	// Managing the question bank
+	question := `{"category": "music", "question": "Who wrote Yesterday?", "answers": ["John Lennon", "Paul McCartney"], "correctAnswerIndex": 1}`
+	status, _ = s.send(http.MethodPost, v1+"/admin/questions", question, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = s.send(http.MethodPost, v1+"/admin/questions", `{"category": "music", "question": "Who?", "answers": ["A", "B"], "correctAnswerIndex": 2}`, admin)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, payload = s.send(http.MethodPost, v1+"/admin/questions", question, admin)
+	if !assert.Equal(t, http.StatusCreated, status) {
+		return
+	}
+	id := fmt.Sprint(payload["data"].(map[string]interface{})["id"])
+	status, _ = s.send(http.MethodPut, v1+"/admin/questions/"+id, strings.Replace(question, "Yesterday", "Let It Be", 1), admin)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodDelete, v1+"/admin/questions/"+id, "", admin)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodDelete, v1+"/admin/questions/"+id, "", admin)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = s.send(http.MethodPost, v1+"/admin/categories", `{"name": "history"}`, admin)
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = s.send(http.MethodPost, v1+"/admin/categories", `{"name": "music"}`, admin)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = s.send(http.MethodPut, v1+"/admin/categories/history", `{"name": "art"}`, admin)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = s.send(http.MethodDelete, v1+"/admin/categories/music", "", admin)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = s.send(http.MethodDelete, v1+"/admin/categories/art", "", admin)
+	assert.Equal(t, http.StatusOK, status)
+
+	for _, operation := range documentedOperations(s.spec) {
+		assert.True(t, s.checked[operation], "%s was not checked against the OpenAPI document", operation)
+	}
+}
+
+// TestLegacyPaths tests that the unversioned paths are served as deprecated aliases of the current version
+func TestLegacyPaths(t *testing.T) {
+	e := useTestServer(t)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedLink       string
+	}{
+		{
+			name:               "success_versioned_path",
+			path:               "/v1/categories",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "success_legacy_path",
+			path:               "/categories",
+			expectedStatusCode: http.StatusOK,
+			expectedLink:       `</v1/categories>; rel="successor-version"`,
+		},
+		{
+			name:               "success_legacy_escaped_path",
+			path:               "/categories/science%2Fphysics/stats",
+			expectedStatusCode: http.StatusOK,
+			expectedLink:       `</v1/categories/science%2Fphysics/stats>; rel="successor-version"`,
+		},
+		{
+			name:               "success_legacy_path_requiring_login",
+			path:               "/me/history",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedLink:       `</v1/me/history>; rel="successor-version"`,
+		},
+		{
+			name:               "failure_due_to_unknown_legacy_path",
+			path:               "/unknown",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "failure_due_to_unknown_path_within_legacy_group",
+			path:               "/me/unknown",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "failure_due_to_unversioned_openapi_document",
+			path:               "/openapi.json",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.Equal(t, tt.expectedLink, rec.Header().Get("Link"))
+			if tt.expectedLink == "" {
+				assert.Empty(t, rec.Header().Get(handlers.DeprecationHeader))
+			} else {
+				assert.Equal(t, "true", rec.Header().Get(handlers.DeprecationHeader))
+			}
+		})
+	}
+}
@@ -1,33 +1,983 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
-// Question represents a quiz question
+// ErrInvalidQuestion is returned when a question is incomplete or inconsistent
+var ErrInvalidQuestion = errors.New("question is invalid")
+
+const (
+	// DifficultyEasy is the difficulty of the easiest questions
+	DifficultyEasy = "easy"
+	// DifficultyMedium is the difficulty of questions which do not specify one
+	DifficultyMedium = "medium"
+	// DifficultyHard is the difficulty of the hardest questions
+	DifficultyHard = "hard"
+)
+
+// Difficulties lists every difficulty from easiest to hardest
+var Difficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard}
+
+// IsDifficulty reports whether a difficulty is one of the known difficulties
+func IsDifficulty(difficulty string) bool {
+	for _, d := range Difficulties {
+		if d == difficulty {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryDetails describes a category to players. Both fields are optional.
+type CategoryDetails struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Category represents a quiz category along with the choices available when starting a quiz from it.
+// The slug identifies the category in requests, while the name is shown to players. The question count and
+// difficulties include every subcategory, as a quiz from the category draws from them all.
+type Category struct {
+	Slug             string   `json:"slug"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Parent           string   `json:"parent"`
+	QuestionCount    int      `json:"questionCount"`
+	Difficulties     []string `json:"difficulties"`
+	DefaultQuestions int      `json:"defaultQuestions"`
+}
+
+// CategorySeparator separates the levels of a subcategory's slug, such as "science/physics"
+const CategorySeparator = "/"
+
+// ParentCategory returns the slug of a category's parent, or an empty string for a top level category
+func ParentCategory(slug string) string {
+	i := strings.LastIndex(slug, CategorySeparator)
+	if i < 0 {
+		return ""
+	}
+	return slug[:i]
+}
+
+// CategoryLevels returns a category followed by each of its parents, up to its top level category
+func CategoryLevels(slug string) []string {
+	levels := []string{slug}
+	for parent := ParentCategory(slug); parent != ""; parent = ParentCategory(parent) {
+		levels = append(levels, parent)
+	}
+	return levels
+}
+
+// IsWithin reports whether a category is the same as, or a subcategory of, another category
+func IsWithin(slug string, ancestor string) bool {
+	return slug == ancestor || strings.HasPrefix(slug, ancestor+CategorySeparator)
+}
+
+// DisplayName returns a readable name for the last level of a category slug by capitalising each word, treating
+// hyphens and underscores as spaces, so "trivia/general-knowledge" becomes "General Knowledge"
+func DisplayName(slug string) string {
+	slug = slug[strings.LastIndex(slug, CategorySeparator)+1:]
+	words := strings.FieldsFunc(slug, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '_'
+	})
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// DifficultiesOf returns the difficulties of the questions, from easiest to hardest, treating questions without
+// one as medium
+func DifficultiesOf(questions Questions) []string {
+	found := make(map[string]bool)
+	for _, question := range questions {
+		found[question.Level()] = true
+	}
+
+	difficulties := []string{}
+	for _, difficulty := range Difficulties {
+		if found[difficulty] {
+			difficulties = append(difficulties, difficulty)
+		}
+	}
+	return difficulties
+}
+
+const (
+	// TypeSingleChoice is the type of questions with one correct answer, used when a question does not specify a type
+	TypeSingleChoice = "single_choice"
+	// TypeTrueFalse is the type of questions with exactly two answers, one of which is correct
+	TypeTrueFalse = "true_false"
+	// TypeMultiSelect is the type of questions with one or more correct answers, which earn partial credit
+	TypeMultiSelect = "multi_select"
+	// TypeFreeText is the type of questions which are answered by typing text rather than choosing an answer
+	TypeFreeText = "free_text"
+	// TypeNumeric is the type of questions which are answered with a number, which earns partial credit when it is close
+	TypeNumeric = "numeric"
+	// TypeOrdering is the type of questions which are answered by putting the answers in order, earning credit for each one in place
+	TypeOrdering = "ordering"
+	// TypeMatching is the type of questions which are answered by matching each prompt to an answer, earning credit for each match
+	TypeMatching = "matching"
+)
+
+// QuestionTypes lists every question type
+var QuestionTypes = []string{TypeSingleChoice, TypeTrueFalse, TypeMultiSelect, TypeFreeText, TypeNumeric, TypeOrdering, TypeMatching}
+
+// IsQuestionType reports whether a question type is one of the known question types
+func IsQuestionType(questionType string) bool {
+	for _, t := range QuestionTypes {
+		if t == questionType {
+			return true
+		}
+	}
+	return false
+}
+
+// Question represents a quiz question. Multi-select questions list their correct answers in CorrectAnswerIndexes,
+// and free-text questions have no answers to choose from but list every accepted answer in AcceptedAnswers.
+// Numeric questions have no answers to choose from either; answers within the absolute or relative tolerance
+// of CorrectValue, whichever is larger, are correct. RelativeTolerance is a fraction, so 0.05 allows 5%.
+// Ordering questions list the correct order of their answers in CorrectAnswerIndexes, and matching questions list
+// the answer which matches each of their Prompts. Either may omit CorrectAnswerIndexes if the answers are listed in that order.
 type Question struct {
-	ID                 int      `json:"id"`
-	Category           string   `json:"category"`
-	Question           string   `json:"question"`
-	Answers            []string `json:"answers"`
-	CorrectAnswerIndex int      `json:"correctAnswerIndex"`
+	ID                   int      `json:"id"`
+	Category             string   `json:"category"`
+	Type                 string   `json:"type,omitempty"`
+	Question             string   `json:"question"`
+	Prompts              []string `json:"prompts,omitempty"`
+	Answers              []string `json:"answers"`
+	CorrectAnswerIndex   int      `json:"correctAnswerIndex"`
+	CorrectAnswerIndexes []int    `json:"correctAnswerIndexes,omitempty"`
+	AcceptedAnswers      []string `json:"acceptedAnswers,omitempty"`
+	CorrectValue         *float64 `json:"correctValue,omitempty"`
+	AbsoluteTolerance    float64  `json:"absoluteTolerance,omitempty"`
+	RelativeTolerance    float64  `json:"relativeTolerance,omitempty"`
+	Unit                 string   `json:"unit,omitempty"`
+	Difficulty           string   `json:"difficulty,omitempty"`
+	TimeLimit            int      `json:"timeLimit,omitempty"`
+	Points               float64  `json:"points,omitempty"`
 }
 
 // Questions represents a group of questions
 type Questions []Question
 
-// QuestionResponse represents a response to a quiz question
+// PublicQuestion represents a quiz question as it is shown to quizzers, without the correct answer
+type PublicQuestion struct {
+	ID         int      `json:"id"`
+	Category   string   `json:"category"`
+	Type       string   `json:"type"`
+	Question   string   `json:"question"`
+	Prompts    []string `json:"prompts,omitempty"`
+	Answers    []string `json:"answers,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+	Difficulty string   `json:"difficulty"`
+	TimeLimit  int      `json:"timeLimit,omitempty"`
+	Points     float64  `json:"points,omitempty"`
+}
+
+// QuizLength holds the limits and defaults for the number of questions issued for a quiz
+type QuizLength struct {
+	Min              int            `json:"min"`
+	Max              int            `json:"max"`
+	Default          int            `json:"default"`
+	CategoryDefaults map[string]int `json:"categoryDefaults"`
+}
+
+// DefaultFor returns the number of questions issued for a category when no count is requested. A subcategory without
+// a default of its own uses the default of its nearest parent which has one.
+func (l QuizLength) DefaultFor(category string) int {
+	for _, level := range CategoryLevels(category) {
+		if count, ok := l.CategoryDefaults[level]; ok {
+			return count
+		}
+	}
+	return l.Default
+}
+
+// Allows reports whether a requested number of questions is within the limits
+func (l QuizLength) Allows(count int) bool {
+	return count >= l.Min && count <= l.Max
+}
+
+// Validate checks that the limits are positive and that every default is within them
+func (l QuizLength) Validate() error {
+	if l.Min < 1 || l.Max < l.Min {
+		return fmt.Errorf("the minimum of %d and maximum of %d questions must be positive and in order", l.Min, l.Max)
+	}
+
+	if !l.Allows(l.Default) {
+		return fmt.Errorf("the default of %d questions must be between %d and %d", l.Default, l.Min, l.Max)
+	}
+
+	for category, count := range l.CategoryDefaults {
+		if !l.Allows(count) {
+			return fmt.Errorf("the default of %d questions for category '%s' must be between %d and %d", count, category, l.Min, l.Max)
+		}
+	}
+
+	return nil
+}
+
+// AnswerGracePeriod allows for the time taken to send an answer to the API before a time limit is enforced
+const AnswerGracePeriod = 2 * time.Second
+
+// TimeLimits holds the number of seconds allowed to answer each question. A question's own time limit takes precedence
+// over the limit for its category or nearest parent category with one, which takes precedence over the default.
+// Questions with a limit of 0 are untimed.
+type TimeLimits struct {
+	Default          int            `json:"default"`
+	CategoryDefaults map[string]int `json:"categoryDefaults"`
+}
+
+// For returns the number of seconds allowed to answer a question, or 0 if it is untimed
+func (l TimeLimits) For(question Question) int {
+	if question.TimeLimit > 0 {
+		return question.TimeLimit
+	}
+	for _, level := range CategoryLevels(question.Category) {
+		if limit, ok := l.CategoryDefaults[level]; ok {
+			return limit
+		}
+	}
+	return l.Default
+}
+
+// Validate checks that none of the time limits are negative
+func (l TimeLimits) Validate() error {
+	if l.Default < 0 {
+		return fmt.Errorf("the default time limit of %d seconds must not be negative", l.Default)
+	}
+
+	for category, limit := range l.CategoryDefaults {
+		if limit < 0 {
+			return fmt.Errorf("the time limit of %d seconds for category '%s' must not be negative", limit, category)
+		}
+	}
+
+	return nil
+}
+
+// CategoryRequest represents a request to create or rename a category
+type CategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// Credentials represents the username and password sent to register or log in
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AccessToken represents a signed access token issued to a user who has logged in
+type AccessToken struct {
+	Token     string    `json:"token"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// QuizSession represents a quiz which has been issued to a quizzer and is awaiting submission.
+// AnswerOrders holds the order in which each question's answers were shown, as indexes into its original answers,
+// and locked in Answers refer to the answers as they were shown. TimeLimits holds the seconds allowed for each timed question
+// and AnswerTimes and AnsweredAt hold how long each checked answer took and when it was checked. Username is empty for
+// quizzes started without logging in.
+// Saved holds the parts of the submission which have been saved, in case saving the rest fails and it is retried.
+type QuizSession struct {
+	ID             string                   `json:"id"`
+	Username       string                   `json:"username,omitempty"`
+	Category       string                   `json:"category"`
+	Difficulty     string                   `json:"difficulty,omitempty"`
+	QuestionIDs    []int                    `json:"questionIds"`
+	AnswerOrders   map[int][]int            `json:"answerOrders"`
+	Answers        map[int]QuestionResponse `json:"answers"`
+	TimeLimits     map[int]int              `json:"timeLimits,omitempty"`
+	AnswerTimes    map[int]time.Duration    `json:"answerTimes"`
+	AnsweredAt     map[int]time.Time        `json:"answeredAt"`
+	CreatedAt      time.Time                `json:"createdAt"`
+	LastAnsweredAt time.Time                `json:"lastAnsweredAt"`
+	Saved          map[string]bool          `json:"saved,omitempty"`
+}
+
+// Quiz represents the questions issued for a quiz session. The category of a quiz drawn from
+// several categories combines their names, such as "computing+music".
+type Quiz struct {
+	SessionID  string           `json:"sessionId"`
+	Category   string           `json:"category"`
+	Categories []string         `json:"categories"`
+	Difficulty string           `json:"difficulty,omitempty"`
+	Questions  []PublicQuestion `json:"questions"`
+}
+
+// QuestionResponse represents a response to a quiz question. Answer is the index of the chosen answer for
+// single choice and true/false questions. Answers holds the chosen indexes for multi-select questions, every
+// index in the chosen order for ordering questions, and the index matched to each prompt for matching questions.
+// Text holds the typed answer for free-text questions and Value holds the number given for numeric questions.
 type QuestionResponse struct {
-	Question *Question `json:"question"`
-	Answer   int       `json:"answer"`
+	QuestionID int      `json:"questionId"`
+	Answer     int      `json:"answer"`
+	Answers    []int    `json:"answers,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Value      *float64 `json:"value,omitempty"`
+}
+
+// HasQuestion reports whether a question was issued for the session
+func (s QuizSession) HasQuestion(questionID int) bool {
+	for _, id := range s.QuestionIDs {
+		if id == questionID {
+			return true
+		}
+	}
+	return false
+}
+
+// BlankResponse returns a response which earns no credit for any type of question, used when time runs out
+func BlankResponse(questionID int) QuestionResponse {
+	return QuestionResponse{QuestionID: questionID, Answer: -1}
+}
+
+// Deadline returns when the answer to a timed question is due and reports whether the question is timed.
+// Questions are answered one after another, so the time allowed runs from the most recently checked answer,
+// or from when the session was created if no answers have been checked.
+func (s QuizSession) Deadline(questionID int) (time.Time, bool) {
+	limit, ok := s.TimeLimits[questionID]
+	if !ok || limit <= 0 {
+		return time.Time{}, false
+	}
+
+	return s.startedAt().Add(time.Duration(limit) * time.Second), true
+}
+
+// SubmissionDeadlines returns when the answer to each timed question which has not been checked is due, keyed by
+// question ID. Questions are shown in the order they were issued, each once the question before it was checked or its
+// time limit ran out, so each question is due its own time limit after the latest it could have been shown. The time
+// spent on an unchecked question without a time limit is unknown, so the questions after it have no deadline until
+// the next checked answer.
+func (s QuizSession) SubmissionDeadlines() map[int]time.Time {
+	deadlines := make(map[int]time.Time)
+	shownAt, known := s.CreatedAt, true
+	for _, id := range s.QuestionIDs {
+		if _, answered := s.Answers[id]; answered {
+			shownAt, known = s.AnsweredAt[id], true
+			continue
+		}
+
+		limit := s.TimeLimits[id]
+		if limit <= 0 {
+			known = false
+			continue
+		}
+
+		if known {
+			shownAt = shownAt.Add(time.Duration(limit) * time.Second)
+			deadlines[id] = shownAt
+		}
+	}
+	return deadlines
+}
+
+// Elapsed returns how long has passed since the time allowed for the next answer started
+func (s QuizSession) Elapsed(now time.Time) time.Duration {
+	return now.Sub(s.startedAt())
 }
 
-// QuizResponse represents a list of question responses
+// startedAt returns when the time allowed for the next answer started
+func (s QuizSession) startedAt() time.Time {
+	if s.LastAnsweredAt.After(s.CreatedAt) {
+		return s.LastAnsweredAt
+	}
+	return s.CreatedAt
+}
+
+// AnswerCheck represents the outcome of checking a single answer during a quiz session.
+// Credit is between 0 and 1, and TimedOut reports that the answer arrived after the question's time limit.
+type AnswerCheck struct {
+	QuestionID           int     `json:"questionId"`
+	Correct              bool    `json:"correct"`
+	TimedOut             bool    `json:"timedOut,omitempty"`
+	Credit               float64 `json:"credit"`
+	CorrectAnswerIndex   int     `json:"correctAnswerIndex"`
+	CorrectAnswerIndexes []int   `json:"correctAnswerIndexes,omitempty"`
+	CorrectAnswer        string  `json:"correctAnswer"`
+}
+
+// QuizResponse represents a list of question responses for a quiz session.
+// Submissions with a player name are ranked on the leaderboard.
 type QuizResponse struct {
-	Category          string             `json:"category"`
+	SessionID         string             `json:"sessionId"`
+	PlayerName        string             `json:"playerName,omitempty"`
 	QuestionResponses []QuestionResponse `json:"questionResponses"`
 }
 
+// CategoryScore represents the score for the questions of a single category within a quiz submission.
+// Score is the points earned under the category's scoring strategy and Total is the points available.
+type CategoryScore struct {
+	Strategy        string  `json:"strategy"`
+	Score           float64 `json:"score"`
+	Total           float64 `json:"total"`
+	ScoreString     string  `json:"scoreString"`
+	ScorePercentage float64 `json:"scorePercentage"`
+}
+
+// LevelComparison represents the percentage of quizzers a score was better than at one level of the category tree
+type LevelComparison struct {
+	Category   string  `json:"category"`
+	Percentile float64 `json:"percentile"`
+}
+
+// QuestionProblem describes a single problem with a question and the field it relates to
+type QuestionProblem struct {
+	Field   string
+	Message string
+}
+
+// Problems returns every problem with the question, in the order its fields are usually written
+func (q Question) Problems() []QuestionProblem {
+	var problems []QuestionProblem
+
+	if q.ID <= 0 {
+		problems = append(problems, QuestionProblem{"id", "id must be a positive number"})
+	}
+
+	if strings.TrimSpace(q.Category) == "" {
+		problems = append(problems, QuestionProblem{"category", "category must not be empty"})
+	}
+
+	if strings.TrimSpace(q.Question) == "" {
+		problems = append(problems, QuestionProblem{"question", "question must not be empty"})
+	}
+
+	if q.Type != "" && !IsQuestionType(q.Type) {
+		msg := fmt.Sprintf("type '%s' must be one of %s", q.Type, strings.Join(QuestionTypes, ", "))
+		problems = append(problems, QuestionProblem{"type", msg})
+	}
+
+	switch q.Kind() {
+	case TypeFreeText, TypeNumeric:
+		if len(q.Answers) > 0 {
+			problems = append(problems, QuestionProblem{"answers", fmt.Sprintf("answers must not be provided for %s questions", q.Kind())})
+		}
+	case TypeTrueFalse:
+		if len(q.Answers) != 2 {
+			problems = append(problems, QuestionProblem{"answers", "exactly two answers must be provided for true_false questions"})
+		}
+	case TypeMatching:
+		problems = append(problems, q.promptsProblems()...)
+	default:
+		if len(q.Answers) < 2 {
+			problems = append(problems, QuestionProblem{"answers", "at least two answers must be provided"})
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, answer := range q.Answers {
+		normalised := strings.ToLower(strings.TrimSpace(answer))
+		if normalised == "" {
+			problems = append(problems, QuestionProblem{"answers", fmt.Sprintf("answer %d must not be empty", i+1)})
+			continue
+		}
+
+		if first, ok := seen[normalised]; ok {
+			problems = append(problems, QuestionProblem{"answers", fmt.Sprintf("answer %d duplicates answer %d", i+1, first+1)})
+		} else {
+			seen[normalised] = i
+		}
+	}
+
+	switch q.Kind() {
+	case TypeMultiSelect, TypeOrdering, TypeMatching:
+		problems = append(problems, q.correctAnswerIndexesProblems()...)
+	case TypeFreeText:
+		problems = append(problems, q.acceptedAnswersProblems()...)
+	case TypeNumeric:
+		problems = append(problems, q.correctValueProblems()...)
+	default:
+		if q.CorrectAnswerIndex < 0 || q.CorrectAnswerIndex >= len(q.Answers) {
+			problems = append(problems, QuestionProblem{"correctAnswerIndex", fmt.Sprintf("correctAnswerIndex %d is out of range", q.CorrectAnswerIndex)})
+		}
+	}
+
+	switch q.Kind() {
+	case TypeMultiSelect, TypeOrdering, TypeMatching:
+	default:
+		if len(q.CorrectAnswerIndexes) > 0 {
+			msg := "correctAnswerIndexes must only be provided for multi_select, ordering and matching questions"
+			problems = append(problems, QuestionProblem{"correctAnswerIndexes", msg})
+		}
+	}
+
+	if q.Kind() != TypeMatching && len(q.Prompts) > 0 {
+		problems = append(problems, QuestionProblem{"prompts", "prompts must only be provided for matching questions"})
+	}
+
+	if q.Kind() != TypeFreeText && len(q.AcceptedAnswers) > 0 {
+		problems = append(problems, QuestionProblem{"acceptedAnswers", "acceptedAnswers must only be provided for free_text questions"})
+	}
+
+	if q.Kind() != TypeNumeric {
+		if q.CorrectValue != nil {
+			problems = append(problems, QuestionProblem{"correctValue", "correctValue must only be provided for numeric questions"})
+		}
+		if q.AbsoluteTolerance != 0 || q.RelativeTolerance != 0 {
+			problems = append(problems, QuestionProblem{"absoluteTolerance", "tolerances must only be provided for numeric questions"})
+		}
+		if q.Unit != "" {
+			problems = append(problems, QuestionProblem{"unit", "unit must only be provided for numeric questions"})
+		}
+	}
+
+	if q.Difficulty != "" && !IsDifficulty(q.Difficulty) {
+		msg := fmt.Sprintf("difficulty '%s' must be one of %s", q.Difficulty, strings.Join(Difficulties, ", "))
+		problems = append(problems, QuestionProblem{"difficulty", msg})
+	}
+
+	if q.TimeLimit < 0 {
+		problems = append(problems, QuestionProblem{"timeLimit", "timeLimit must not be negative"})
+	}
+
+	if q.Points < 0 {
+		problems = append(problems, QuestionProblem{"points", "points must not be negative"})
+	}
+
+	return problems
+}
+
+// correctAnswerIndexesProblems checks that the correct answers of a multi-select, ordering or matching question are unique
+// and in range, and that ordering and matching questions list one for every answer or prompt respectively
+func (q Question) correctAnswerIndexesProblems() []QuestionProblem {
+	var problems []QuestionProblem
+
+	switch q.Kind() {
+	case TypeMultiSelect:
+		if len(q.CorrectAnswerIndexes) == 0 {
+			problems = append(problems, QuestionProblem{"correctAnswerIndexes", "at least one correct answer index must be provided"})
+		}
+	case TypeOrdering:
+		if q.CorrectAnswerIndexes != nil && len(q.CorrectAnswerIndexes) != len(q.Answers) {
+			problems = append(problems, QuestionProblem{"correctAnswerIndexes", "correctAnswerIndexes must list every answer exactly once"})
+		}
+	case TypeMatching:
+		if q.CorrectAnswerIndexes != nil && len(q.CorrectAnswerIndexes) != len(q.Prompts) {
+			problems = append(problems, QuestionProblem{"correctAnswerIndexes", "correctAnswerIndexes must list one answer for every prompt"})
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, index := range q.CorrectAnswerIndexes {
+		if index < 0 || index >= len(q.Answers) {
+			problems = append(problems, QuestionProblem{"correctAnswerIndexes", fmt.Sprintf("correctAnswerIndexes %d is out of range", index)})
+		} else if seen[index] {
+			problems = append(problems, QuestionProblem{"correctAnswerIndexes", fmt.Sprintf("correctAnswerIndexes %d is listed more than once", index)})
+		}
+		seen[index] = true
+	}
+
+	return problems
+}
+
+// promptsProblems checks that a matching question has at least two unique prompts and an answer for each of them
+func (q Question) promptsProblems() []QuestionProblem {
+	var problems []QuestionProblem
+
+	if len(q.Prompts) < 2 {
+		problems = append(problems, QuestionProblem{"prompts", "at least two prompts must be provided"})
+	}
+
+	seen := make(map[string]int)
+	for i, prompt := range q.Prompts {
+		normalised := strings.ToLower(strings.TrimSpace(prompt))
+		if normalised == "" {
+			problems = append(problems, QuestionProblem{"prompts", fmt.Sprintf("prompt %d must not be empty", i+1)})
+			continue
+		}
+
+		if first, ok := seen[normalised]; ok {
+			problems = append(problems, QuestionProblem{"prompts", fmt.Sprintf("prompt %d duplicates prompt %d", i+1, first+1)})
+		} else {
+			seen[normalised] = i
+		}
+	}
+
+	if len(q.Answers) < len(q.Prompts) {
+		problems = append(problems, QuestionProblem{"answers", "at least one answer must be provided for every prompt"})
+	}
+
+	return problems
+}
+
+// acceptedAnswersProblems checks that a free-text question has at least one accepted answer and that none are blank
+func (q Question) acceptedAnswersProblems() []QuestionProblem {
+	var problems []QuestionProblem
+
+	if len(q.AcceptedAnswers) == 0 {
+		problems = append(problems, QuestionProblem{"acceptedAnswers", "at least one accepted answer must be provided"})
+	}
+
+	for i, answer := range q.AcceptedAnswers {
+		if NormaliseText(answer) == "" {
+			problems = append(problems, QuestionProblem{"acceptedAnswers", fmt.Sprintf("accepted answer %d must not be empty", i+1)})
+		}
+	}
+
+	return problems
+}
+
+// correctValueProblems checks that a numeric question has a correct value and that its tolerances are not negative
+func (q Question) correctValueProblems() []QuestionProblem {
+	var problems []QuestionProblem
+
+	if q.CorrectValue == nil {
+		problems = append(problems, QuestionProblem{"correctValue", "correctValue must be provided"})
+	} else if math.IsNaN(*q.CorrectValue) || math.IsInf(*q.CorrectValue, 0) {
+		problems = append(problems, QuestionProblem{"correctValue", "correctValue must be a finite number"})
+	}
+
+	if q.AbsoluteTolerance < 0 {
+		problems = append(problems, QuestionProblem{"absoluteTolerance", "absoluteTolerance must not be negative"})
+	}
+
+	if q.RelativeTolerance < 0 {
+		problems = append(problems, QuestionProblem{"relativeTolerance", "relativeTolerance must not be negative"})
+	}
+
+	return problems
+}
+
+// Validate checks that the question is complete and returns its first problem
+func (q Question) Validate() error {
+	problems := q.Problems()
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidQuestion, problems[0].Message)
+	}
+	return nil
+}
+
+// Reordered returns a copy of the question with its answers in the specified order, where order[i] is the
+// original index of the answer shown at position i. The question is returned unchanged if the order does not fit its answers.
+func (q Question) Reordered(order []int) Question {
+	if len(order) != len(q.Answers) {
+		return q
+	}
+
+	answers := make([]string, len(order))
+	shown := make(map[int]int, len(order))
+	for i, original := range order {
+		if original < 0 || original >= len(q.Answers) {
+			return q
+		}
+
+		answers[i] = q.Answers[original]
+		shown[original] = i
+	}
+
+	q.Answers = answers
+	if i, ok := shown[q.CorrectAnswerIndex]; ok {
+		q.CorrectAnswerIndex = i
+	}
+	if indexes := q.correctIndexes(); indexes != nil {
+		correctAnswerIndexes := make([]int, len(indexes))
+		for i, original := range indexes {
+			correctAnswerIndexes[i] = shown[original]
+		}
+
+		// The correct answers to multi-select questions are a set, whereas ordering and matching questions depend on their order
+		if q.Kind() == TypeMultiSelect {
+			sort.Ints(correctAnswerIndexes)
+		}
+		q.CorrectAnswerIndexes = correctAnswerIndexes
+	}
+	return q
+}
+
+// correctIndexes returns the correct answer indexes, which default to the answers in the order they are listed
+// for ordering questions and to the answer listed at the same position as each prompt for matching questions
+func (q Question) correctIndexes() []int {
+	if q.CorrectAnswerIndexes != nil {
+		return q.CorrectAnswerIndexes
+	}
+
+	var count int
+	switch q.Kind() {
+	case TypeOrdering:
+		count = len(q.Answers)
+	case TypeMatching:
+		count = len(q.Prompts)
+	default:
+		return nil
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// Kind returns the type of the question, treating questions without one as single choice
+func (q Question) Kind() string {
+	if q.Type == "" {
+		return TypeSingleChoice
+	}
+	return q.Type
+}
+
+// Credit scores a response to the question between 0 and 1. Multi-select questions earn credit for each
+// correct answer chosen, less any incorrect answers chosen, and free-text answers are accepted if they
+// match any accepted answer once normalised. Numeric answers within the tolerance earn full credit, which
+// then falls away evenly to nothing at twice the tolerance. Ordering and matching questions earn credit for
+// each answer in its correct position.
+func (q Question) Credit(response QuestionResponse) float64 {
+	switch q.Kind() {
+	case TypeMultiSelect:
+		if len(q.CorrectAnswerIndexes) == 0 {
+			return 0
+		}
+
+		correct := make(map[int]bool, len(q.CorrectAnswerIndexes))
+		for _, index := range q.CorrectAnswerIndexes {
+			correct[index] = true
+		}
+
+		chosen := make(map[int]bool, len(response.Answers))
+		hits := 0
+		misses := 0
+		for _, index := range response.Answers {
+			if chosen[index] {
+				continue
+			}
+			chosen[index] = true
+
+			if correct[index] {
+				hits++
+			} else {
+				misses++
+			}
+		}
+
+		credit := float64(hits-misses) / float64(len(q.CorrectAnswerIndexes))
+		return math.Max(credit, 0)
+	case TypeFreeText:
+		text := NormaliseText(response.Text)
+		if text == "" {
+			return 0
+		}
+
+		for _, accepted := range q.AcceptedAnswers {
+			if NormaliseText(accepted) == text {
+				return 1
+			}
+		}
+		return 0
+	case TypeOrdering, TypeMatching:
+		correct := q.correctIndexes()
+		if len(correct) == 0 {
+			return 0
+		}
+
+		placed := 0
+		for i, index := range correct {
+			if i < len(response.Answers) && response.Answers[i] == index {
+				placed++
+			}
+		}
+		return float64(placed) / float64(len(correct))
+	case TypeNumeric:
+		if q.CorrectValue == nil || response.Value == nil {
+			return 0
+		}
+
+		difference := math.Abs(*response.Value - *q.CorrectValue)
+		tolerance := q.Tolerance()
+		if difference <= tolerance {
+			return 1
+		} else if difference >= 2*tolerance {
+			return 0
+		}
+		return 2 - difference/tolerance
+	default:
+		if response.Answer == q.CorrectAnswerIndex {
+			return 1
+		}
+		return 0
+	}
+}
+
+// Skipped reports whether a response leaves a question unanswered, such as a blank answer sent when time runs out
+func (q Question) Skipped(response QuestionResponse) bool {
+	switch q.Kind() {
+	case TypeMultiSelect, TypeOrdering:
+		return len(response.Answers) == 0
+	case TypeMatching:
+		for _, index := range response.Answers {
+			if index >= 0 {
+				return false
+			}
+		}
+		return true
+	case TypeFreeText:
+		return NormaliseText(response.Text) == ""
+	case TypeNumeric:
+		return response.Value == nil
+	default:
+		return response.Answer < 0 || response.Answer >= len(q.Answers)
+	}
+}
+
+// Weight returns the points available for the question under weighted scoring, which is 1 unless it sets its own points
+func (q Question) Weight() float64 {
+	if q.Points > 0 {
+		return q.Points
+	}
+	return 1
+}
+
+// CorrectAnswer describes the correct answer to the question. Multi-select answers are listed in the order they are shown,
+// ordering answers in their correct order and matching answers beside their prompts. The first accepted answer is used
+// for free-text questions and numeric answers are followed by their unit.
+func (q Question) CorrectAnswer() string {
+	switch q.Kind() {
+	case TypeMultiSelect, TypeOrdering, TypeMatching:
+		correct := q.correctIndexes()
+		answers := make([]string, 0, len(correct))
+		for i, index := range correct {
+			if index < 0 || index >= len(q.Answers) {
+				continue
+			}
+
+			if q.Kind() == TypeMatching && i < len(q.Prompts) {
+				answers = append(answers, q.Prompts[i]+": "+q.Answers[index])
+			} else {
+				answers = append(answers, q.Answers[index])
+			}
+		}
+		return strings.Join(answers, ", ")
+	case TypeFreeText:
+		if len(q.AcceptedAnswers) == 0 {
+			return ""
+		}
+		return q.AcceptedAnswers[0]
+	case TypeNumeric:
+		if q.CorrectValue == nil {
+			return ""
+		}
+		answer := strconv.FormatFloat(*q.CorrectValue, 'f', -1, 64)
+		if q.Unit != "" {
+			answer += " " + q.Unit
+		}
+		return answer
+	default:
+		if q.CorrectAnswerIndex < 0 || q.CorrectAnswerIndex >= len(q.Answers) {
+			return ""
+		}
+		return q.Answers[q.CorrectAnswerIndex]
+	}
+}
+
+// Tolerance returns how far a numeric answer may be from the correct value and still earn full credit
+func (q Question) Tolerance() float64 {
+	if q.CorrectValue == nil {
+		return q.AbsoluteTolerance
+	}
+	return math.Max(q.AbsoluteTolerance, q.RelativeTolerance*math.Abs(*q.CorrectValue))
+}
+
+// MaxPlayerNameLength is the most characters a player name may have
+const MaxPlayerNameLength = 32
+
+// NormalisePlayerName trims a player name and collapses its whitespace, and checks that it is short and printable.
+// An empty name is allowed for anonymous players.
+func NormalisePlayerName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			msg := "player name must only contain printable characters"
+			return "", errors.New(msg)
+		}
+	}
+
+	if len([]rune(name)) > MaxPlayerNameLength {
+		return "", fmt.Errorf("player name must be at most %d characters", MaxPlayerNameLength)
+	}
+
+	return name, nil
+}
+
+// NormaliseText prepares a free-text answer for comparison by lowercasing it, removing punctuation,
+// collapsing whitespace and dropping a leading article, so "The  Beatles!" matches "beatles"
+func NormaliseText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else if unicode.IsSpace(r) || r == '-' || r == '/' {
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// Level returns the difficulty of the question, treating questions without one as medium
+func (q Question) Level() string {
+	if q.Difficulty == "" {
+		return DifficultyMedium
+	}
+	return q.Difficulty
+}
+
+// Public returns the question without its correct answer
+func (q Question) Public() PublicQuestion {
+	return PublicQuestion{
+		ID:         q.ID,
+		Category:   q.Category,
+		Type:       q.Kind(),
+		Question:   q.Question,
+		Prompts:    q.Prompts,
+		Answers:    q.Answers,
+		Unit:       q.Unit,
+		Difficulty: q.Level(),
+		Points:     q.Points,
+	}
+}
+
+// Public returns the questions without their correct answers
+func (q Questions) Public() []PublicQuestion {
+	public := make([]PublicQuestion, len(q))
+	for i, question := range q {
+		public[i] = question.Public()
+	}
+	return public
+}
+
+// WithDifficulty returns the questions of the specified difficulty. An empty difficulty returns every question.
+func (q Questions) WithDifficulty(difficulty string) Questions {
+	if difficulty == "" {
+		return q
+	}
+
+	filtered := Questions{}
+	for _, question := range q {
+		if question.Level() == difficulty {
+			filtered = append(filtered, question)
+		}
+	}
+	return filtered
+}
+
 // ShuffledCopy returns a shuffled copy of the Questions slice. The original slice remains unchanged.
 func (q Questions) ShuffledCopy() Questions {
 	cpy := make(Questions, len(q))
@@ -1,7 +1,9 @@
 package models
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,6 +49,11 @@ func getTestQuestions() Questions {
 	}
 }
 
+// float64Pointer returns a pointer to a number, for the optional numeric fields of questions and responses
+func float64Pointer(value float64) *float64 {
+	return &value
+}
+
 // TestShuffledCopyLength checks that the shuffled copy is the same length as the original
 func TestShuffledCopyLength(t *testing.T) {
 	original := getTestQuestions()
@@ -81,3 +88,618 @@ func TestShuffledCopyDifferentOrder(t *testing.T) {
 
 	assert.False(t, sameOrder, "Shuffled copy should be in a different order from the original")
 }
+
+// TestPublicHidesCorrectAnswer checks that public questions do not expose the correct answer
+func TestPublicHidesCorrectAnswer(t *testing.T) {
+	original := getTestQuestions()
+
+	public := original.Public()
+
+	assert.Equal(t, len(original), len(public))
+	for i, question := range public {
+		assert.Equal(t, PublicQuestion{
+			ID:         original[i].ID,
+			Category:   original[i].Category,
+			Type:       TypeSingleChoice,
+			Question:   original[i].Question,
+			Answers:    original[i].Answers,
+			Difficulty: DifficultyMedium,
+		}, question)
+	}
+}
+
+// TestValidate tests the Validate method of a question
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(q *Question)
+		expectedError string
+	}{
+		{"success_valid_question", func(q *Question) {}, ""},
+		{"failure_missing_id", func(q *Question) { q.ID = 0 }, "question is invalid: id must be a positive number"},
+		{"failure_empty_category", func(q *Question) { q.Category = " " }, "question is invalid: category must not be empty"},
+		{"failure_empty_question", func(q *Question) { q.Question = "" }, "question is invalid: question must not be empty"},
+		{"failure_too_few_answers", func(q *Question) { q.Answers = []string{"H2O"} }, "question is invalid: at least two answers must be provided"},
+		{"failure_empty_answer", func(q *Question) { q.Answers[2] = "  " }, "question is invalid: answer 3 must not be empty"},
+		{"failure_duplicate_answer", func(q *Question) { q.Answers[3] = " h2o" }, "question is invalid: answer 4 duplicates answer 1"},
+		{"failure_correct_answer_too_large", func(q *Question) { q.CorrectAnswerIndex = 4 }, "question is invalid: correctAnswerIndex 4 is out of range"},
+		{"failure_negative_correct_answer", func(q *Question) { q.CorrectAnswerIndex = -1 }, "question is invalid: correctAnswerIndex -1 is out of range"},
+		{"success_known_difficulty", func(q *Question) { q.Difficulty = DifficultyHard }, ""},
+		{"failure_unknown_difficulty", func(q *Question) { q.Difficulty = "Hard" }, "question is invalid: difficulty 'Hard' must be one of easy, medium, hard"},
+		{"failure_unknown_type", func(q *Question) { q.Type = "essay" }, "question is invalid: type 'essay' must be one of single_choice, true_false, multi_select, free_text, numeric, ordering, matching"},
+		{"failure_correct_answer_indexes_for_single_choice", func(q *Question) { q.CorrectAnswerIndexes = []int{0} }, "question is invalid: correctAnswerIndexes must only be provided for multi_select, ordering and matching questions"},
+		{"failure_accepted_answers_for_single_choice", func(q *Question) { q.AcceptedAnswers = []string{"H2O"} }, "question is invalid: acceptedAnswers must only be provided for free_text questions"},
+		{"failure_correct_value_for_single_choice", func(q *Question) { q.CorrectValue = float64Pointer(2) }, "question is invalid: correctValue must only be provided for numeric questions"},
+		{"failure_unit_for_single_choice", func(q *Question) { q.Unit = "metres" }, "question is invalid: unit must only be provided for numeric questions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := getTestQuestions()[0]
+			tt.modify(&question)
+
+			err := question.Validate()
+			if tt.expectedError != "" {
+				assert.ErrorIs(t, err, ErrInvalidQuestion)
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestValidateQuestionTypes tests the Validate method for questions which are not single choice
+func TestValidateQuestionTypes(t *testing.T) {
+	tests := []struct {
+		name          string
+		question      Question
+		expectedError string
+	}{
+		{
+			name:     "success_true_false",
+			question: Question{Type: TypeTrueFalse, Answers: []string{"True", "False"}, CorrectAnswerIndex: 1},
+		},
+		{
+			name:          "failure_true_false_without_two_answers",
+			question:      Question{Type: TypeTrueFalse, Answers: []string{"True", "False", "Maybe"}},
+			expectedError: "question is invalid: exactly two answers must be provided for true_false questions",
+		},
+		{
+			name:     "success_multi_select",
+			question: Question{Type: TypeMultiSelect, Answers: []string{"H2O", "O2", "H2O2"}, CorrectAnswerIndexes: []int{0, 2}},
+		},
+		{
+			name:          "failure_multi_select_without_correct_answers",
+			question:      Question{Type: TypeMultiSelect, Answers: []string{"H2O", "O2", "H2O2"}},
+			expectedError: "question is invalid: at least one correct answer index must be provided",
+		},
+		{
+			name:          "failure_multi_select_correct_answer_out_of_range",
+			question:      Question{Type: TypeMultiSelect, Answers: []string{"H2O", "O2", "H2O2"}, CorrectAnswerIndexes: []int{0, 3}},
+			expectedError: "question is invalid: correctAnswerIndexes 3 is out of range",
+		},
+		{
+			name:          "failure_multi_select_duplicate_correct_answer",
+			question:      Question{Type: TypeMultiSelect, Answers: []string{"H2O", "O2", "H2O2"}, CorrectAnswerIndexes: []int{1, 1}},
+			expectedError: "question is invalid: correctAnswerIndexes 1 is listed more than once",
+		},
+		{
+			name:     "success_free_text",
+			question: Question{Type: TypeFreeText, AcceptedAnswers: []string{"H2O", "water"}},
+		},
+		{
+			name:          "failure_free_text_with_answers",
+			question:      Question{Type: TypeFreeText, Answers: []string{"H2O", "O2"}, AcceptedAnswers: []string{"H2O"}},
+			expectedError: "question is invalid: answers must not be provided for free_text questions",
+		},
+		{
+			name:          "failure_free_text_without_accepted_answers",
+			question:      Question{Type: TypeFreeText},
+			expectedError: "question is invalid: at least one accepted answer must be provided",
+		},
+		{
+			name:          "failure_free_text_empty_accepted_answer",
+			question:      Question{Type: TypeFreeText, AcceptedAnswers: []string{"H2O", " ?"}},
+			expectedError: "question is invalid: accepted answer 2 must not be empty",
+		},
+		{
+			name:     "success_numeric",
+			question: Question{Type: TypeNumeric, CorrectValue: float64Pointer(0), AbsoluteTolerance: 0.5, RelativeTolerance: 0.1, Unit: "degrees"},
+		},
+		{
+			name:          "failure_numeric_without_correct_value",
+			question:      Question{Type: TypeNumeric, AbsoluteTolerance: 1},
+			expectedError: "question is invalid: correctValue must be provided",
+		},
+		{
+			name:          "failure_numeric_with_answers",
+			question:      Question{Type: TypeNumeric, Answers: []string{"7", "8"}, CorrectValue: float64Pointer(8)},
+			expectedError: "question is invalid: answers must not be provided for numeric questions",
+		},
+		{
+			name:     "success_ordering_in_listed_order",
+			question: Question{Type: TypeOrdering, Answers: []string{"Please Please Me", "Revolver", "Abbey Road"}},
+		},
+		{
+			name:     "success_ordering_with_correct_order",
+			question: Question{Type: TypeOrdering, Answers: []string{"Abbey Road", "Please Please Me", "Revolver"}, CorrectAnswerIndexes: []int{1, 2, 0}},
+		},
+		{
+			name:          "failure_ordering_with_incomplete_order",
+			question:      Question{Type: TypeOrdering, Answers: []string{"Abbey Road", "Please Please Me", "Revolver"}, CorrectAnswerIndexes: []int{1, 2}},
+			expectedError: "question is invalid: correctAnswerIndexes must list every answer exactly once",
+		},
+		{
+			name:     "success_matching_with_extra_answer",
+			question: Question{Type: TypeMatching, Prompts: []string{"Camel", "Polar bear"}, Answers: []string{"Desert", "Arctic", "Rainforest"}},
+		},
+		{
+			name:          "failure_matching_without_enough_prompts",
+			question:      Question{Type: TypeMatching, Prompts: []string{"Camel"}, Answers: []string{"Desert", "Arctic"}},
+			expectedError: "question is invalid: at least two prompts must be provided",
+		},
+		{
+			name:          "failure_matching_duplicate_prompt",
+			question:      Question{Type: TypeMatching, Prompts: []string{"Camel", "camel "}, Answers: []string{"Desert", "Arctic"}},
+			expectedError: "question is invalid: prompt 2 duplicates prompt 1",
+		},
+		{
+			name:          "failure_matching_without_enough_answers",
+			question:      Question{Type: TypeMatching, Prompts: []string{"Camel", "Polar bear", "Penguin"}, Answers: []string{"Desert", "Arctic"}},
+			expectedError: "question is invalid: at least one answer must be provided for every prompt",
+		},
+		{
+			name:          "failure_matching_answer_for_every_prompt",
+			question:      Question{Type: TypeMatching, Prompts: []string{"Camel", "Polar bear"}, Answers: []string{"Desert", "Arctic"}, CorrectAnswerIndexes: []int{0}},
+			expectedError: "question is invalid: correctAnswerIndexes must list one answer for every prompt",
+		},
+		{
+			name:          "failure_prompts_for_single_choice",
+			question:      Question{Prompts: []string{"Camel", "Polar bear"}, Answers: []string{"Desert", "Arctic"}},
+			expectedError: "question is invalid: prompts must only be provided for matching questions",
+		},
+		{
+			name:          "failure_numeric_negative_tolerance",
+			question:      Question{Type: TypeNumeric, CorrectValue: float64Pointer(8), RelativeTolerance: -0.1},
+			expectedError: "question is invalid: relativeTolerance must not be negative",
+		},
+		{
+			name:     "success_time_limit",
+			question: Question{Answers: []string{"H2O", "O2"}, TimeLimit: 30},
+		},
+		{
+			name:          "failure_negative_points",
+			question:      Question{Answers: []string{"H2O", "O2"}, Points: -1},
+			expectedError: "question is invalid: points must not be negative",
+		},
+		{
+			name:          "failure_negative_time_limit",
+			question:      Question{Answers: []string{"H2O", "O2"}, TimeLimit: -5},
+			expectedError: "question is invalid: timeLimit must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := tt.question
+			question.ID = 1
+			question.Category = "science"
+			question.Question = "What is the answer?"
+
+			err := question.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestProblems checks that every problem with a question is reported against its field
+func TestProblems(t *testing.T) {
+	question := Question{Category: "science", Answers: []string{"H2O", "", "h2o"}, CorrectAnswerIndex: 3}
+
+	problems := question.Problems()
+
+	assert.Equal(t, []QuestionProblem{
+		{"id", "id must be a positive number"},
+		{"question", "question must not be empty"},
+		{"answers", "answer 2 must not be empty"},
+		{"answers", "answer 3 duplicates answer 1"},
+		{"correctAnswerIndex", "correctAnswerIndex 3 is out of range"},
+	}, problems)
+}
+
+// TestWithDifficulty checks that questions are filtered by difficulty and that questions without one are medium
+func TestWithDifficulty(t *testing.T) {
+	questions := getTestQuestions()
+	questions[0].Difficulty = DifficultyEasy
+	questions[1].Difficulty = DifficultyHard
+	questions[2].Difficulty = DifficultyMedium
+
+	tests := []struct {
+		name        string
+		difficulty  string
+		expectedIDs []int
+	}{
+		{"success_every_question_without_difficulty", "", []int{1, 2, 3, 4, 5}},
+		{"success_easy", DifficultyEasy, []int{1}},
+		{"success_medium_includes_unspecified", DifficultyMedium, []int{3, 4, 5}},
+		{"success_hard", DifficultyHard, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []int{}
+			for _, question := range questions.WithDifficulty(tt.difficulty) {
+				ids = append(ids, question.ID)
+			}
+
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+// TestReordered tests reordering the answers of a question
+func TestReordered(t *testing.T) {
+	tests := []struct {
+		name            string
+		order           []int
+		expectedAnswers []string
+		expectedIndex   int
+	}{
+		{"success_reversed_order", []int{3, 2, 1, 0}, []string{"HO", "H2O2", "O2", "H2O"}, 3},
+		{"success_original_order", []int{0, 1, 2, 3}, []string{"H2O", "O2", "H2O2", "HO"}, 0},
+		{"unchanged_without_order", nil, []string{"H2O", "O2", "H2O2", "HO"}, 0},
+		{"unchanged_when_order_does_not_fit", []int{1, 0}, []string{"H2O", "O2", "H2O2", "HO"}, 0},
+		{"unchanged_when_order_is_out_of_range", []int{4, 2, 1, 0}, []string{"H2O", "O2", "H2O2", "HO"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := getTestQuestions()[0]
+
+			reordered := original.Reordered(tt.order)
+
+			assert.Equal(t, tt.expectedAnswers, reordered.Answers)
+			assert.Equal(t, tt.expectedIndex, reordered.CorrectAnswerIndex)
+			assert.Equal(t, []string{"H2O", "O2", "H2O2", "HO"}, original.Answers, "The original question should be unchanged")
+		})
+	}
+}
+
+// TestReorderedMultiSelect checks that the correct answers of a multi-select question follow their answers
+func TestReorderedMultiSelect(t *testing.T) {
+	question := Question{ID: 1, Type: TypeMultiSelect, Answers: []string{"Mercury", "Venus", "Mars", "Jupiter"}, CorrectAnswerIndexes: []int{0, 2}}
+
+	reordered := question.Reordered([]int{2, 3, 1, 0})
+
+	assert.Equal(t, []string{"Mars", "Jupiter", "Venus", "Mercury"}, reordered.Answers)
+	assert.Equal(t, []int{0, 3}, reordered.CorrectAnswerIndexes)
+	assert.Equal(t, []int{0, 2}, question.CorrectAnswerIndexes, "Expected the original question to be unchanged")
+}
+
+// TestReorderedOrderingAndMatching checks that the correct order of an ordering question and the matches of a matching question
+// follow their answers, including when they are listed in order rather than with correct answer indexes
+func TestReorderedOrderingAndMatching(t *testing.T) {
+	ordering := Question{Type: TypeOrdering, Answers: []string{"Please Please Me", "Revolver", "Abbey Road"}}
+	matching := Question{Type: TypeMatching, Prompts: []string{"Camel", "Polar bear"}, Answers: []string{"Desert", "Arctic", "Rainforest"}}
+
+	reordered := ordering.Reordered([]int{2, 0, 1})
+	assert.Equal(t, []string{"Abbey Road", "Please Please Me", "Revolver"}, reordered.Answers)
+	assert.Equal(t, []int{1, 2, 0}, reordered.CorrectAnswerIndexes)
+	assert.Equal(t, "Please Please Me, Revolver, Abbey Road", reordered.CorrectAnswer())
+
+	reordered = matching.Reordered([]int{2, 1, 0})
+	assert.Equal(t, []string{"Rainforest", "Arctic", "Desert"}, reordered.Answers)
+	assert.Equal(t, []int{2, 1}, reordered.CorrectAnswerIndexes)
+	assert.Equal(t, "Camel: Desert, Polar bear: Arctic", reordered.CorrectAnswer())
+}
+
+// TestCredit tests scoring a response to each type of question
+func TestCredit(t *testing.T) {
+	singleChoice := Question{Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0}
+	trueFalse := Question{Type: TypeTrueFalse, Answers: []string{"True", "False"}, CorrectAnswerIndex: 1}
+	multiSelect := Question{Type: TypeMultiSelect, Answers: []string{"Mercury", "Venus", "Earth", "Mars"}, CorrectAnswerIndexes: []int{0, 1}}
+	freeText := Question{Type: TypeFreeText, AcceptedAnswers: []string{"The Beatles", "Beatles"}}
+	ordering := Question{Type: TypeOrdering, Answers: []string{"Please Please Me", "Revolver", "Abbey Road", "Let It Be"}}
+	matching := Question{Type: TypeMatching, Prompts: []string{"Camel", "Polar bear", "Penguin"}, Answers: []string{"Desert", "Arctic", "Antarctic"}, CorrectAnswerIndexes: []int{0, 1, 2}}
+	exact := Question{Type: TypeNumeric, CorrectValue: float64Pointer(8)}
+	absolute := Question{Type: TypeNumeric, CorrectValue: float64Pointer(100), AbsoluteTolerance: 10}
+	relative := Question{Type: TypeNumeric, CorrectValue: float64Pointer(-8849), AbsoluteTolerance: 1, RelativeTolerance: 0.01}
+
+	tests := []struct {
+		name           string
+		question       Question
+		response       QuestionResponse
+		expectedCredit float64
+	}{
+		{"success_single_choice_correct", singleChoice, QuestionResponse{Answer: 0}, 1},
+		{"success_single_choice_incorrect", singleChoice, QuestionResponse{Answer: 1}, 0},
+		{"success_true_false_correct", trueFalse, QuestionResponse{Answer: 1}, 1},
+		{"success_true_false_unanswered", trueFalse, QuestionResponse{Answer: -1}, 0},
+		{"success_multi_select_all_correct", multiSelect, QuestionResponse{Answers: []int{1, 0}}, 1},
+		{"success_multi_select_partially_correct", multiSelect, QuestionResponse{Answers: []int{1}}, 0.5},
+		{"success_multi_select_duplicates_count_once", multiSelect, QuestionResponse{Answers: []int{1, 1}}, 0.5},
+		{"success_multi_select_incorrect_answers_deducted", multiSelect, QuestionResponse{Answers: []int{0, 1, 3}}, 0.5},
+		{"success_multi_select_never_negative", multiSelect, QuestionResponse{Answers: []int{2, 3}}, 0},
+		{"success_multi_select_unanswered", multiSelect, QuestionResponse{}, 0},
+		{"success_free_text_exact", freeText, QuestionResponse{Text: "The Beatles"}, 1},
+		{"success_free_text_normalised", freeText, QuestionResponse{Text: "  the   BEATLES! "}, 1},
+		{"success_free_text_incorrect", freeText, QuestionResponse{Text: "The Rolling Stones"}, 0},
+		{"success_free_text_empty", freeText, QuestionResponse{Text: " "}, 0},
+		{"success_ordering_correct", ordering, QuestionResponse{Answers: []int{0, 1, 2, 3}}, 1},
+		{"success_ordering_partially_correct", ordering, QuestionResponse{Answers: []int{0, 2, 1, 3}}, 0.5},
+		{"success_ordering_incomplete", ordering, QuestionResponse{Answers: []int{0}}, 0.25},
+		{"success_ordering_unanswered", ordering, QuestionResponse{}, 0},
+		{"success_matching_correct", matching, QuestionResponse{Answers: []int{0, 1, 2}}, 1},
+		{"success_matching_partially_correct", matching, QuestionResponse{Answers: []int{0, 2, -1}}, 1.0 / 3},
+		{"success_numeric_exact", exact, QuestionResponse{Value: float64Pointer(8)}, 1},
+		{"success_numeric_exact_without_tolerance", exact, QuestionResponse{Value: float64Pointer(8.01)}, 0},
+		{"success_numeric_unanswered", exact, QuestionResponse{}, 0},
+		{"success_numeric_within_absolute_tolerance", absolute, QuestionResponse{Value: float64Pointer(90)}, 1},
+		{"success_numeric_close_to_absolute_tolerance", absolute, QuestionResponse{Value: float64Pointer(115)}, 0.5},
+		{"success_numeric_beyond_twice_the_tolerance", absolute, QuestionResponse{Value: float64Pointer(79)}, 0},
+		{"success_numeric_within_relative_tolerance", relative, QuestionResponse{Value: float64Pointer(-8800)}, 1},
+		{"success_numeric_close_to_relative_tolerance", relative, QuestionResponse{Value: float64Pointer(-8716.265)}, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expectedCredit, tt.question.Credit(tt.response), 1e-9)
+		})
+	}
+}
+
+// TestNormaliseText tests normalising free-text answers before they are compared
+func TestNormaliseText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"success_lowercase_and_trimmed", "  Paris ", "paris"},
+		{"success_punctuation_removed", "St. Paul's", "st pauls"},
+		{"success_whitespace_collapsed", "New \t York", "new york"},
+		{"success_hyphens_separate_words", "Rock-n-roll", "rock n roll"},
+		{"success_leading_article_dropped", "The Beatles", "beatles"},
+		{"success_lone_article_kept", "A", "a"},
+		{"success_empty", " ?! ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormaliseText(tt.input))
+		})
+	}
+}
+
+// TestQuizLength tests the defaults and limits for the number of questions in a quiz
+func TestQuizLength(t *testing.T) {
+	length := QuizLength{Min: 1, Max: 10, Default: 5, CategoryDefaults: map[string]int{"science": 8, "science/physics": 3}}
+
+	assert.Equal(t, 8, length.DefaultFor("science"))
+	assert.Equal(t, 3, length.DefaultFor("science/physics/optics"))
+	assert.Equal(t, 8, length.DefaultFor("science/chemistry"))
+	assert.Equal(t, 5, length.DefaultFor("random"))
+	assert.True(t, length.Allows(1))
+	assert.True(t, length.Allows(10))
+	assert.False(t, length.Allows(0))
+	assert.False(t, length.Allows(11))
+
+	tests := []struct {
+		name          string
+		length        QuizLength
+		expectedError string
+	}{
+		{"success_valid_limits", length, ""},
+		{"failure_minimum_below_one", QuizLength{Min: 0, Max: 10, Default: 5}, "the minimum of 0 and maximum of 10 questions must be positive and in order"},
+		{"failure_maximum_below_minimum", QuizLength{Min: 5, Max: 4, Default: 5}, "the minimum of 5 and maximum of 4 questions must be positive and in order"},
+		{"failure_default_out_of_range", QuizLength{Min: 1, Max: 10, Default: 11}, "the default of 11 questions must be between 1 and 10"},
+		{"failure_category_default_out_of_range", QuizLength{Min: 1, Max: 10, Default: 5, CategoryDefaults: map[string]int{"music": 0}}, "the default of 0 questions for category 'music' must be between 1 and 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.length.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestTimeLimits checks that question time limits take precedence over category limits, which take precedence over the default
+func TestTimeLimits(t *testing.T) {
+	limits := TimeLimits{Default: 20, CategoryDefaults: map[string]int{"science": 45, "music": 0}}
+
+	assert.Equal(t, 10, limits.For(Question{Category: "science", TimeLimit: 10}))
+	assert.Equal(t, 45, limits.For(Question{Category: "science"}))
+	assert.Equal(t, 45, limits.For(Question{Category: "science/physics"}))
+	assert.Equal(t, 0, limits.For(Question{Category: "music/jazz"}))
+	assert.Equal(t, 0, limits.For(Question{Category: "music"}))
+	assert.Equal(t, 20, limits.For(Question{Category: "history"}))
+
+	tests := []struct {
+		name          string
+		limits        TimeLimits
+		expectedError string
+	}{
+		{"success_valid_limits", limits, ""},
+		{"success_untimed", TimeLimits{}, ""},
+		{"failure_negative_default", TimeLimits{Default: -1}, "the default time limit of -1 seconds must not be negative"},
+		{"failure_negative_category_limit", TimeLimits{CategoryDefaults: map[string]int{"music": -10}}, "the time limit of -10 seconds for category 'music' must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestDeadlines checks that the time allowed for each question runs from the most recently checked answer
+func TestDeadlines(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := QuizSession{
+		QuestionIDs: []int{1, 2, 3},
+		Answers:     map[int]QuestionResponse{},
+		TimeLimits:  map[int]int{1: 30, 2: 20},
+		CreatedAt:   createdAt,
+	}
+
+	deadline, timed := session.Deadline(1)
+	assert.True(t, timed)
+	assert.Equal(t, createdAt.Add(30*time.Second), deadline)
+
+	_, timed = session.Deadline(3)
+	assert.False(t, timed, "Questions without a time limit should not have a deadline")
+
+	assert.Equal(t, map[int]time.Time{1: createdAt.Add(30 * time.Second), 2: createdAt.Add(50 * time.Second)}, session.SubmissionDeadlines())
+
+	session.Answers[1] = QuestionResponse{QuestionID: 1, Answer: 0}
+	session.AnsweredAt = map[int]time.Time{1: createdAt.Add(10 * time.Second)}
+	session.LastAnsweredAt = createdAt.Add(10 * time.Second)
+
+	deadline, timed = session.Deadline(2)
+	assert.True(t, timed)
+	assert.Equal(t, createdAt.Add(30*time.Second), deadline)
+
+	assert.Equal(t, map[int]time.Time{2: createdAt.Add(30 * time.Second)}, session.SubmissionDeadlines(), "Expected checked answers to have no submission deadline")
+
+	session.Answers[2] = QuestionResponse{QuestionID: 2, Answer: 0}
+	assert.Empty(t, session.SubmissionDeadlines(), "Only untimed questions remain")
+}
+
+// TestSubmissionDeadlinesAfterUntimedQuestion checks that questions after an unchecked untimed question have no deadline
+// until the next checked answer
+func TestSubmissionDeadlinesAfterUntimedQuestion(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := QuizSession{
+		QuestionIDs: []int{1, 2, 3, 4},
+		Answers:     map[int]QuestionResponse{3: {QuestionID: 3, Answer: 0}},
+		TimeLimits:  map[int]int{2: 20, 3: 20, 4: 10},
+		AnsweredAt:  map[int]time.Time{3: createdAt.Add(time.Minute)},
+		CreatedAt:   createdAt,
+	}
+
+	assert.Equal(t, map[int]time.Time{4: createdAt.Add(70 * time.Second)}, session.SubmissionDeadlines())
+}
+
+// TestSkipped checks that blank responses to each type of question are recognised
+func TestSkipped(t *testing.T) {
+	singleChoice := Question{Answers: []string{"H2O", "O2"}}
+	multiSelect := Question{Type: TypeMultiSelect, Answers: []string{"H2O", "O2"}}
+	matching := Question{Type: TypeMatching, Prompts: []string{"Camel", "Polar bear"}, Answers: []string{"Desert", "Arctic"}}
+	freeText := Question{Type: TypeFreeText}
+	numeric := Question{Type: TypeNumeric}
+
+	tests := []struct {
+		name     string
+		question Question
+		response QuestionResponse
+		expected bool
+	}{
+		{"skipped_single_choice", singleChoice, QuestionResponse{Answer: -1}, true},
+		{"skipped_single_choice_out_of_range", singleChoice, QuestionResponse{Answer: 2}, true},
+		{"answered_single_choice", singleChoice, QuestionResponse{Answer: 1}, false},
+		{"skipped_multi_select", multiSelect, QuestionResponse{}, true},
+		{"answered_multi_select", multiSelect, QuestionResponse{Answers: []int{1}}, false},
+		{"skipped_matching", matching, QuestionResponse{Answers: []int{-1, -1}}, true},
+		{"answered_matching", matching, QuestionResponse{Answers: []int{-1, 0}}, false},
+		{"skipped_free_text", freeText, QuestionResponse{Text: " . "}, true},
+		{"answered_free_text", freeText, QuestionResponse{Text: "Au"}, false},
+		{"skipped_numeric", numeric, QuestionResponse{}, true},
+		{"answered_numeric", numeric, QuestionResponse{Value: float64Pointer(0)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.question.Skipped(tt.response))
+		})
+	}
+
+	assert.Equal(t, 1.0, singleChoice.Weight(), "Questions without points should be worth one point")
+	assert.Equal(t, 2.5, Question{Points: 2.5}.Weight())
+}
+
+// TestNormalisePlayerName tests tidying and checking player names
+func TestNormalisePlayerName(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      string
+		expectedError string
+	}{
+		{"success_collapses_whitespace", "  Ada   Lovelace ", "Ada Lovelace", ""},
+		{"success_anonymous", "   ", "", ""},
+		{"success_unicode", "Zoë", "Zoë", ""},
+		{"failure_too_long", strings.Repeat("a", 33), "", "player name must be at most 32 characters"},
+		{"failure_not_printable", "Ada\u0007", "", "player name must only contain printable characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := NormalisePlayerName(tt.input)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, name)
+			}
+		})
+	}
+}
+
+// TestDisplayName tests deriving display names from category slugs
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		name     string
+		slug     string
+		expected string
+	}{
+		{"success_single_word", "music", "Music"},
+		{"success_hyphens", "world-history", "World History"},
+		{"success_underscores", "pop_music", "Pop Music"},
+		{"success_spaces", "general  knowledge", "General Knowledge"},
+		{"success_subcategory", "science/particle-physics", "Particle Physics"},
+		{"success_empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DisplayName(tt.slug))
+		})
+	}
+}
+
+// TestDifficultiesOf tests listing the difficulties of questions from easiest to hardest
+func TestDifficultiesOf(t *testing.T) {
+	questions := Questions{{Difficulty: "hard"}, {}, {Difficulty: "hard"}, {Difficulty: "easy"}}
+	assert.Equal(t, []string{"easy", "medium", "hard"}, DifficultiesOf(questions))
+	assert.Equal(t, []string{"hard"}, DifficultiesOf(questions[:1]))
+	assert.Equal(t, []string{}, DifficultiesOf(nil))
+}
+
+// TestCategoryLevels tests finding the parents of categories within the category tree
+func TestCategoryLevels(t *testing.T) {
+	assert.Equal(t, []string{"music"}, CategoryLevels("music"))
+	assert.Equal(t, []string{"science/physics/optics", "science/physics", "science"}, CategoryLevels("science/physics/optics"))
+
+	assert.Equal(t, "", ParentCategory("music"))
+	assert.Equal(t, "science", ParentCategory("science/physics"))
+
+	assert.True(t, IsWithin("science", "science"))
+	assert.True(t, IsWithin("science/physics/optics", "science"))
+	assert.False(t, IsWithin("sciences", "science"), "Expected categories sharing a prefix not to be subcategories")
+	assert.False(t, IsWithin("science", "science/physics"))
+}
@@ -0,0 +1,226 @@
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"quizwizard/api/bank"
+	"quizwizard/api/lint"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+)
+
+var (
+	// ErrUnchanged is returned by Reload when the questions file is the same as the one last loaded
+	ErrUnchanged = errors.New("questions file is unchanged")
+	// ErrEdited is returned by Reload when loading the questions file would discard changes made through the admin endpoints
+	ErrEdited = errors.New("questions have been edited through the admin endpoints since the questions file was loaded")
+)
+
+// State records which questions file was last loaded into the bank, so that changes made while the API was stopped
+// can be detected
+type State interface {
+	LoadedFile() (fileHash string, questionsHash string, err error)
+	SetLoadedFile(fileHash string, questionsHash string) error
+}
+
+// Reloader reloads the live question bank from a questions file
+type Reloader struct {
+	mu       sync.Mutex
+	filename string
+	bank     *bank.Bank
+	scores   scores.ScoreStore
+	state    State
+	modTime  time.Time
+	size     int64
+	// fileHash and questionsHash identify the file last loaded into the bank and the questions it held
+	fileHash      string
+	questionsHash string
+}
+
+// New creates a reloader for the specified questions file. The state is optional. Without a recorded questions file the
+// bank is assumed to hold no admin changes, and the file is loaded by the next reload even if it has not changed.
+func New(filename string, b *bank.Bank, s scores.ScoreStore, state State) (*Reloader, error) {
+	r := &Reloader{
+		filename: filename,
+		bank:     b,
+		scores:   s,
+		state:    state,
+	}
+
+	if state != nil {
+		fileHash, questionsHash, err := state.LoadedFile()
+		if err != nil {
+			return nil, err
+		}
+		r.fileHash, r.questionsHash = fileHash, questionsHash
+	}
+	if r.questionsHash == "" {
+		r.fileHash, r.questionsHash = "", bank.Hash(b.Questions())
+	}
+
+	if info, err := os.Stat(filename); err == nil {
+		r.modTime = info.ModTime()
+		r.size = info.Size()
+	}
+
+	return r, nil
+}
+
+// Reload lints the questions file and then swaps it into the live bank. It returns ErrUnchanged if the file is the
+// same as the one last loaded, and ErrEdited rather than discard changes made through the admin endpoints since then.
+// If the file cannot be read or is invalid the live bank is left unchanged.
+func (r *Reloader) Reload() (bank.Diff, error) {
+	return r.reload(false)
+}
+
+// ForceReload lints the questions file and then swaps it into the live bank, even if it is unchanged or this discards
+// changes made through the admin endpoints
+func (r *Reloader) ForceReload() (bank.Diff, error) {
+	return r.reload(true)
+}
+
+// Sync loads the questions file if it has changed since it was last loaded, such as while the API was stopped, and
+// logs the outcome
+func (r *Reloader) Sync() {
+	r.reloadAndLog("startup", false)
+}
+
+// reload loads the questions file into the live bank, checking first that it has changed and that no admin changes
+// would be lost unless forced
+func (r *Reloader) reload(force bool) (bank.Diff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if info, err := os.Stat(r.filename); err == nil {
+		r.modTime = info.ModTime()
+		r.size = info.Size()
+	}
+
+	data, err := os.ReadFile(r.filename)
+	if err != nil {
+		return bank.Diff{}, fmt.Errorf("failed to read file %s: %w", r.filename, err)
+	}
+
+	fileHash := hashBytes(data)
+	if !force && fileHash == r.fileHash {
+		return bank.Diff{}, ErrUnchanged
+	}
+
+	old := r.bank.Questions()
+	if !force && bank.Hash(old) != r.questionsHash {
+		return bank.Diff{}, ErrEdited
+	}
+
+	report := lint.Lint(data)
+	report.File = r.filename
+	if err := report.Err(); err != nil {
+		return bank.Diff{}, fmt.Errorf("questions within file %s are invalid: %w", r.filename, err)
+	}
+
+	var questions map[string]models.Questions
+	if err := json.Unmarshal(data, &questions); err != nil {
+		return bank.Diff{}, fmt.Errorf("failed to unmarshal JSON within file %s: %w", r.filename, err)
+	}
+
+	// The bank is checked again as it is replaced, in case the questions were edited while the file was linted
+	if force {
+		err = r.bank.Replace(questions)
+	} else {
+		err = r.bank.ReplaceIf(r.questionsHash, questions)
+	}
+	if errors.Is(err, bank.ErrChanged) {
+		return bank.Diff{}, ErrEdited
+	} else if err != nil {
+		return bank.Diff{}, fmt.Errorf("questions within file %s were rejected: %w", r.filename, err)
+	}
+
+	r.fileHash, r.questionsHash = fileHash, bank.Hash(questions)
+	if r.state != nil {
+		if err := r.state.SetLoadedFile(r.fileHash, r.questionsHash); err != nil {
+			log.Printf("Failed to record the questions loaded from %s: %v", r.filename, err)
+		}
+	}
+
+	// Newly-appeared categories need somewhere to record their scores
+	for category := range questions {
+		for _, level := range models.CategoryLevels(category) {
+			r.scores.AddCategory(level)
+		}
+	}
+
+	return bank.Compare(old, questions), nil
+}
+
+// Watch polls the questions file at the specified interval and reloads the bank whenever it changes, until the context is cancelled
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.changed() {
+				r.reloadAndLog("file change", false)
+			}
+		}
+	}
+}
+
+// HandleSignals reloads the bank every time a signal is received on the channel, until the context is cancelled. These
+// reloads are forced, so a signal loads the file even if this discards changes made through the admin endpoints.
+func (r *Reloader) HandleSignals(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			r.reloadAndLog(sig.String(), true)
+		}
+	}
+}
+
+// changed reports whether the questions file has been modified since it was last loaded
+func (r *Reloader) changed() bool {
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size
+}
+
+// reloadAndLog reloads the bank and logs the outcome. An unchanged file is not logged.
+func (r *Reloader) reloadAndLog(trigger string, force bool) {
+	diff, err := r.reload(force)
+	if errors.Is(err, ErrUnchanged) {
+		return
+	} else if errors.Is(err, ErrEdited) {
+		log.Printf("Not reloading questions from %s after %s as %v. Send SIGHUP to reload them anyway.", r.filename, trigger, err)
+		return
+	} else if err != nil {
+		log.Printf("Failed to reload questions after %s, keeping the current question bank: %v", trigger, err)
+		return
+	}
+
+	log.Printf("Reloaded questions from %s after %s: %s", r.filename, trigger, diff)
+}
+
+// hashBytes returns a hex-encoded SHA-256 hash of the data
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quizwizard/api/bank"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validQuestions = `{
+  "science": [
+    {"id": 1, "category": "science", "question": "What is the chemical symbol for water?", "answers": ["H2O", "O2"], "correctAnswerIndex": 0}
+  ],
+  "music": [
+    {"id": 2, "category": "music", "question": "Who is the lead vocalist of the band Queen?", "answers": ["Freddie Mercury", "John Lennon"], "correctAnswerIndex": 0}
+  ]
+}`
+
+const invalidQuestions = `{
+  "science": [
+    {"id": 1, "category": "science", "question": "What is the chemical symbol for water?", "answers": ["H2O", "O2"], "correctAnswerIndex": 5}
+  ]
+}`
+
+// setup is a helper function which creates a questions file, a bank holding only a science question and a score store
+func setup(t *testing.T, contents string) (string, *bank.Bank, scores.ScoreStore) {
+	filename := filepath.Join(t.TempDir(), "questions.json")
+	writeFile(t, filename, contents)
+
+	b := bank.New(map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for salt?", Answers: []string{"NaCl", "KCl"}, CorrectAnswerIndex: 0},
+		},
+	}, nil)
+
+	store := scores.NewMemoryStore()
+	store.Reset([]string{"random", "science"})
+
+	return filename, b, store
+}
+
+// newReloader is a helper function which creates a reloader and fails the test if it cannot be created
+func newReloader(t *testing.T, filename string, b *bank.Bank, store scores.ScoreStore, state State) *Reloader {
+	reloader, err := New(filename, b, store, state)
+	if err != nil {
+		t.Fatalf("failed to create reloader: %v", err)
+	}
+	return reloader
+}
+
+// memoryState records the loaded questions file in memory, standing in for the database across restarts
+type memoryState struct {
+	fileHash      string
+	questionsHash string
+	err           error
+}
+
+func (s *memoryState) LoadedFile() (string, string, error) {
+	return s.fileHash, s.questionsHash, s.err
+}
+
+func (s *memoryState) SetLoadedFile(fileHash string, questionsHash string) error {
+	s.fileHash, s.questionsHash = fileHash, questionsHash
+	return nil
+}
+
+// writeFile is a helper function which overwrites a file and moves its modification time forward
+func writeFile(t *testing.T, filename string, contents string) {
+	if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write questions file: %v", err)
+	}
+
+	modTime := time.Now().Add(time.Duration(len(contents)) * time.Millisecond)
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatalf("failed to update modification time: %v", err)
+	}
+}
+
+// TestReload checks that a valid file is swapped in and new categories receive a score bucket
+func TestReload(t *testing.T) {
+	filename, b, store := setup(t, validQuestions)
+	reloader := newReloader(t, filename, b, store, nil)
+
+	diff, err := reloader.Reload()
+
+	assert.NoError(t, err)
+	assert.Equal(t, bank.Diff{AddedCategories: []string{"music"}, AddedQuestions: 1, ChangedQuestions: 1}, diff)
+	assert.Equal(t, "What is the chemical symbol for water?", b.Questions()["science"][0].Question)
+	assert.True(t, store.HasCategory("music"))
+}
+
+// TestReloadRejectsInvalidFile checks that an invalid or unreadable file leaves the live bank unchanged
+func TestReloadRejectsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{"invalid_question", invalidQuestions, "correctAnswerIndex 5 is out of range"},
+		{"invalid_json", `{"science": [`, "invalid JSON"},
+		{"unknown_field", strings.Replace(validQuestions, `"answers"`, `"anwsers"`, 1), "unknown field 'anwsers'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename, b, store := setup(t, tt.contents)
+			before := b.Questions()
+
+			_, err := newReloader(t, filename, b, store, nil).Reload()
+
+			assert.ErrorContains(t, err, tt.expectedError)
+			assert.Equal(t, before, b.Questions())
+		})
+	}
+}
+
+// TestReloadSkipsUnchangedFile checks that a file which has already been loaded is not loaded again unless forced
+func TestReloadSkipsUnchangedFile(t *testing.T) {
+	filename, b, store := setup(t, validQuestions)
+	reloader := newReloader(t, filename, b, store, nil)
+
+	_, err := reloader.Reload()
+	assert.NoError(t, err)
+
+	_, err = reloader.Reload()
+	assert.ErrorIs(t, err, ErrUnchanged)
+
+	_, err = reloader.ForceReload()
+	assert.NoError(t, err)
+}
+
+// TestReloadRefusesToDiscardEdits checks that changes made through the admin endpoints are only replaced by a forced reload
+func TestReloadRefusesToDiscardEdits(t *testing.T) {
+	filename, b, store := setup(t, validQuestions)
+	reloader := newReloader(t, filename, b, store, nil)
+
+	_, err := reloader.Reload()
+	assert.NoError(t, err)
+
+	_, err = b.AddQuestion(models.Question{Category: "science", Question: "What is the chemical symbol for gold?", Answers: []string{"Au", "Ag"}, CorrectAnswerIndex: 0})
+	assert.NoError(t, err)
+	edited := b.Questions()
+
+	writeFile(t, filename, invalidQuestions[:len(invalidQuestions)-1]+`, "history": []}`)
+	_, err = reloader.Reload()
+	assert.ErrorIs(t, err, ErrEdited)
+	assert.Equal(t, edited, b.Questions())
+
+	writeFile(t, filename, validQuestions)
+	diff, err := reloader.ForceReload()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, diff.RemovedQuestions)
+	assert.Len(t, b.Questions()["music"], 1)
+
+	// Once the file has been loaded again later changes to it are reloaded as normal
+	writeFile(t, filename, validQuestions[:len(validQuestions)-1]+`, "history": []}`)
+	_, err = reloader.Reload()
+	assert.NoError(t, err)
+	assert.Contains(t, b.Questions(), "history")
+}
+
+// TestReloadRacingAdminEdit checks that a question added through the admin endpoints while the file is being reloaded
+// is never discarded. Either the edit lands first and the reload is refused, or the reload lands first and the edit is
+// made to the reloaded questions.
+func TestReloadRacingAdminEdit(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		filename, b, store := setup(t, validQuestions)
+		reloader := newReloader(t, filename, b, store, nil)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := b.AddQuestion(models.Question{Category: "science", Question: "What is the chemical symbol for gold?", Answers: []string{"Au", "Ag"}, CorrectAnswerIndex: 0})
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := reloader.Reload()
+			if err != nil {
+				assert.ErrorIs(t, err, ErrEdited)
+			}
+		}()
+		wg.Wait()
+
+		found := false
+		for _, question := range b.Questions()["science"] {
+			found = found || question.Question == "What is the chemical symbol for gold?"
+		}
+		if !found {
+			t.Fatalf("the question added while reloading was discarded")
+		}
+	}
+}
+
+// TestNewDetectsChangesWhileStopped checks that the recorded state tells whether the file changed while the API was stopped
+func TestNewDetectsChangesWhileStopped(t *testing.T) {
+	filename, b, store := setup(t, validQuestions)
+	state := &memoryState{}
+
+	_, err := newReloader(t, filename, b, store, state).Reload()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, state.fileHash)
+
+	// Restarting without changes to the file loads nothing
+	restarted := bank.New(b.Questions(), nil)
+	_, err = newReloader(t, filename, restarted, store, state).Reload()
+	assert.ErrorIs(t, err, ErrUnchanged)
+
+	// Changes made while stopped are loaded even though the file is older than the reloader
+	writeFile(t, filename, validQuestions[:len(validQuestions)-1]+`, "history": []}`)
+	restarted = bank.New(b.Questions(), nil)
+	_, err = newReloader(t, filename, restarted, store, state).Reload()
+	assert.NoError(t, err)
+	assert.Contains(t, restarted.Questions(), "history")
+
+	_, err = New(filename, b, store, &memoryState{err: errors.New("database is closed")})
+	assert.EqualError(t, err, "database is closed")
+}
+
+// TestWatch checks that changes to the questions file are picked up by polling
+func TestWatch(t *testing.T) {
+	filename, b, store := setup(t, invalidQuestions)
+	reloader := newReloader(t, filename, b, store, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	writeFile(t, filename, validQuestions)
+
+	assert.Eventually(t, func() bool {
+		_, ok := b.Questions()["music"]
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestHandleSignals checks that a signal triggers a reload
+func TestHandleSignals(t *testing.T) {
+	filename, b, store := setup(t, validQuestions)
+	reloader := newReloader(t, filename, b, store, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	go reloader.HandleSignals(ctx, signals)
+	signals <- os.Interrupt
+
+	assert.Eventually(t, func() bool {
+		_, ok := b.Questions()["music"]
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
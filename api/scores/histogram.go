@@ -0,0 +1,197 @@
+package scores
+
+import (
+	"math"
+)
+
+const (
+	// Resolution is the number of histogram bins per percentage point. Scores are rounded to the nearest bin, so two
+	// scores are only told apart when they differ by at least half of 1/Resolution of a percentage point.
+	Resolution = 100
+	// bins is the number of histogram bins covering every score from 0% to 100%
+	bins = 100*Resolution + 1
+	// treeSpan is the largest power of two no greater than bins, from which searches of the Fenwick tree start
+	treeSpan = 8192
+	// denseNodes is the number of Fenwick tree nodes a histogram tracks sparsely before switching to a dense array.
+	// Below it, a map of the touched nodes uses less memory than the array.
+	denseNodes = 1024
+)
+
+// Histogram counts percentage scores in fixed-width bins, using no more memory however many scores it holds.
+// Bin counts are kept in a Fenwick tree so that adding a score and counting the scores below another both take
+// O(log n) time in the number of bins. The tree only tracks the nodes which have been touched until it holds enough
+// distinct scores to be worth a dense array, so empty and rarely played buckets cost little. The count, mean,
+// standard deviation, lowest and highest scores are tracked exactly.
+//
+// Percentiles match an exact comparison against every score whenever no two different scores share a bin, which
+// holds for every score of the form correct/questions of quizzes with fewer than 100 questions. Medians, pass rates
+// and histogram buckets are also calculated from the bins, so they share this precision. A Histogram is not safe
+// for concurrent use.
+type Histogram struct {
+	sparse  map[int]int64
+	tree    []int64
+	count   int64
+	mean    float64
+	m2      float64
+	lowest  float64
+	highest float64
+}
+
+// NewHistogram creates an empty histogram
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// bin returns the index of the bin a score is counted in. Scores outside 0% to 100% are counted in the nearest end bin.
+func bin(score float64) int {
+	index := int(math.Round(score * Resolution))
+	if index < 0 {
+		return 0
+	}
+	if index >= bins {
+		return bins - 1
+	}
+	return index
+}
+
+// node returns the count held by a node of the Fenwick tree
+func (h *Histogram) node(i int) int64 {
+	if h.tree != nil {
+		return h.tree[i]
+	}
+	return h.sparse[i]
+}
+
+// increment adds to a node of the Fenwick tree, switching to a dense array once enough nodes have been touched
+func (h *Histogram) increment(i int, n int64) {
+	if h.tree != nil {
+		h.tree[i] += n
+		return
+	}
+
+	if h.sparse == nil {
+		h.sparse = make(map[int]int64)
+	}
+	h.sparse[i] += n
+
+	if len(h.sparse) > denseNodes {
+		h.tree = make([]int64, bins+1)
+		for node, count := range h.sparse {
+			h.tree[node] = count
+		}
+		h.sparse = nil
+	}
+}
+
+// Add counts a new score
+func (h *Histogram) Add(score float64) {
+	for i := bin(score) + 1; i <= bins; i += i & -i {
+		h.increment(i, 1)
+	}
+
+	if h.count == 0 || score < h.lowest {
+		h.lowest = score
+	}
+	if h.count == 0 || score > h.highest {
+		h.highest = score
+	}
+	// Welford's method keeps the running variance accurate without storing the scores
+	h.count++
+	delta := score - h.mean
+	h.mean += delta / float64(h.count)
+	h.m2 += delta * (score - h.mean)
+}
+
+// Merge counts every score counted by another histogram
+func (h *Histogram) Merge(other *Histogram) {
+	if other.count == 0 {
+		return
+	}
+
+	// The Fenwick trees of both histograms cover the same bins, so their nodes can be added together
+	for i := 1; i <= bins; i++ {
+		if n := other.node(i); n > 0 {
+			h.increment(i, n)
+		}
+	}
+
+	if h.count == 0 || other.lowest < h.lowest {
+		h.lowest = other.lowest
+	}
+	if h.count == 0 || other.highest > h.highest {
+		h.highest = other.highest
+	}
+	// The running means and variances are combined as in Chan et al.'s parallel form of Welford's method
+	count := h.count + other.count
+	delta := other.mean - h.mean
+	h.mean += delta * float64(other.count) / float64(count)
+	h.m2 += other.m2 + delta*delta*float64(h.count)*float64(other.count)/float64(count)
+	h.count = count
+}
+
+// Count returns the number of scores counted
+func (h *Histogram) Count() int64 {
+	return h.count
+}
+
+// Below returns the number of scores counted in bins below the bin of a score
+func (h *Histogram) Below(score float64) int64 {
+	below := int64(0)
+	for i := bin(score); i > 0; i -= i & -i {
+		below += h.node(i)
+	}
+	return below
+}
+
+// nth returns the score of the bin holding the nth lowest score, counting from 1
+func (h *Histogram) nth(n int64) float64 {
+	index := 0
+	for step := treeSpan; step > 0; step /= 2 {
+		next := index + step
+		if next <= bins && h.node(next) < n {
+			index = next
+			n -= h.node(next)
+		}
+	}
+	return float64(index) / Resolution
+}
+
+// Percentile returns the percentage of counted scores which a score is better than. Scores sharing its bin are
+// treated as equal to it and are not beaten.
+func (h *Histogram) Percentile(score float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return float64(h.Below(score)) / float64(h.count) * 100
+}
+
+// Stats returns summary statistics for the counted scores
+func (h *Histogram) Stats() Stats {
+	stats := Stats{Count: int(h.count), Histogram: make([]int, HistogramBuckets)}
+	if h.count == 0 {
+		return stats
+	}
+
+	stats.Mean = h.mean
+	stats.StdDev = math.Sqrt(h.m2 / float64(h.count))
+	stats.Lowest = h.lowest
+	stats.Highest = h.highest
+	stats.PassRate = float64(h.count-h.Below(PassMark)) / float64(h.count) * 100
+
+	stats.Median = h.nth((h.count + 1) / 2)
+	if h.count%2 == 0 {
+		stats.Median = (stats.Median + h.nth(h.count/2+1)) / 2
+	}
+
+	// The last bucket also holds perfect scores
+	width := 100 / HistogramBuckets
+	for i := range stats.Histogram {
+		upper := h.count
+		if i < HistogramBuckets-1 {
+			upper = h.Below(float64((i + 1) * width))
+		}
+		stats.Histogram[i] = int(upper - h.Below(float64(i*width)))
+	}
+
+	return stats
+}
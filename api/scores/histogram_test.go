@@ -0,0 +1,320 @@
+package scores
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exactPercentile is a helper function which compares a score against every stored score, as the score store did
+// before scores were counted in histograms
+func exactPercentile(scores []float64, score float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+
+	betterThanCount := 0
+	for _, existing := range scores {
+		if score > existing {
+			betterThanCount++
+		}
+	}
+	return float64(betterThanCount) / float64(len(scores)) * 100
+}
+
+// quizScore is a helper function which returns a random score of the form correct/questions, with half credit
+// allowed, for quizzes of up to 49 questions
+func quizScore(r *rand.Rand) float64 {
+	questions := r.Intn(49) + 1
+	credit := float64(r.Intn(2*questions+1)) / 2
+	return credit / float64(questions) * 100
+}
+
+// TestHistogramPercentile tests counting scores and comparing against them
+func TestHistogramPercentile(t *testing.T) {
+	histogram := NewHistogram()
+	assert.Equal(t, 0.0, histogram.Percentile(50), "Expected an empty histogram to be beaten by no score")
+
+	for _, score := range []float64{50.0, 60.0, 70.0, 80.0, 90.0} {
+		histogram.Add(score)
+	}
+
+	tests := []struct {
+		name     string
+		score    float64
+		expected float64
+	}{
+		{"better_than_some_scores", 75.0, 60.0},
+		{"equal_scores_are_not_beaten", 50.0, 0.0},
+		{"better_than_all_scores", 100.0, 100.0},
+		{"worse_than_all_scores", 0.0, 0.0},
+		{"just_above_a_score", 60.01, 40.0},
+		{"above_the_range", 150.0, 100.0},
+		{"below_the_range", -10.0, 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, histogram.Percentile(tt.score))
+		})
+	}
+
+	assert.Equal(t, int64(5), histogram.Count())
+}
+
+// TestHistogramStats tests the medians, pass rates and histogram buckets calculated from the bins
+func TestHistogramStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		scores   []float64
+		expected Stats
+	}{
+		{"success_empty", nil, Stats{Histogram: make([]int, HistogramBuckets)}},
+		{"success_odd_count", []float64{50, 90, 60, 80, 70}, Stats{Count: 5, Mean: 70, Median: 70, StdDev: 14.142135623730951, PassRate: 100, Lowest: 50, Highest: 90, Histogram: []int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1}}},
+		{"success_even_count", []float64{20, 40, 60, 100}, Stats{Count: 4, Mean: 55, Median: 50, StdDev: 29.58039891549808, PassRate: 50, Lowest: 20, Highest: 100, Histogram: []int{0, 0, 1, 0, 1, 0, 1, 0, 0, 1}}},
+		{"success_bucket_edges", []float64{0, 9.99, 10, 49.99, 50, 99.99, 100}, Stats{Count: 7, Mean: 45.71, Median: 49.99, StdDev: 38.86050437140516, PassRate: 42.857142857142854, Lowest: 0, Highest: 100, Histogram: []int{2, 1, 0, 0, 1, 1, 0, 0, 0, 2}}},
+		{"success_equal_scores", []float64{75, 75, 75}, Stats{Count: 3, Mean: 75, Median: 75, PassRate: 100, Lowest: 75, Highest: 75, Histogram: []int{0, 0, 0, 0, 0, 0, 0, 3, 0, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			histogram := NewHistogram()
+			for _, score := range tt.scores {
+				histogram.Add(score)
+			}
+			assert.Equal(t, tt.expected, histogram.Stats())
+		})
+	}
+}
+
+// TestHistogramMatchesExactPercentile checks that percentiles and statistics of quiz scores match those calculated
+// from every score
+func TestHistogramMatchesExactPercentile(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	histogram := NewHistogram()
+	scores := []float64{}
+
+	for i := 0; i < 20000; i++ {
+		score := quizScore(r)
+		histogram.Add(score)
+		scores = append(scores, score)
+
+		if i%97 == 0 {
+			query := quizScore(r)
+			if !assert.Equal(t, exactPercentile(scores, query), histogram.Percentile(query), "Percentile of %v after %d scores", query, i+1) {
+				return
+			}
+		}
+	}
+
+	exact := exactStats(scores)
+	stats := histogram.Stats()
+	assert.Equal(t, exact.Count, stats.Count)
+	assert.InDelta(t, exact.Mean, stats.Mean, 1e-9)
+	assert.InDelta(t, exact.StdDev, stats.StdDev, 1e-9)
+	assert.InDelta(t, exact.Median, stats.Median, 0.5/Resolution, "Expected the median to the nearest bin")
+	assert.Equal(t, exact.PassRate, stats.PassRate)
+	assert.Equal(t, exact.Lowest, stats.Lowest)
+	assert.Equal(t, exact.Highest, stats.Highest)
+	assert.Equal(t, exact.Histogram, stats.Histogram)
+}
+
+// exactStats is a helper function which calculates summary statistics from every score
+func exactStats(scores []float64) Stats {
+	sorted := append([]float64{}, scores...)
+	sort.Float64s(sorted)
+
+	stats := Stats{Count: len(sorted), Lowest: sorted[0], Highest: sorted[len(sorted)-1], Histogram: make([]int, HistogramBuckets)}
+	stats.Median = sorted[(len(sorted)-1)/2]
+	if len(sorted)%2 == 0 {
+		stats.Median = (stats.Median + sorted[len(sorted)/2]) / 2
+	}
+
+	total, passed := 0.0, 0
+	for _, score := range sorted {
+		total += score
+		if score >= PassMark {
+			passed++
+		}
+		bucket := int(score / (100 / HistogramBuckets))
+		if bucket == HistogramBuckets {
+			bucket--
+		}
+		stats.Histogram[bucket]++
+	}
+	stats.Mean = total / float64(len(sorted))
+	stats.PassRate = float64(passed) / float64(len(sorted)) * 100
+
+	squares := 0.0
+	for _, score := range sorted {
+		squares += (score - stats.Mean) * (score - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(squares / float64(len(sorted)))
+
+	return stats
+}
+
+// TestHistogramApproximatesContinuousScores checks that scores which can take any value, such as those with speed
+// bonuses, are compared to within the fraction of scores sharing the score's bin
+func TestHistogramApproximatesContinuousScores(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	histogram := NewHistogram()
+	scores := []float64{}
+	for i := 0; i < 100000; i++ {
+		score := r.Float64() * 100
+		histogram.Add(score)
+		scores = append(scores, score)
+	}
+
+	for i := 0; i < 100; i++ {
+		query := r.Float64() * 100
+		assert.InDelta(t, exactPercentile(scores, query), histogram.Percentile(query), 0.05)
+	}
+}
+
+// TestHistogramMemoryIsBounded checks that counting a score never allocates once the histogram is dense, however many
+// scores are counted
+func TestHistogramMemoryIsBounded(t *testing.T) {
+	histogram := NewHistogram()
+	for i := 0; i < bins; i++ {
+		histogram.Add(float64(i) / Resolution)
+	}
+	assert.NotNil(t, histogram.tree)
+
+	score := 0.0
+	allocs := testing.AllocsPerRun(100000, func() {
+		histogram.Add(score)
+		score += 0.37
+		if score > 100 {
+			score -= 100
+		}
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+// TestHistogramStaysSparse checks that a histogram holding few distinct scores tracks them without a dense array, and
+// gives the same results once it becomes dense
+func TestHistogramStaysSparse(t *testing.T) {
+	histogram := NewHistogram()
+	assert.Nil(t, histogram.sparse)
+	assert.Nil(t, histogram.tree)
+
+	scores := []float64{20, 45, 50, 50, 100}
+	for _, score := range scores {
+		histogram.Add(score)
+	}
+	assert.Nil(t, histogram.tree)
+	assert.LessOrEqual(t, len(histogram.sparse), len(scores)*14)
+
+	sparseStats := histogram.Stats()
+	sparsePercentiles := []float64{}
+	for _, query := range []float64{0, 45, 50, 75, 100} {
+		sparsePercentiles = append(sparsePercentiles, histogram.Percentile(query))
+		assert.Equal(t, exactPercentile(scores, query), histogram.Percentile(query))
+	}
+
+	// A copy switched to a dense array answers the same
+	dense := *histogram
+	dense.tree = make([]int64, bins+1)
+	for node, count := range histogram.sparse {
+		dense.tree[node] = count
+	}
+	dense.sparse = nil
+	assert.Equal(t, sparseStats, dense.Stats())
+	for i, query := range []float64{0, 45, 50, 75, 100} {
+		assert.Equal(t, sparsePercentiles[i], dense.Percentile(query))
+	}
+}
+
+// benchmarkSizes are the numbers of stored scores the percentile benchmarks compare against
+var benchmarkSizes = []int{1000, 100000, 1000000, 10000000}
+
+// BenchmarkHistogramPercentile measures percentile queries, which should take the same time at every size
+func BenchmarkHistogramPercentile(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("scores=%d", size), func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			store := NewMemoryStore()
+			store.AddCategory("science")
+			for i := 0; i < size; i++ {
+				store.Append("science", quizScore(r))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				store.Percentile("science", float64(i%101))
+			}
+		})
+	}
+}
+
+// BenchmarkHistogramAppend measures storing scores, which should take the same time at every size
+func BenchmarkHistogramAppend(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("scores=%d", size), func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			store := NewMemoryStore()
+			store.AddCategory("science")
+			for i := 0; i < size; i++ {
+				store.Append("science", quizScore(r))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				store.Append("science", float64(i%101))
+			}
+		})
+	}
+}
+
+// BenchmarkExactPercentile measures comparing against every stored score for reference, which grows with the size
+func BenchmarkExactPercentile(b *testing.B) {
+	for _, size := range benchmarkSizes[:3] {
+		b.Run(fmt.Sprintf("scores=%d", size), func(b *testing.B) {
+			r := rand.New(rand.NewSource(1))
+			scores := make([]float64, size)
+			for i := range scores {
+				scores[i] = quizScore(r)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				exactPercentile(scores, float64(i%101))
+			}
+		})
+	}
+}
+
+// TestHistogramMerge checks that a merged histogram matches one which counted the scores of both
+func TestHistogramMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	histogram, other := NewHistogram(), NewHistogram()
+	scores := []float64{}
+
+	for i := 0; i < 5000; i++ {
+		score := quizScore(r)
+		histogram.Add(score)
+		scores = append(scores, score)
+	}
+	for _, score := range []float64{0, 12.5, 100} {
+		other.Add(score)
+		scores = append(scores, score)
+	}
+
+	histogram.Merge(other)
+	histogram.Merge(NewHistogram())
+
+	exact := exactStats(scores)
+	stats := histogram.Stats()
+	assert.Equal(t, exact.Count, stats.Count)
+	assert.InDelta(t, exact.Mean, stats.Mean, 1e-9)
+	assert.InDelta(t, exact.StdDev, stats.StdDev, 1e-9)
+	assert.Equal(t, exact.Lowest, stats.Lowest)
+	assert.Equal(t, exact.Highest, stats.Highest)
+	assert.Equal(t, exact.Histogram, stats.Histogram)
+	assert.Equal(t, exactPercentile(scores, 50), histogram.Percentile(50))
+}
@@ -0,0 +1,164 @@
+package scores
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"quizwizard/api/models"
+)
+
+const (
+	// PeriodDay ranks the scores from the last 24 hours
+	PeriodDay = "day"
+	// PeriodWeek ranks the scores from the last 7 days
+	PeriodWeek = "week"
+	// PeriodMonth ranks the scores from the last 30 days
+	PeriodMonth = "month"
+	// PeriodAll ranks every score
+	PeriodAll = "all"
+)
+
+// Periods lists every leaderboard period
+var Periods = []string{PeriodDay, PeriodWeek, PeriodMonth, PeriodAll}
+
+// PeriodStart returns the time from which scores are ranked for a leaderboard period. Every score is ranked for PeriodAll.
+func PeriodStart(period string, now time.Time) (time.Time, error) {
+	switch period {
+	case PeriodDay:
+		return now.Add(-24 * time.Hour), nil
+	case PeriodWeek:
+		return now.AddDate(0, 0, -7), nil
+	case PeriodMonth:
+		return now.AddDate(0, 0, -30), nil
+	case PeriodAll:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("period '%s' must be one of %s", period, strings.Join(Periods, ", "))
+	}
+}
+
+// Entry represents the score a named player achieved for a quiz. Score is a percentage.
+type Entry struct {
+	Player      string    `json:"player"`
+	Category    string    `json:"category"`
+	Difficulty  string    `json:"difficulty,omitempty"`
+	Score       float64   `json:"score"`
+	ScoreString string    `json:"scoreString"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Ranking represents a player's best entry on a leaderboard along with their rank
+type Ranking struct {
+	Rank int `json:"rank"`
+	Entry
+}
+
+// Leaderboard stores the scores of named players and ranks them. Implementations must be safe for concurrent use.
+type Leaderboard interface {
+	// Record stores a new entry
+	Record(entry Entry) error
+	// Top ranks the best entry of each player for a category and its subcategories, or every category if it is empty,
+	// made since a time. Players with the same score share a rank, and players tied with the last place are included
+	// even if that exceeds the limit.
+	Top(category string, since time.Time, limit int) ([]Ranking, error)
+	// RenameCategory moves the entries of a category and its subcategories, alone or within a combination, to the new names
+	RenameCategory(name string, newName string) error
+}
+
+// Rank orders the best entry of each player by score, with earlier entries first among equal scores, and assigns
+// ranks so that tied players share a rank and the next rank is skipped, such as 1, 2, 2, 4. Players tied with the
+// last place are included even if that exceeds the limit.
+func Rank(entries []Entry, limit int) []Ranking {
+	best := make(map[string]Entry)
+	for _, entry := range entries {
+		current, ok := best[entry.Player]
+		if !ok || entry.Score > current.Score || (entry.Score == current.Score && entry.CreatedAt.Before(current.CreatedAt)) {
+			best[entry.Player] = entry
+		}
+	}
+
+	ordered := make([]Entry, 0, len(best))
+	for _, entry := range best {
+		ordered = append(ordered, entry)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Score != ordered[j].Score {
+			return ordered[i].Score > ordered[j].Score
+		}
+		if !ordered[i].CreatedAt.Equal(ordered[j].CreatedAt) {
+			return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+		}
+		return ordered[i].Player < ordered[j].Player
+	})
+
+	rankings := []Ranking{}
+	for i, entry := range ordered {
+		rank := i + 1
+		if i > 0 && entry.Score == ordered[i-1].Score {
+			rank = rankings[i-1].Rank
+		}
+
+		if i >= limit && rank != rankings[i-1].Rank {
+			break
+		}
+		rankings = append(rankings, Ranking{Rank: rank, Entry: entry})
+	}
+
+	return rankings
+}
+
+// MemoryLeaderboard is an in-memory Leaderboard guarded by a mutex
+type MemoryLeaderboard struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// NewMemoryLeaderboard creates an empty in-memory leaderboard
+func NewMemoryLeaderboard() *MemoryLeaderboard {
+	return &MemoryLeaderboard{}
+}
+
+// Record stores a new entry
+func (l *MemoryLeaderboard) Record(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+// Top ranks the best entry of each player for a category and its subcategories, or every category if it is empty,
+// made since a time
+func (l *MemoryLeaderboard) Top(category string, since time.Time, limit int) ([]Ranking, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	matching := []Entry{}
+	for _, entry := range l.entries {
+		if category != "" && !models.IsWithin(entry.Category, category) {
+			continue
+		}
+		if entry.CreatedAt.Before(since) {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+
+	return Rank(matching, limit), nil
+}
+
+// RenameCategory moves the entries of a category and its subcategories, alone or within a combination, to the new names
+func (l *MemoryLeaderboard) RenameCategory(name string, newName string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, entry := range l.entries {
+		if renamed, ok := RenameBucket(entry.Category, name, newName); ok {
+			l.entries[i].Category = renamed
+		}
+	}
+	return nil
+}
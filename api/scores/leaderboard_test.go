@@ -0,0 +1,118 @@
+package scores
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRank tests ordering and ranking the best entry of each player
+func TestRank(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Player: "ada", Score: 60, CreatedAt: start},
+		{Player: "ada", Score: 90, CreatedAt: start.Add(time.Minute)},
+		{Player: "bob", Score: 80, CreatedAt: start.Add(2 * time.Minute)},
+		{Player: "cy", Score: 80, CreatedAt: start.Add(time.Minute)},
+		{Player: "dee", Score: 40, CreatedAt: start},
+		{Player: "eve", Score: 80, CreatedAt: start.Add(3 * time.Minute)},
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected []string
+		ranks    []int
+	}{
+		{"success_ties_share_a_rank", 10, []string{"ada", "cy", "bob", "eve", "dee"}, []int{1, 2, 2, 2, 5}},
+		{"success_ties_with_last_place_are_included", 2, []string{"ada", "cy", "bob", "eve"}, []int{1, 2, 2, 2}},
+		{"success_limit_between_ranks", 1, []string{"ada"}, []int{1}},
+		{"success_limit_after_ties", 4, []string{"ada", "cy", "bob", "eve"}, []int{1, 2, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankings := Rank(entries, tt.limit)
+
+			players := []string{}
+			ranks := []int{}
+			for _, ranking := range rankings {
+				players = append(players, ranking.Player)
+				ranks = append(ranks, ranking.Rank)
+			}
+			assert.Equal(t, tt.expected, players)
+			assert.Equal(t, tt.ranks, ranks)
+		})
+	}
+
+	assert.Equal(t, 90.0, Rank(entries, 1)[0].Score, "Expected each player's best score to be ranked")
+	assert.Empty(t, Rank(nil, 10))
+}
+
+// TestMemoryLeaderboardTop checks that entries are filtered by category and period
+func TestMemoryLeaderboardTop(t *testing.T) {
+	now := time.Now()
+	leaderboard := NewMemoryLeaderboard()
+	assert.NoError(t, leaderboard.Record(Entry{Player: "ada", Category: "science", Score: 50, CreatedAt: now.Add(-time.Hour)}))
+	assert.NoError(t, leaderboard.Record(Entry{Player: "bob", Category: "science", Score: 70, CreatedAt: now.Add(-48 * time.Hour)}))
+	assert.NoError(t, leaderboard.Record(Entry{Player: "cy", Category: "music", Score: 90, CreatedAt: now}))
+	assert.NoError(t, leaderboard.Record(Entry{Player: "dee", Category: "science/physics", Score: 60, CreatedAt: now}))
+	assert.NoError(t, leaderboard.Record(Entry{Player: "eve", Category: "sciences", Score: 80, CreatedAt: now}))
+
+	tests := []struct {
+		name     string
+		category string
+		period   string
+		expected []string
+	}{
+		{"success_every_category", "", PeriodAll, []string{"cy", "eve", "bob", "dee", "ada"}},
+		{"success_parent_category", "science", PeriodAll, []string{"bob", "dee", "ada"}},
+		{"success_subcategory", "science/physics", PeriodAll, []string{"dee"}},
+		{"success_recent_scores", "science", PeriodDay, []string{"dee", "ada"}},
+		{"success_empty_category", "history", PeriodAll, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, err := PeriodStart(tt.period, now)
+			assert.NoError(t, err)
+
+			rankings, err := leaderboard.Top(tt.category, since, 10)
+			assert.NoError(t, err)
+
+			players := []string{}
+			for _, ranking := range rankings {
+				players = append(players, ranking.Player)
+			}
+			assert.Equal(t, tt.expected, players)
+		})
+	}
+
+	_, err := PeriodStart("year", now)
+	assert.EqualError(t, err, "period 'year' must be one of day, week, month, all")
+}
+
+// TestMemoryLeaderboardRenameCategory checks that renaming a category moves the entries of its subcategories and
+// combinations
+func TestMemoryLeaderboardRenameCategory(t *testing.T) {
+	leaderboard := NewMemoryLeaderboard()
+	for _, category := range []string{"science", "science/physics", "music+science", "sciences"} {
+		assert.NoError(t, leaderboard.Record(Entry{Player: category, Category: category, Score: 50}))
+	}
+
+	assert.NoError(t, leaderboard.RenameCategory("science", "nature"))
+
+	rankings, err := leaderboard.Top("", time.Time{}, 10)
+	assert.NoError(t, err)
+	categories := map[string]string{}
+	for _, ranking := range rankings {
+		categories[ranking.Player] = ranking.Category
+	}
+	assert.Equal(t, map[string]string{
+		"science":         "nature",
+		"science/physics": "nature/physics",
+		"music+science":   "music+nature",
+		"sciences":        "sciences",
+	}, categories)
+}
@@ -0,0 +1,220 @@
+package scores
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"quizwizard/api/models"
+)
+
+// ErrCategoryNotFound is returned when a category has no score bucket
+var ErrCategoryNotFound = errors.New("category does not exist")
+
+// Combination returns the name of the score bucket for a quiz drawn from several categories, such as "computing+music"
+func Combination(categories []string) string {
+	sorted := append([]string{}, categories...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "+")
+}
+
+// SplitCombination returns the categories within a category combination. A single category is returned on its own.
+func SplitCombination(combination string) []string {
+	return strings.Split(combination, "+")
+}
+
+// Bucket returns the name of the score bucket for a category and difficulty.
+// Quizzes without a difficulty are compared against the whole category.
+func Bucket(category string, difficulty string) string {
+	if difficulty == "" {
+		return category
+	}
+	return category + ":" + difficulty
+}
+
+// RenameBucket returns the name a score bucket takes once a category and its subcategories are renamed, and reports
+// whether the bucket holds the scores of any of them, alone or within a combination
+func RenameBucket(bucket string, name string, newName string) (string, bool) {
+	combination, difficulty, _ := strings.Cut(bucket, ":")
+
+	categories := SplitCombination(combination)
+	renamed := false
+	for i, category := range categories {
+		if models.IsWithin(category, name) {
+			categories[i] = newName + strings.TrimPrefix(category, name)
+			renamed = true
+		}
+	}
+	if !renamed {
+		return bucket, false
+	}
+
+	return Bucket(Combination(categories), difficulty), true
+}
+
+const (
+	// PassMark is the lowest percentage score which passes a quiz
+	PassMark = 50.0
+	// HistogramBuckets is the number of equal ranges scores are counted in for statistics
+	HistogramBuckets = 10
+)
+
+// Stats represents summary statistics for the scores of a category. PassRate is the percentage of scores of at least
+// PassMark, and Histogram counts the scores in each tenth of the range from 0% to 100%, with perfect scores in the last.
+type Stats struct {
+	Count     int     `json:"count"`
+	Mean      float64 `json:"mean"`
+	Median    float64 `json:"median"`
+	StdDev    float64 `json:"stdDev"`
+	PassRate  float64 `json:"passRate"`
+	Lowest    float64 `json:"lowest"`
+	Highest   float64 `json:"highest"`
+	Histogram []int   `json:"histogram"`
+}
+
+// ScoreStore stores percentage scores for each category. Implementations must be safe for concurrent use.
+type ScoreStore interface {
+	// Append stores a new score for a category
+	Append(category string, score float64) error
+	// Percentile returns the percentage of stored scores for a category which a score is better than
+	Percentile(category string, score float64) (float64, error)
+	// Stats returns summary statistics for the scores of a category
+	Stats(category string) (Stats, error)
+	// Reset removes every score and creates an empty bucket for each of the specified categories
+	Reset(categories []string) error
+	// AddCategory creates an empty bucket for a category if one does not already exist
+	AddCategory(category string)
+	// HasCategory reports whether a category has a score bucket
+	HasCategory(category string) bool
+	// Categories returns the sorted names of every category with a score bucket
+	Categories() []string
+	// RenameCategory moves the scores of a category and its subcategories, alone or within a combination, to the new
+	// names. Scores moved to a bucket which already exists are counted alongside its own.
+	RenameCategory(name string, newName string) error
+}
+
+// MemoryStore is an in-memory ScoreStore guarded by a mutex. Each category's scores are counted in a Histogram,
+// so memory use grows with the number of categories rather than the number of scores, and buckets holding few
+// distinct scores stay small.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	scores map[string]*Histogram
+}
+
+// NewMemoryStore creates an empty in-memory score store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		scores: make(map[string]*Histogram),
+	}
+}
+
+// Append stores a new score for a category
+func (s *MemoryStore) Append(category string, score float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	histogram, ok := s.scores[category]
+	if !ok {
+		return ErrCategoryNotFound
+	}
+
+	histogram.Add(score)
+	return nil
+}
+
+// Percentile returns the percentage of stored scores for a category which a score is better than
+func (s *MemoryStore) Percentile(category string, score float64) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	histogram, ok := s.scores[category]
+	if !ok {
+		return 0, ErrCategoryNotFound
+	}
+
+	return histogram.Percentile(score), nil
+}
+
+// Stats returns summary statistics for the scores of a category
+func (s *MemoryStore) Stats(category string) (Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	histogram, ok := s.scores[category]
+	if !ok {
+		return Stats{}, ErrCategoryNotFound
+	}
+
+	return histogram.Stats(), nil
+}
+
+// Reset removes every score and creates an empty bucket for each of the specified categories
+func (s *MemoryStore) Reset(categories []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scores = make(map[string]*Histogram, len(categories))
+	for _, category := range categories {
+		s.scores[category] = NewHistogram()
+	}
+
+	return nil
+}
+
+// AddCategory creates an empty bucket for a category if one does not already exist
+func (s *MemoryStore) AddCategory(category string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.scores[category]; !ok {
+		s.scores[category] = NewHistogram()
+	}
+}
+
+// HasCategory reports whether a category has a score bucket
+func (s *MemoryStore) HasCategory(category string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.scores[category]
+	return ok
+}
+
+// Categories returns the sorted names of every category with a score bucket
+func (s *MemoryStore) Categories() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	categories := make([]string, 0, len(s.scores))
+	for category := range s.scores {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	return categories
+}
+
+// RenameCategory moves the scores of a category and its subcategories, alone or within a combination, to the new names
+func (s *MemoryStore) RenameCategory(name string, newName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	moved := make(map[string]*Histogram)
+	for bucket, histogram := range s.scores {
+		if renamed, ok := RenameBucket(bucket, name, newName); ok {
+			delete(s.scores, bucket)
+			moved[renamed] = histogram
+		}
+	}
+
+	for bucket, histogram := range moved {
+		if existing, ok := s.scores[bucket]; ok {
+			existing.Merge(histogram)
+			continue
+		}
+		s.scores[bucket] = histogram
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package scores
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryStorePercentile tests the Percentile method of the in-memory score store
+func TestMemoryStorePercentile(t *testing.T) {
+	store := NewMemoryStore()
+	assert.NoError(t, store.Reset([]string{"science", "music"}))
+	for _, score := range []float64{50.0, 60.0, 70.0, 80.0, 90.0} {
+		assert.NoError(t, store.Append("science", score))
+	}
+
+	tests := []struct {
+		name          string
+		category      string
+		score         float64
+		expected      float64
+		expectedError error
+	}{
+		{"better_than_some_scores", "science", 75.0, 60.0, nil},
+		{"equal_scores_are_not_beaten", "science", 50.0, 0.0, nil},
+		{"better_than_all_scores", "science", 100.0, 100.0, nil},
+		{"empty_category", "music", 60.0, 0.0, nil},
+		{"unknown_category", "history", 60.0, 0.0, ErrCategoryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := store.Percentile(tt.category, tt.score)
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestMemoryStoreStats tests the Stats method of the in-memory score store
+func TestMemoryStoreStats(t *testing.T) {
+	store := NewMemoryStore()
+	assert.NoError(t, store.Reset([]string{"science", "music"}))
+	for _, score := range []float64{40.0, 100.0, 70.0} {
+		assert.NoError(t, store.Append("science", score))
+	}
+
+	stats, err := store.Stats("science")
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Count: 3, Mean: 70.0, Median: 70.0, StdDev: 24.49489742783178, PassRate: 66.66666666666666, Lowest: 40.0, Highest: 100.0, Histogram: []int{0, 0, 0, 0, 1, 0, 0, 1, 0, 1}}, stats)
+
+	stats, err = store.Stats("music")
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Histogram: make([]int, HistogramBuckets)}, stats)
+
+	_, err = store.Stats("history")
+	assert.Equal(t, ErrCategoryNotFound, err)
+}
+
+// TestMemoryStoreCategories tests creating, resetting and listing score buckets
+func TestMemoryStoreCategories(t *testing.T) {
+	store := NewMemoryStore()
+	assert.NoError(t, store.Reset([]string{"science", "music"}))
+	assert.NoError(t, store.Append("science", 50.0))
+
+	store.AddCategory("science")
+	store.AddCategory("history")
+
+	assert.Equal(t, []string{"history", "music", "science"}, store.Categories())
+	assert.True(t, store.HasCategory("history"))
+	assert.False(t, store.HasCategory("random"))
+
+	stats, _ := store.Stats("science")
+	assert.Equal(t, 1, stats.Count, "Adding an existing category should keep its scores")
+
+	assert.Equal(t, ErrCategoryNotFound, store.Append("random", 50.0))
+
+	assert.NoError(t, store.Reset([]string{"random"}))
+	assert.Equal(t, []string{"random"}, store.Categories())
+}
+
+// TestMemoryStoreConcurrentAccess hammers the store from many goroutines. Run with -race to detect data races.
+func TestMemoryStoreConcurrentAccess(t *testing.T) {
+	store := NewMemoryStore()
+	assert.NoError(t, store.Reset([]string{"science"}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				store.Append("science", float64((i+j)%101))
+				store.Percentile("science", 50.0)
+				store.Stats("science")
+				store.AddCategory("music")
+				store.Categories()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats, err := store.Stats("science")
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, stats.Count)
+}
+
+// TestBucket checks that each difficulty has its own bucket within a category
+func TestBucket(t *testing.T) {
+	assert.Equal(t, "science", Bucket("science", ""))
+	assert.Equal(t, "science:hard", Bucket("science", "hard"))
+}
+
+// TestRenameBucket checks that buckets are renamed for a category and its subcategories, alone or within a combination
+func TestRenameBucket(t *testing.T) {
+	tests := []struct {
+		bucket   string
+		expected string
+		renamed  bool
+	}{
+		{"science", "nature", true},
+		{"science:hard", "nature:hard", true},
+		{"science/physics", "nature/physics", true},
+		{"computing+science/physics:easy", "computing+nature/physics:easy", true},
+		{"music+science", "music+nature", true},
+		{"sciences", "sciences", false},
+		{"music", "music", false},
+	}
+
+	for _, tt := range tests {
+		renamed, ok := RenameBucket(tt.bucket, "science", "nature")
+		assert.Equal(t, tt.expected, renamed, tt.bucket)
+		assert.Equal(t, tt.renamed, ok, tt.bucket)
+	}
+
+	// Renamed categories keep combinations in order
+	renamed, _ := RenameBucket("music+science", "science", "art")
+	assert.Equal(t, "art+music", renamed)
+}
+
+// TestMemoryStoreRenameCategory checks that renaming a category moves its scores, counting them alongside any scores
+// already held under the new name
+func TestMemoryStoreRenameCategory(t *testing.T) {
+	store := NewMemoryStore()
+	assert.NoError(t, store.Reset([]string{"science", "science/physics:hard", "nature", "music"}))
+	assert.NoError(t, store.Append("science", 40))
+	assert.NoError(t, store.Append("science/physics:hard", 60))
+	assert.NoError(t, store.Append("nature", 80))
+
+	assert.NoError(t, store.RenameCategory("science", "nature"))
+
+	assert.Equal(t, []string{"music", "nature", "nature/physics:hard"}, store.Categories())
+	stats, err := store.Stats("nature")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	stats, err = store.Stats("nature/physics:hard")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Count)
+}
+
+// TestCombination checks that a combination of categories has a single bucket regardless of their order
+func TestCombination(t *testing.T) {
+	assert.Equal(t, "computing+music", Combination([]string{"music", "computing"}))
+	assert.Equal(t, "music", Combination([]string{"music"}))
+	assert.Equal(t, []string{"computing", "music"}, SplitCombination("computing+music"))
+	assert.Equal(t, []string{"music"}, SplitCombination("music"))
+	assert.Equal(t, "computing+music:hard", Bucket(Combination([]string{"music", "computing"}), "hard"))
+}
@@ -0,0 +1,256 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"quizwizard/api/models"
+)
+
+const (
+	// StrategyStandard awards one point for each question, scaled by the credit earned
+	StrategyStandard = "standard"
+	// StrategyWeighted awards the points set for each question, scaled by the credit earned
+	StrategyWeighted = "weighted"
+	// StrategyNegative deducts points for wrong answers, but not for skipped questions
+	StrategyNegative = "negative"
+	// StrategySpeed awards bonus points for answering quickly
+	StrategySpeed = "speed"
+	// StrategyStreak multiplies the points earned by consecutive correct answers
+	StrategyStreak = "streak"
+)
+
+const (
+	// NegativePenalty is the fraction of a question's points deducted for a wrong answer
+	NegativePenalty = 0.25
+	// SpeedBonus is the fraction of the points earned for a question which is added for an instant answer.
+	// The bonus shrinks evenly to nothing as the time taken reaches the question's time limit, or SpeedWindow if it is untimed.
+	SpeedBonus = 0.5
+	// SpeedWindow is the time within which an answer to an untimed question earns a speed bonus
+	SpeedWindow = 30 * time.Second
+	// StreakStep is the amount the multiplier grows by for each consecutive correct answer after the first
+	StreakStep = 0.1
+	// StreakMax is the largest multiplier a streak can reach
+	StreakMax = 2.0
+)
+
+// Answer describes a marked answer to a single question. Credit is between 0 and 1 and skipped questions
+// were not answered at all. TimeTaken is only known for answers checked during the quiz and is otherwise 0.
+type Answer struct {
+	QuestionID int
+	Category   string
+	Weight     float64
+	Credit     float64
+	Skipped    bool
+	TimeTaken  time.Duration
+	TimeLimit  time.Duration
+}
+
+// Points breaks down how the points for a single answer were earned
+type Points struct {
+	QuestionID  int     `json:"questionId"`
+	Category    string  `json:"category"`
+	Available   float64 `json:"available"`
+	Earned      float64 `json:"earned"`
+	Penalty     float64 `json:"penalty,omitempty"`
+	SpeedBonus  float64 `json:"speedBonus,omitempty"`
+	StreakBonus float64 `json:"streakBonus,omitempty"`
+	Total       float64 `json:"total"`
+}
+
+// Scorer is a scoring strategy which awards points for the answers to a quiz.
+// Answers are given in the order their questions were asked and points are returned in the same order.
+type Scorer interface {
+	Score(answers []Answer) []Points
+}
+
+// Standard awards one point for each question, scaled by the credit earned
+type Standard struct{}
+
+// Score implements Scorer
+func (Standard) Score(answers []Answer) []Points {
+	points := make([]Points, len(answers))
+	for i, answer := range answers {
+		points[i] = Points{QuestionID: answer.QuestionID, Category: answer.Category, Available: 1, Earned: answer.Credit, Total: answer.Credit}
+	}
+	return points
+}
+
+// Weighted awards the weight of each question, scaled by the credit earned
+type Weighted struct{}
+
+// Score implements Scorer
+func (Weighted) Score(answers []Answer) []Points {
+	points := make([]Points, len(answers))
+	for i, answer := range answers {
+		earned := answer.Weight * answer.Credit
+		points[i] = Points{QuestionID: answer.QuestionID, Category: answer.Category, Available: answer.Weight, Earned: earned, Total: earned}
+	}
+	return points
+}
+
+// NegativeMarking deducts a fraction of the available points for each answer which earned no credit.
+// Skipped questions, including those which ran out of time, are not penalised.
+type NegativeMarking struct {
+	Scorer  Scorer
+	Penalty float64
+}
+
+// Score implements Scorer
+func (n NegativeMarking) Score(answers []Answer) []Points {
+	points := n.Scorer.Score(answers)
+	for i, answer := range answers {
+		if answer.Skipped || answer.Credit > 0 {
+			continue
+		}
+		points[i].Penalty = n.Penalty * points[i].Available
+		points[i].Total -= points[i].Penalty
+	}
+	return points
+}
+
+// SpeedBonuses adds a bonus for correct answers which were given quickly. The bonus is a fraction of the points earned,
+// which shrinks evenly to nothing as the time taken reaches the question's time limit, or the window if it is untimed.
+type SpeedBonuses struct {
+	Scorer Scorer
+	Bonus  float64
+	Window time.Duration
+}
+
+// Score implements Scorer
+func (s SpeedBonuses) Score(answers []Answer) []Points {
+	points := s.Scorer.Score(answers)
+	for i, answer := range answers {
+		if answer.Credit <= 0 || answer.TimeTaken <= 0 {
+			continue
+		}
+
+		window := s.Window
+		if answer.TimeLimit > 0 {
+			window = answer.TimeLimit
+		}
+
+		remaining := 1 - answer.TimeTaken.Seconds()/window.Seconds()
+		if remaining <= 0 {
+			continue
+		}
+		points[i].SpeedBonus = s.Bonus * points[i].Earned * remaining
+		points[i].Total += points[i].SpeedBonus
+	}
+	return points
+}
+
+// StreakMultiplier multiplies the points earned by consecutive correct answers. The first correct answer of a streak
+// earns its usual points and each one after it raises the multiplier by the step, up to the maximum.
+// Answers which are wrong, partially correct or skipped end the streak.
+type StreakMultiplier struct {
+	Scorer Scorer
+	Step   float64
+	Max    float64
+}
+
+// Score implements Scorer
+func (s StreakMultiplier) Score(answers []Answer) []Points {
+	points := s.Scorer.Score(answers)
+	streak := 0
+	for i, answer := range answers {
+		if answer.Skipped || answer.Credit < 1 {
+			streak = 0
+			continue
+		}
+
+		extra := math.Min(s.Step*float64(streak), s.Max-1)
+		streak++
+
+		points[i].StreakBonus = points[i].Earned * extra
+		points[i].Total += points[i].StreakBonus
+	}
+	return points
+}
+
+// Parse builds a scorer from a strategy such as "weighted+negative+streak". Points are awarded by either the standard
+// or the weighted strategy, which defaults to standard, and then adjusted by negative marking, speed bonuses and
+// streak multipliers in that order, whichever order they are written in.
+func Parse(strategy string) (Scorer, error) {
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(strategy, "+") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case StrategyStandard, StrategyWeighted, StrategyNegative, StrategySpeed, StrategyStreak:
+		default:
+			return nil, fmt.Errorf("scoring strategy '%s' does not exist", name)
+		}
+
+		if selected[name] {
+			return nil, fmt.Errorf("scoring strategy '%s' is listed more than once", name)
+		}
+		selected[name] = true
+	}
+
+	if selected[StrategyStandard] && selected[StrategyWeighted] {
+		return nil, fmt.Errorf("scoring strategies '%s' and '%s' cannot be combined", StrategyStandard, StrategyWeighted)
+	}
+
+	var scorer Scorer = Standard{}
+	if selected[StrategyWeighted] {
+		scorer = Weighted{}
+	}
+	if selected[StrategyNegative] {
+		scorer = NegativeMarking{Scorer: scorer, Penalty: NegativePenalty}
+	}
+	if selected[StrategySpeed] {
+		scorer = SpeedBonuses{Scorer: scorer, Bonus: SpeedBonus, Window: SpeedWindow}
+	}
+	if selected[StrategyStreak] {
+		scorer = StreakMultiplier{Scorer: scorer, Step: StreakStep, Max: StreakMax}
+	}
+
+	return scorer, nil
+}
+
+// Strategies selects the scoring strategy for each category. Categories without a strategy of their own use the strategy
+// of their nearest parent category with one, or else the default. An empty default is the standard strategy.
+type Strategies struct {
+	Default    string            `json:"default"`
+	Categories map[string]string `json:"categories"`
+}
+
+// For returns the scoring strategy for a category
+func (s Strategies) For(category string) string {
+	for _, level := range models.CategoryLevels(category) {
+		if strategy, ok := s.Categories[level]; ok {
+			return strategy
+		}
+	}
+	return s.defaultStrategy()
+}
+
+// defaultStrategy returns the scoring strategy for categories without one of their own
+func (s Strategies) defaultStrategy() string {
+	if s.Default == "" {
+		return StrategyStandard
+	}
+	return s.Default
+}
+
+// Scorer returns the scorer for a category
+func (s Strategies) Scorer(category string) (Scorer, error) {
+	return Parse(s.For(category))
+}
+
+// Validate checks that every strategy can be parsed
+func (s Strategies) Validate() error {
+	if _, err := Parse(s.defaultStrategy()); err != nil {
+		return fmt.Errorf("the default scoring strategy is invalid: %w", err)
+	}
+
+	for category, strategy := range s.Categories {
+		if _, err := Parse(strategy); err != nil {
+			return fmt.Errorf("the scoring strategy for category '%s' is invalid: %w", category, err)
+		}
+	}
+
+	return nil
+}
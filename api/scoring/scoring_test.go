@@ -0,0 +1,186 @@
+package scoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getTestAnswers is a helper function which returns a correct, a wrong, a skipped, a partially correct and two more correct answers
+func getTestAnswers() []Answer {
+	return []Answer{
+		{QuestionID: 1, Category: "science", Weight: 2, Credit: 1, TimeTaken: 5 * time.Second},
+		{QuestionID: 2, Category: "science", Weight: 1, Credit: 0},
+		{QuestionID: 3, Category: "science", Weight: 4, Skipped: true},
+		{QuestionID: 4, Category: "science", Weight: 2, Credit: 0.5},
+		{QuestionID: 5, Category: "science", Weight: 1, Credit: 1, TimeTaken: 15 * time.Second, TimeLimit: 20 * time.Second},
+		{QuestionID: 6, Category: "science", Weight: 1, Credit: 1, TimeTaken: time.Minute},
+	}
+}
+
+// TestScorers tests the points awarded by each scoring strategy
+func TestScorers(t *testing.T) {
+	tests := []struct {
+		name     string
+		scorer   Scorer
+		expected []Points
+	}{
+		{
+			name:   "success_standard",
+			scorer: Standard{},
+			expected: []Points{
+				{QuestionID: 1, Category: "science", Available: 1, Earned: 1, Total: 1},
+				{QuestionID: 2, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 3, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 4, Category: "science", Available: 1, Earned: 0.5, Total: 0.5},
+				{QuestionID: 5, Category: "science", Available: 1, Earned: 1, Total: 1},
+				{QuestionID: 6, Category: "science", Available: 1, Earned: 1, Total: 1},
+			},
+		},
+		{
+			name:   "success_weighted",
+			scorer: Weighted{},
+			expected: []Points{
+				{QuestionID: 1, Category: "science", Available: 2, Earned: 2, Total: 2},
+				{QuestionID: 2, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 3, Category: "science", Available: 4, Earned: 0, Total: 0},
+				{QuestionID: 4, Category: "science", Available: 2, Earned: 1, Total: 1},
+				{QuestionID: 5, Category: "science", Available: 1, Earned: 1, Total: 1},
+				{QuestionID: 6, Category: "science", Available: 1, Earned: 1, Total: 1},
+			},
+		},
+		{
+			name:   "success_negative_marking_spares_skipped_questions",
+			scorer: NegativeMarking{Scorer: Weighted{}, Penalty: 0.5},
+			expected: []Points{
+				{QuestionID: 1, Category: "science", Available: 2, Earned: 2, Total: 2},
+				{QuestionID: 2, Category: "science", Available: 1, Earned: 0, Penalty: 0.5, Total: -0.5},
+				{QuestionID: 3, Category: "science", Available: 4, Earned: 0, Total: 0},
+				{QuestionID: 4, Category: "science", Available: 2, Earned: 1, Total: 1},
+				{QuestionID: 5, Category: "science", Available: 1, Earned: 1, Total: 1},
+				{QuestionID: 6, Category: "science", Available: 1, Earned: 1, Total: 1},
+			},
+		},
+		{
+			name:   "success_speed_bonus_uses_time_limit_or_window",
+			scorer: SpeedBonuses{Scorer: Standard{}, Bonus: 0.5, Window: 10 * time.Second},
+			expected: []Points{
+				{QuestionID: 1, Category: "science", Available: 1, Earned: 1, SpeedBonus: 0.25, Total: 1.25},
+				{QuestionID: 2, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 3, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 4, Category: "science", Available: 1, Earned: 0.5, Total: 0.5},
+				{QuestionID: 5, Category: "science", Available: 1, Earned: 1, SpeedBonus: 0.125, Total: 1.125},
+				{QuestionID: 6, Category: "science", Available: 1, Earned: 1, Total: 1},
+			},
+		},
+		{
+			name:   "success_streak_resets_after_incorrect_answers",
+			scorer: StreakMultiplier{Scorer: Standard{}, Step: 0.5, Max: 1.5},
+			expected: []Points{
+				{QuestionID: 1, Category: "science", Available: 1, Earned: 1, Total: 1},
+				{QuestionID: 2, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 3, Category: "science", Available: 1, Earned: 0, Total: 0},
+				{QuestionID: 4, Category: "science", Available: 1, Earned: 0.5, Total: 0.5},
+				{QuestionID: 5, Category: "science", Available: 1, Earned: 1, Total: 1},
+				{QuestionID: 6, Category: "science", Available: 1, Earned: 1, StreakBonus: 0.5, Total: 1.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.scorer.Score(getTestAnswers()))
+		})
+	}
+}
+
+// TestStreakMultiplierMaximum checks that a long streak stops growing at the maximum multiplier
+func TestStreakMultiplierMaximum(t *testing.T) {
+	answers := []Answer{{QuestionID: 1, Credit: 1}, {QuestionID: 2, Credit: 1}, {QuestionID: 3, Credit: 1}, {QuestionID: 4, Credit: 1}}
+
+	points := StreakMultiplier{Scorer: Standard{}, Step: 0.5, Max: 2}.Score(answers)
+
+	bonuses := []float64{}
+	for _, p := range points {
+		bonuses = append(bonuses, p.StreakBonus)
+	}
+	assert.Equal(t, []float64{0, 0.5, 1, 1}, bonuses)
+}
+
+// TestParse tests building scorers from strategies
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		strategy      string
+		expected      Scorer
+		expectedError string
+	}{
+		{"success_standard", "standard", Standard{}, ""},
+		{"success_weighted", " Weighted ", Weighted{}, ""},
+		{"success_negative_defaults_to_standard", "negative", NegativeMarking{Scorer: Standard{}, Penalty: NegativePenalty}, ""},
+		{
+			"success_strategies_are_applied_in_a_fixed_order",
+			"streak+speed+negative+weighted",
+			StreakMultiplier{
+				Scorer: SpeedBonuses{
+					Scorer: NegativeMarking{Scorer: Weighted{}, Penalty: NegativePenalty},
+					Bonus:  SpeedBonus,
+					Window: SpeedWindow,
+				},
+				Step: StreakStep,
+				Max:  StreakMax,
+			},
+			"",
+		},
+		{"failure_unknown_strategy", "weighted+bonus", nil, "scoring strategy 'bonus' does not exist"},
+		{"failure_empty_strategy", "", nil, "scoring strategy '' does not exist"},
+		{"failure_repeated_strategy", "speed+speed", nil, "scoring strategy 'speed' is listed more than once"},
+		{"failure_standard_and_weighted", "standard+weighted", nil, "scoring strategies 'standard' and 'weighted' cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer, err := Parse(tt.strategy)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, scorer)
+			}
+		})
+	}
+}
+
+// TestStrategies checks that categories use their own strategy, falling back to the default
+func TestStrategies(t *testing.T) {
+	strategies := Strategies{Default: "weighted", Categories: map[string]string{"music": "negative+streak", "music/jazz": "speed"}}
+
+	assert.Equal(t, "negative+streak", strategies.For("music"))
+	assert.Equal(t, "negative+streak", strategies.For("music/classical/baroque"))
+	assert.Equal(t, "speed", strategies.For("music/jazz"))
+	assert.Equal(t, "weighted", strategies.For("science"))
+	assert.Equal(t, StrategyStandard, Strategies{}.For("science"))
+
+	tests := []struct {
+		name          string
+		strategies    Strategies
+		expectedError string
+	}{
+		{"success_valid_strategies", strategies, ""},
+		{"success_no_strategies", Strategies{}, ""},
+		{"failure_invalid_default", Strategies{Default: "fast"}, "the default scoring strategy is invalid: scoring strategy 'fast' does not exist"},
+		{"failure_invalid_category_strategy", Strategies{Categories: map[string]string{"music": "streak+streak"}}, "the scoring strategy for category 'music' is invalid: scoring strategy 'streak' is listed more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.strategies.Validate()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"quizwizard/api/models"
+)
+
+var (
+	// ErrSessionNotFound is returned when a session does not exist or has expired
+	ErrSessionNotFound = errors.New("quiz session was not found or has expired")
+	// ErrQuestionNotIssued is returned when a question was not issued for a session
+	ErrQuestionNotIssued = errors.New("question was not issued for this quiz session")
+	// ErrAlreadyAnswered is returned when a question has already been answered within a session
+	ErrAlreadyAnswered = errors.New("question has already been answered")
+	// ErrTimeExpired is returned when an answer arrives after its question's time limit, in which case a blank answer is locked in
+	ErrTimeExpired = errors.New("the time limit for the question has passed")
+)
+
+// The parts of a submission which are saved separately. Each part is recorded against its session once it has been
+// saved, so that a retried submission does not save it again.
+const (
+	PartScore       = "score"
+	PartLeaderboard = "leaderboard"
+	PartResult      = "result"
+)
+
+// Store holds the quiz sessions which have been issued and are awaiting submission
+type Store struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]models.QuizSession
+	// claimed holds the IDs of the sessions which are being submitted
+	claimed map[string]bool
+}
+
+// NewStore creates an empty session store. Sessions expire once they are older than the ttl.
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:      ttl,
+		sessions: make(map[string]models.QuizSession),
+		claimed:  make(map[string]bool),
+	}
+}
+
+// Create issues a new quiz session for the specified category, difficulty and questions.
+// The answer orders record how each question's answers were shuffled and may be nil if they were not,
+// and the time limits hold the seconds allowed for each timed question and may be nil if none are timed.
+// The username is the user who started the quiz, or empty if they did not log in.
+func (s *Store) Create(category string, difficulty string, questions models.Questions, answerOrders map[int][]int, timeLimits map[int]int, username string) (models.QuizSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return models.QuizSession{}, err
+	}
+
+	questionIDs := make([]int, len(questions))
+	for i, question := range questions {
+		questionIDs[i] = question.ID
+	}
+
+	session := models.QuizSession{
+		ID:           id,
+		Username:     username,
+		Category:     category,
+		Difficulty:   difficulty,
+		QuestionIDs:  questionIDs,
+		AnswerOrders: answerOrders,
+		Answers:      make(map[int]models.QuestionResponse),
+		TimeLimits:   timeLimits,
+		AnswerTimes:  make(map[int]time.Duration),
+		AnsweredAt:   make(map[int]time.Time),
+		Saved:        make(map[string]bool),
+		CreatedAt:    time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneExpired(session.CreatedAt)
+	s.sessions[id] = session
+
+	return copySession(session), nil
+}
+
+// Get retrieves an active quiz session by its ID
+func (s *Store) Get(id string) (models.QuizSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || s.expired(session, time.Now()) {
+		return models.QuizSession{}, false
+	}
+
+	return copySession(session), true
+}
+
+// RecordAnswer locks in the answer given for a question once it has been checked. If the answer arrives after
+// the question's time limit, a blank answer is locked in instead and ErrTimeExpired is returned.
+func (s *Store) RecordAnswer(id string, response models.QuestionResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	session, ok := s.sessions[id]
+	if !ok || s.expired(session, now) {
+		return ErrSessionNotFound
+	}
+
+	if !session.HasQuestion(response.QuestionID) {
+		return ErrQuestionNotIssued
+	}
+
+	if _, answered := session.Answers[response.QuestionID]; answered {
+		return ErrAlreadyAnswered
+	}
+
+	deadline, timed := session.Deadline(response.QuestionID)
+	late := timed && now.After(deadline.Add(models.AnswerGracePeriod))
+	if late {
+		response = models.BlankResponse(response.QuestionID)
+	}
+
+	session.Answers[response.QuestionID] = response
+	session.AnswerTimes[response.QuestionID] = session.Elapsed(now)
+	session.AnsweredAt[response.QuestionID] = now
+	session.LastAnsweredAt = now
+	s.sessions[id] = session
+
+	if late {
+		return ErrTimeExpired
+	}
+	return nil
+}
+
+// Claim marks an active quiz session as being submitted and returns it, along with the parts of its submission which
+// were saved by earlier attempts. Only one caller can claim a session until it is released, so that it is only
+// submitted once.
+func (s *Store) Claim(id string) (models.QuizSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || s.expired(session, time.Now()) || s.claimed[id] {
+		return models.QuizSession{}, false
+	}
+	s.claimed[id] = true
+
+	return copySession(session), true
+}
+
+// MarkSaved records that part of a claimed quiz session's submission has been saved
+func (s *Store) MarkSaved(id string, part string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok {
+		session.Saved[part] = true
+	}
+}
+
+// Release allows a claimed quiz session to be claimed again, such as after its submission could not be saved
+func (s *Store) Release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claimed, id)
+}
+
+// Delete removes a quiz session and reports whether it was still active. Only one caller can delete a session.
+func (s *Store) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return false
+	}
+	delete(s.sessions, id)
+	delete(s.claimed, id)
+
+	return !s.expired(session, time.Now())
+}
+
+// expired reports whether a session has outlived the store's ttl
+func (s *Store) expired(session models.QuizSession, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(session.CreatedAt) > s.ttl
+}
+
+// pruneExpired removes all expired sessions. The caller must hold the lock.
+func (s *Store) pruneExpired(now time.Time) {
+	for id, session := range s.sessions {
+		if s.expired(session, now) {
+			delete(s.sessions, id)
+			delete(s.claimed, id)
+		}
+	}
+}
+
+// copySession returns a copy of a session which does not share its answers, their timings or its saved parts with the store.
+// The answer orders and time limits are never modified once the session is created, so they are shared.
+func copySession(session models.QuizSession) models.QuizSession {
+	answers := make(map[int]models.QuestionResponse, len(session.Answers))
+	for questionID, answer := range session.Answers {
+		answers[questionID] = answer
+	}
+	session.Answers = answers
+
+	answerTimes := make(map[int]time.Duration, len(session.AnswerTimes))
+	for questionID, taken := range session.AnswerTimes {
+		answerTimes[questionID] = taken
+	}
+	session.AnswerTimes = answerTimes
+
+	answeredAt := make(map[int]time.Time, len(session.AnsweredAt))
+	for questionID, at := range session.AnsweredAt {
+		answeredAt[questionID] = at
+	}
+	session.AnsweredAt = answeredAt
+
+	saved := make(map[string]bool, len(session.Saved))
+	for part := range session.Saved {
+		saved[part] = true
+	}
+	session.Saved = saved
+
+	return session
+}
+
+// newSessionID generates a random, hex-encoded session ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,158 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"quizwizard/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreate checks that a session records the issued questions
+func TestCreate(t *testing.T) {
+	store := NewStore(time.Hour)
+	questions := models.Questions{{ID: 3}, {ID: 1}, {ID: 2}}
+
+	session, err := store.Create("science", "", questions, nil, nil, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, session.ID, 32)
+	assert.Equal(t, "science", session.Category)
+	assert.Equal(t, []int{3, 1, 2}, session.QuestionIDs)
+	assert.Nil(t, session.AnswerOrders)
+
+	shuffled, err := store.Create("science", "", questions, map[int][]int{3: {1, 0}}, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int][]int{3: {1, 0}}, shuffled.AnswerOrders)
+
+	other, err := store.Create("science", "", questions, nil, nil, "ada")
+	assert.NoError(t, err)
+	assert.NotEqual(t, session.ID, other.ID, "Expected each session to have a unique ID")
+	assert.Equal(t, "ada", other.Username)
+	assert.Empty(t, session.Username)
+}
+
+// TestGet tests retrieving active, unknown and expired sessions
+func TestGet(t *testing.T) {
+	store := NewStore(time.Hour)
+	session, _ := store.Create("music", "", models.Questions{{ID: 1}}, nil, nil, "")
+
+	stored, ok := store.Get(session.ID)
+	assert.True(t, ok)
+	assert.Equal(t, session, stored)
+
+	_, ok = store.Get("unknown")
+	assert.False(t, ok)
+
+	store.sessions[session.ID] = models.QuizSession{ID: session.ID, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	_, ok = store.Get(session.ID)
+	assert.False(t, ok, "Expected expired session to be unavailable")
+}
+
+// TestRecordAnswer tests locking in answers for a session
+func TestRecordAnswer(t *testing.T) {
+	store := NewStore(time.Hour)
+	session, _ := store.Create("music", "", models.Questions{{ID: 1}, {ID: 2}}, nil, nil, "")
+
+	tests := []struct {
+		name          string
+		sessionID     string
+		questionID    int
+		expectedError error
+	}{
+		{"success_first_answer", session.ID, 1, nil},
+		{"failure_already_answered", session.ID, 1, ErrAlreadyAnswered},
+		{"failure_question_not_issued", session.ID, 5, ErrQuestionNotIssued},
+		{"failure_unknown_session", "unknown", 2, ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.RecordAnswer(tt.sessionID, models.QuestionResponse{QuestionID: tt.questionID, Answer: 3})
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+
+	stored, _ := store.Get(session.ID)
+	assert.Equal(t, map[int]models.QuestionResponse{1: {QuestionID: 1, Answer: 3}}, stored.Answers)
+	assert.Contains(t, stored.AnswerTimes, 1, "Expected the time taken to be recorded")
+	assert.Contains(t, stored.AnsweredAt, 1, "Expected the time answered to be recorded")
+
+	// Modifying a retrieved session must not affect the store
+	stored.Answers[2] = models.QuestionResponse{QuestionID: 2}
+	assert.NoError(t, store.RecordAnswer(session.ID, models.QuestionResponse{QuestionID: 2, Answer: 1}))
+}
+
+// TestRecordAnswerAfterTimeLimit checks that late answers to timed questions are locked in as blank answers
+func TestRecordAnswerAfterTimeLimit(t *testing.T) {
+	store := NewStore(time.Hour)
+	session, _ := store.Create("music", "", models.Questions{{ID: 1}, {ID: 2}}, nil, map[int]int{1: 10, 2: 10}, "")
+
+	// The first question was issued a minute ago
+	late := store.sessions[session.ID]
+	late.CreatedAt = time.Now().Add(-time.Minute)
+	store.sessions[session.ID] = late
+
+	err := store.RecordAnswer(session.ID, models.QuestionResponse{QuestionID: 1, Answer: 2})
+	assert.Equal(t, ErrTimeExpired, err)
+
+	// The time allowed for the second question runs from the late answer
+	assert.NoError(t, store.RecordAnswer(session.ID, models.QuestionResponse{QuestionID: 2, Answer: 1}))
+
+	stored, _ := store.Get(session.ID)
+	assert.Equal(t, map[int]models.QuestionResponse{
+		1: models.BlankResponse(1),
+		2: {QuestionID: 2, Answer: 1},
+	}, stored.Answers)
+}
+
+// TestDelete checks that a session can only be deleted once
+func TestDelete(t *testing.T) {
+	store := NewStore(time.Hour)
+	session, _ := store.Create("music", "", models.Questions{{ID: 1}}, nil, nil, "")
+
+	assert.True(t, store.Delete(session.ID))
+	assert.False(t, store.Delete(session.ID))
+
+	_, ok := store.Get(session.ID)
+	assert.False(t, ok)
+}
+
+// TestClaim checks that a session can only be claimed once until it is released, and not once it is deleted
+func TestClaim(t *testing.T) {
+	store := NewStore(time.Hour)
+	session, _ := store.Create("music", "", models.Questions{{ID: 1}}, nil, nil, "")
+
+	claimed, ok := store.Claim(session.ID)
+	assert.True(t, ok)
+	assert.Empty(t, claimed.Saved)
+	_, ok = store.Claim(session.ID)
+	assert.False(t, ok)
+
+	// Parts saved by an earlier attempt are returned when the session is claimed again
+	store.MarkSaved(session.ID, PartScore)
+	store.Release(session.ID)
+	claimed, ok = store.Claim(session.ID)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]bool{PartScore: true}, claimed.Saved)
+
+	assert.True(t, store.Delete(session.ID))
+	store.Release(session.ID)
+	_, ok = store.Claim(session.ID)
+	assert.False(t, ok)
+	_, ok = store.Claim("missing")
+	assert.False(t, ok)
+}
+
+// TestCreatePrunesExpiredSessions checks that expired sessions are removed when new sessions are created
+func TestCreatePrunesExpiredSessions(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.sessions["old"] = models.QuizSession{ID: "old", CreatedAt: time.Now().Add(-2 * time.Hour)}
+
+	_, err := store.Create("music", "", models.Questions{{ID: 1}}, nil, nil, "")
+
+	assert.NoError(t, err)
+	assert.NotContains(t, store.sessions, "old")
+	assert.Len(t, store.sessions, 1)
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"quizwizard/api/accounts"
+)
+
+// AccountStore is an accounts.Store which persists every user and result to the database.
+// Lookups are answered by an in-memory store which is populated from the database on creation.
+type AccountStore struct {
+	// mu serialises registrations so that a username is never inserted twice
+	mu    sync.Mutex
+	db    *DB
+	cache accounts.Store
+}
+
+// NewAccountStore creates a persistent account store, loading every stored user and result into the cache
+func NewAccountStore(db *DB, cache accounts.Store) (*AccountStore, error) {
+	userRows, err := db.db.Query(`SELECT id, username, password_hash, created_at FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer userRows.Close()
+
+	usernames := make(map[int]string)
+	for userRows.Next() {
+		var user accounts.User
+		if err := userRows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		if _, err := cache.Create(user); err != nil {
+			return nil, err
+		}
+		usernames[user.ID] = user.Username
+	}
+	if err := userRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	resultRows, err := db.db.Query(`SELECT user_id, category, difficulty, score, score_string, outcomes, created_at FROM results ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query results: %w", err)
+	}
+	defer resultRows.Close()
+
+	for resultRows.Next() {
+		var userID int
+		var outcomes string
+		var result accounts.Result
+		if err := resultRows.Scan(&userID, &result.Category, &result.Difficulty, &result.Score, &result.ScoreString, &outcomes, &result.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan result: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(outcomes), &result.Outcomes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result outcomes: %w", err)
+		}
+
+		result.Username = usernames[userID]
+		if err := cache.RecordResult(result); err != nil {
+			return nil, err
+		}
+	}
+	if err := resultRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+
+	return &AccountStore{db: db, cache: cache}, nil
+}
+
+// Create stores a new user, assigning it the ID given by the database
+func (s *AccountStore) Create(user accounts.User) (accounts.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.cache.Find(user.Username); err == nil {
+		return accounts.User{}, accounts.ErrUsernameTaken
+	}
+
+	id, err := s.db.insertUser(user)
+	if err != nil {
+		return accounts.User{}, err
+	}
+	user.ID = id
+
+	return s.cache.Create(user)
+}
+
+// Find retrieves a user by username
+func (s *AccountStore) Find(username string) (accounts.User, error) {
+	return s.cache.Find(username)
+}
+
+// RecordResult stores the result of a quiz submitted by a user
+func (s *AccountStore) RecordResult(result accounts.Result) error {
+	user, err := s.cache.Find(result.Username)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.insertResult(user.ID, result); err != nil {
+		return err
+	}
+
+	return s.cache.RecordResult(result)
+}
+
+// Results retrieves every result of a user, oldest first
+func (s *AccountStore) Results(username string) ([]accounts.Result, error) {
+	return s.cache.Results(username)
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"quizwizard/api/scores"
+)
+
+// Leaderboard is a scores.Leaderboard which persists every entry to the database.
+// Rankings are answered by an in-memory leaderboard which is populated from the database on creation.
+type Leaderboard struct {
+	db    *DB
+	cache scores.Leaderboard
+}
+
+// NewLeaderboard creates a persistent leaderboard, loading every stored entry into the cache
+func NewLeaderboard(db *DB, cache scores.Leaderboard) (*Leaderboard, error) {
+	rows, err := db.db.Query(`SELECT player, category, difficulty, score, score_string, created_at FROM leaderboard ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry scores.Entry
+		if err := rows.Scan(&entry.Player, &entry.Category, &entry.Difficulty, &entry.Score, &entry.ScoreString, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+
+		if err := cache.Record(entry); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard: %w", err)
+	}
+
+	return &Leaderboard{db: db, cache: cache}, nil
+}
+
+// Record stores a new entry
+func (l *Leaderboard) Record(entry scores.Entry) error {
+	if err := l.db.insertLeaderboardEntry(entry); err != nil {
+		return err
+	}
+
+	return l.cache.Record(entry)
+}
+
+// Top ranks the best entry of each player for a category and its subcategories, or every category if it is empty,
+// made since a time
+func (l *Leaderboard) Top(category string, since time.Time, limit int) ([]scores.Ranking, error) {
+	return l.cache.Top(category, since, limit)
+}
+
+// RenameCategory moves the entries of a category and its subcategories, alone or within a combination, to the new names
+func (l *Leaderboard) RenameCategory(name string, newName string) error {
+	if err := l.db.renameCategory("leaderboard", name, newName); err != nil {
+		return err
+	}
+
+	return l.cache.RenameCategory(name, newName)
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration represents a versioned change to the database schema
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations lists every schema change in the order it must be applied. Never edit an applied migration; add a new one instead.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE metadata (
+				key   TEXT PRIMARY KEY,
+				value TEXT NOT NULL
+			)`,
+			`CREATE TABLE categories (
+				name TEXT PRIMARY KEY
+			)`,
+			// Questions are stored as JSON so that new question fields do not require a migration
+			`CREATE TABLE questions (
+				id       INTEGER PRIMARY KEY,
+				category TEXT NOT NULL REFERENCES categories (name) ON DELETE CASCADE,
+				data     TEXT NOT NULL
+			)`,
+			`CREATE INDEX questions_category ON questions (category)`,
+			`CREATE TABLE submissions (
+				id         INTEGER PRIMARY KEY AUTOINCREMENT,
+				category   TEXT NOT NULL,
+				score      REAL NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX submissions_category ON submissions (category)`,
+		},
+	},
+	{
+		version: 2,
+		statements: []string{
+			`CREATE TABLE leaderboard (
+				id           INTEGER PRIMARY KEY AUTOINCREMENT,
+				player       TEXT NOT NULL,
+				category     TEXT NOT NULL,
+				difficulty   TEXT NOT NULL,
+				score        REAL NOT NULL,
+				score_string TEXT NOT NULL,
+				created_at   TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX leaderboard_category ON leaderboard (category)`,
+		},
+	},
+	{
+		version: 3,
+		statements: []string{
+			`CREATE TABLE users (
+				id            INTEGER PRIMARY KEY AUTOINCREMENT,
+				username      TEXT NOT NULL UNIQUE,
+				password_hash TEXT NOT NULL,
+				created_at    TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE results (
+				id           INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id      INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+				category     TEXT NOT NULL,
+				difficulty   TEXT NOT NULL,
+				score        REAL NOT NULL,
+				score_string TEXT NOT NULL,
+				created_at   TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX results_user ON results (user_id)`,
+		},
+	},
+	{
+		version: 4,
+		statements: []string{
+			// Outcomes are stored as JSON, like questions, and results recorded before this migration have none
+			`ALTER TABLE results ADD COLUMN outcomes TEXT NOT NULL DEFAULT '[]'`,
+		},
+	},
+}
+
+// migrate applies every migration which has not yet been applied to the database
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var current int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs the statements of a single migration within a transaction
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, m.version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"quizwizard/api/scores"
+)
+
+// ScoreStore is a scores.ScoreStore which persists every score to the database.
+// Queries are answered by an in-memory store which is populated from the database on creation.
+type ScoreStore struct {
+	db    *DB
+	cache scores.ScoreStore
+}
+
+// NewScoreStore creates a persistent score store, loading every stored submission into the cache
+func NewScoreStore(db *DB, cache scores.ScoreStore) (*ScoreStore, error) {
+	rows, err := db.db.Query(`SELECT category, score FROM submissions ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query submissions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category string
+		var score float64
+		if err := rows.Scan(&category, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan submission: %w", err)
+		}
+
+		cache.AddCategory(category)
+		if err := cache.Append(category, score); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read submissions: %w", err)
+	}
+
+	return &ScoreStore{db: db, cache: cache}, nil
+}
+
+// Append stores a new score for a category
+func (s *ScoreStore) Append(category string, score float64) error {
+	if !s.cache.HasCategory(category) {
+		return scores.ErrCategoryNotFound
+	}
+
+	if err := s.db.insertSubmission(category, score, time.Now()); err != nil {
+		return err
+	}
+
+	return s.cache.Append(category, score)
+}
+
+// Percentile returns the percentage of stored scores for a category which a score is better than
+func (s *ScoreStore) Percentile(category string, score float64) (float64, error) {
+	return s.cache.Percentile(category, score)
+}
+
+// Stats returns summary statistics for the scores of a category
+func (s *ScoreStore) Stats(category string) (scores.Stats, error) {
+	return s.cache.Stats(category)
+}
+
+// Reset removes every stored score and creates an empty bucket for each of the specified categories
+func (s *ScoreStore) Reset(categories []string) error {
+	if _, err := s.db.db.Exec(`DELETE FROM submissions`); err != nil {
+		return fmt.Errorf("failed to delete submissions: %w", err)
+	}
+
+	return s.cache.Reset(categories)
+}
+
+// AddCategory creates an empty bucket for a category if one does not already exist
+func (s *ScoreStore) AddCategory(category string) {
+	s.cache.AddCategory(category)
+}
+
+// HasCategory reports whether a category has a score bucket
+func (s *ScoreStore) HasCategory(category string) bool {
+	return s.cache.HasCategory(category)
+}
+
+// Categories returns the sorted names of every category with a score bucket
+func (s *ScoreStore) Categories() []string {
+	return s.cache.Categories()
+}
+
+// RenameCategory moves the scores of a category and its subcategories, alone or within a combination, to the new names
+func (s *ScoreStore) RenameCategory(name string, newName string) error {
+	if err := s.db.renameCategory("submissions", name, newName); err != nil {
+		return err
+	}
+
+	return s.cache.RenameCategory(name, newName)
+}
@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+
+	_ "modernc.org/sqlite"
+)
+
+// importedKey is the metadata key which records that the questions file has been imported
+const importedKey = "questions_imported_from"
+
+// loadedFileKey and loadedQuestionsKey are the metadata keys which record hashes of the questions file last loaded
+// into the bank and of the questions it held
+const (
+	loadedFileKey      = "questions_file_hash"
+	loadedQuestionsKey = "questions_loaded_hash"
+)
+
+// DB is a SQLite database holding the question bank and quiz submissions
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database at the specified path, creating it if necessary, and applies any pending migrations
+func Open(path string) (*DB, error) {
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	// SQLite serialises writes, and a single connection keeps in-memory databases consistent
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database %s: %w", path, err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// QuestionsImported reports whether a questions file has already been imported into the database
+func (d *DB) QuestionsImported() (bool, error) {
+	var count int
+	err := d.db.QueryRow(`SELECT COUNT(*) FROM metadata WHERE key = ?`, importedKey).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to read import status: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// ImportQuestions stores the questions for each category and records the source they were imported from
+func (d *DB) ImportQuestions(questions map[string]models.Questions, source string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertQuestions(tx, questions); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO metadata (key, value) VALUES (?, ?)`, importedKey, source)
+	if err != nil {
+		return fmt.Errorf("failed to record import: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return nil
+}
+
+// LoadedFile returns the hashes recorded for the questions file last loaded into the bank and for the questions it
+// held. Both are empty if nothing has been recorded.
+func (d *DB) LoadedFile() (string, string, error) {
+	rows, err := d.db.Query(`SELECT key, value FROM metadata WHERE key IN (?, ?)`, loadedFileKey, loadedQuestionsKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to query loaded questions file: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return "", "", fmt.Errorf("failed to scan loaded questions file: %w", err)
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", fmt.Errorf("failed to read loaded questions file: %w", err)
+	}
+
+	return values[loadedFileKey], values[loadedQuestionsKey], nil
+}
+
+// SetLoadedFile records hashes of the questions file loaded into the bank and of the questions it held
+func (d *DB) SetLoadedFile(fileHash string, questionsHash string) error {
+	_, err := d.db.Exec(`INSERT OR REPLACE INTO metadata (key, value) VALUES (?, ?), (?, ?)`,
+		loadedFileKey, fileHash, loadedQuestionsKey, questionsHash)
+	if err != nil {
+		return fmt.Errorf("failed to record loaded questions file: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceQuestions replaces every stored category and question within a single transaction
+func (d *DB) ReplaceQuestions(questions map[string]models.Questions) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin replacing questions: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM questions`); err != nil {
+		return fmt.Errorf("failed to delete questions: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM categories`); err != nil {
+		return fmt.Errorf("failed to delete categories: %w", err)
+	}
+
+	if err := insertQuestions(tx, questions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit questions: %w", err)
+	}
+
+	return nil
+}
+
+// LoadQuestions retrieves the questions for each category, ordered by question ID
+func (d *DB) LoadQuestions() (map[string]models.Questions, error) {
+	questions := make(map[string]models.Questions)
+
+	categoryRows, err := d.db.Query(`SELECT name FROM categories`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer categoryRows.Close()
+
+	for categoryRows.Next() {
+		var category string
+		if err := categoryRows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		questions[category] = models.Questions{}
+	}
+	if err := categoryRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read categories: %w", err)
+	}
+
+	questionRows, err := d.db.Query(`SELECT category, data FROM questions ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query questions: %w", err)
+	}
+	defer questionRows.Close()
+
+	for questionRows.Next() {
+		var category, data string
+		if err := questionRows.Scan(&category, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan question: %w", err)
+		}
+
+		var question models.Question
+		if err := json.Unmarshal([]byte(data), &question); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal question: %w", err)
+		}
+		questions[category] = append(questions[category], question)
+	}
+	if err := questionRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read questions: %w", err)
+	}
+
+	return questions, nil
+}
+
+// insertQuestions stores the categories and questions within a transaction
+func insertQuestions(tx *sql.Tx, questions map[string]models.Questions) error {
+	categories := make([]string, 0, len(questions))
+	for category := range questions {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		_, err := tx.Exec(`INSERT OR IGNORE INTO categories (name) VALUES (?)`, category)
+		if err != nil {
+			return fmt.Errorf("failed to insert category %s: %w", category, err)
+		}
+
+		for _, question := range questions[category] {
+			data, err := json.Marshal(question)
+			if err != nil {
+				return fmt.Errorf("failed to marshal question %d: %w", question.ID, err)
+			}
+
+			_, err = tx.Exec(`INSERT INTO questions (id, category, data) VALUES (?, ?, ?)`, question.ID, category, string(data))
+			if err != nil {
+				return fmt.Errorf("failed to insert question %d: %w", question.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// insertSubmission stores the score of a quiz submission
+func (d *DB) insertSubmission(category string, score float64, createdAt time.Time) error {
+	_, err := d.db.Exec(`INSERT INTO submissions (category, score, created_at) VALUES (?, ?, ?)`, category, score, createdAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to insert submission: %w", err)
+	}
+
+	return nil
+}
+
+// insertLeaderboardEntry stores the score of a named player
+func (d *DB) insertLeaderboardEntry(entry scores.Entry) error {
+	_, err := d.db.Exec(`INSERT INTO leaderboard (player, category, difficulty, score, score_string, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.Player, entry.Category, entry.Difficulty, entry.Score, entry.ScoreString, entry.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to insert leaderboard entry: %w", err)
+	}
+
+	return nil
+}
+
+// renameCategory moves the rows of a table which belong to a category or its subcategories, alone or within a
+// combination, to the new names within a single transaction. The table name must be a constant.
+func (d *DB) renameCategory(table string, name string, newName string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin renaming %s categories: %w", table, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT DISTINCT category FROM ` + table)
+	if err != nil {
+		return fmt.Errorf("failed to query %s categories: %w", table, err)
+	}
+
+	var categories []string
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan %s category: %w", table, err)
+		}
+		categories = append(categories, category)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s categories: %w", table, err)
+	}
+
+	for _, category := range categories {
+		renamed, ok := scores.RenameBucket(category, name, newName)
+		if !ok {
+			continue
+		}
+
+		if _, err := tx.Exec(`UPDATE `+table+` SET category = ? WHERE category = ?`, renamed, category); err != nil {
+			return fmt.Errorf("failed to rename %s category %s: %w", table, category, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit renamed %s categories: %w", table, err)
+	}
+
+	return nil
+}
+
+// insertUser stores a new user and returns the ID it was assigned
+func (d *DB) insertUser(user accounts.User) (int, error) {
+	result, err := d.db.Exec(`INSERT INTO users (username, password_hash, created_at) VALUES (?, ?, ?)`,
+		user.Username, user.PasswordHash, user.CreatedAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert user %s: %w", user.Username, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read ID of user %s: %w", user.Username, err)
+	}
+
+	return int(id), nil
+}
+
+// insertResult stores the result of a quiz submitted by a user, along with the outcome of each question
+func (d *DB) insertResult(userID int, result accounts.Result) error {
+	outcomes, err := json.Marshal(result.Outcomes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result outcomes: %w", err)
+	}
+
+	_, err = d.db.Exec(`INSERT INTO results (user_id, category, difficulty, score, score_string, outcomes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, result.Category, result.Difficulty, result.Score, result.ScoreString, string(outcomes), result.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to insert result: %w", err)
+	}
+
+	return nil
+}
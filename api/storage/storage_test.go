@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quizwizard/api/accounts"
+	"quizwizard/api/models"
+	"quizwizard/api/scores"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// openTestDB is a helper function which opens a fresh database in a temporary directory
+func openTestDB(t *testing.T) (*DB, string) {
+	path := filepath.Join(t.TempDir(), "quizwizard.db")
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db, path
+}
+
+// getTestQuestions is a helper function which returns a fresh copy of test questions
+func getTestQuestions() map[string]models.Questions {
+	return map[string]models.Questions{
+		"science": {
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+		},
+		"math": {
+			{ID: 3, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4", "5", "6"}, CorrectAnswerIndex: 1},
+		},
+		"history": {},
+	}
+}
+
+// TestOpenAppliesMigrations checks that migrations are applied once and reopening a database is safe
+func TestOpenAppliesMigrations(t *testing.T) {
+	db, path := openTestDB(t)
+
+	var version int
+	err := db.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	assert.NoError(t, err)
+	assert.Equal(t, migrations[len(migrations)-1].version, version)
+	assert.NoError(t, db.Close())
+
+	reopened, err := Open(path)
+	if assert.NoError(t, err) {
+		var count int
+		err = reopened.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+		assert.NoError(t, err)
+		assert.Equal(t, len(migrations), count)
+		reopened.Close()
+	}
+}
+
+// TestImportAndLoadQuestions checks that imported questions are loaded back unchanged
+func TestImportAndLoadQuestions(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	imported, err := db.QuestionsImported()
+	assert.NoError(t, err)
+	assert.False(t, imported)
+
+	err = db.ImportQuestions(getTestQuestions(), "questions.json")
+	assert.NoError(t, err)
+
+	imported, err = db.QuestionsImported()
+	assert.NoError(t, err)
+	assert.True(t, imported)
+
+	questions, err := db.LoadQuestions()
+	assert.NoError(t, err)
+
+	expected := getTestQuestions()
+	expected["science"] = models.Questions{expected["science"][1], expected["science"][0]}
+	assert.Equal(t, expected, questions)
+}
+
+// TestImportQuestionsIsAtomic checks that a failed import leaves the database unchanged
+func TestImportQuestionsIsAtomic(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	questions := getTestQuestions()
+	questions["math"] = append(questions["math"], models.Question{ID: 1, Category: "math"})
+
+	err := db.ImportQuestions(questions, "questions.json")
+	assert.Error(t, err)
+
+	imported, err := db.QuestionsImported()
+	assert.NoError(t, err)
+	assert.False(t, imported)
+
+	loaded, err := db.LoadQuestions()
+	assert.NoError(t, err)
+	assert.Empty(t, loaded)
+}
+
+// TestReplaceQuestions checks that replacing questions removes everything which was previously stored
+func TestReplaceQuestions(t *testing.T) {
+	db, _ := openTestDB(t)
+	assert.NoError(t, db.ImportQuestions(getTestQuestions(), "questions.json"))
+
+	replacement := map[string]models.Questions{
+		"music": {
+			{ID: 1, Category: "music", Question: "Who is the lead vocalist of the band Queen?", Answers: []string{"Freddie Mercury", "John Lennon"}, CorrectAnswerIndex: 0},
+		},
+	}
+	assert.NoError(t, db.ReplaceQuestions(replacement))
+
+	questions, err := db.LoadQuestions()
+	assert.NoError(t, err)
+	assert.Equal(t, replacement, questions)
+}
+
+// TestLoadedFile checks that the hashes of the loaded questions file are recorded and replaced
+func TestLoadedFile(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	fileHash, questionsHash, err := db.LoadedFile()
+	assert.NoError(t, err)
+	assert.Empty(t, fileHash)
+	assert.Empty(t, questionsHash)
+
+	assert.NoError(t, db.SetLoadedFile("file1", "questions1"))
+	assert.NoError(t, db.SetLoadedFile("file2", "questions2"))
+
+	fileHash, questionsHash, err = db.LoadedFile()
+	assert.NoError(t, err)
+	assert.Equal(t, "file2", fileHash)
+	assert.Equal(t, "questions2", questionsHash)
+}
+
+// TestScoreStorePersistsScores checks that scores survive reopening the database
+func TestScoreStorePersistsScores(t *testing.T) {
+	db, path := openTestDB(t)
+
+	store, err := NewScoreStore(db, scores.NewMemoryStore())
+	if !assert.NoError(t, err) {
+		return
+	}
+	store.AddCategory("science")
+
+	assert.NoError(t, store.Append("science", 40.0))
+	assert.NoError(t, store.Append("science", 80.0))
+	assert.Equal(t, scores.ErrCategoryNotFound, store.Append("history", 80.0))
+	assert.NoError(t, db.Close())
+
+	reopened, err := Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reopened.Close()
+
+	store, err = NewScoreStore(reopened, scores.NewMemoryStore())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stats, err := store.Stats("science")
+	assert.NoError(t, err)
+	assert.Equal(t, scores.Stats{Count: 2, Mean: 60.0, Median: 60.0, StdDev: 20.0, PassRate: 50.0, Lowest: 40.0, Highest: 80.0, Histogram: []int{0, 0, 0, 0, 1, 0, 0, 0, 1, 0}}, stats)
+
+	percentile, err := store.Percentile("science", 50.0)
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, percentile)
+
+	assert.NoError(t, store.Reset([]string{"science"}))
+
+	var count int
+	err = reopened.db.QueryRow(`SELECT COUNT(*) FROM submissions`).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+// TestLeaderboardPersistsEntries checks that leaderboard entries survive reopening the database
+func TestLeaderboardPersistsEntries(t *testing.T) {
+	db, path := openTestDB(t)
+
+	leaderboard, err := NewLeaderboard(db, scores.NewMemoryLeaderboard())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := scores.Entry{Player: "ada", Category: "science", Difficulty: "hard", Score: 80, ScoreString: "4/5", CreatedAt: createdAt}
+	assert.NoError(t, leaderboard.Record(entry))
+	assert.NoError(t, leaderboard.Record(scores.Entry{Player: "bob", Category: "music", Score: 60, ScoreString: "3/5", CreatedAt: createdAt}))
+	assert.NoError(t, db.Close())
+
+	reopened, err := Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reopened.Close()
+
+	leaderboard, err = NewLeaderboard(reopened, scores.NewMemoryLeaderboard())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rankings, err := leaderboard.Top("science", time.Time{}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, rankings, 1) {
+		assert.Equal(t, 1, rankings[0].Rank)
+		assert.Equal(t, entry.Player, rankings[0].Player)
+		assert.Equal(t, entry.Difficulty, rankings[0].Difficulty)
+		assert.Equal(t, entry.ScoreString, rankings[0].ScoreString)
+		assert.True(t, entry.CreatedAt.Equal(rankings[0].CreatedAt))
+	}
+}
+
+// TestRenameCategoryPersists checks that renamed scores and leaderboard entries survive reopening the database
+func TestRenameCategoryPersists(t *testing.T) {
+	db, path := openTestDB(t)
+
+	store, err := NewScoreStore(db, scores.NewMemoryStore())
+	if !assert.NoError(t, err) {
+		return
+	}
+	leaderboard, err := NewLeaderboard(db, scores.NewMemoryLeaderboard())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, bucket := range []string{"science/physics", "music+science:hard", "sciences"} {
+		store.AddCategory(bucket)
+		assert.NoError(t, store.Append(bucket, 50))
+		assert.NoError(t, leaderboard.Record(scores.Entry{Player: bucket, Category: bucket, Score: 50, CreatedAt: time.Now()}))
+	}
+
+	assert.NoError(t, store.RenameCategory("science", "nature"))
+	assert.NoError(t, leaderboard.RenameCategory("science", "nature"))
+	assert.NoError(t, db.Close())
+
+	reopened, err := Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reopened.Close()
+
+	store, err = NewScoreStore(reopened, scores.NewMemoryStore())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"music+nature:hard", "nature/physics", "sciences"}, store.Categories())
+
+	leaderboard, err = NewLeaderboard(reopened, scores.NewMemoryLeaderboard())
+	if !assert.NoError(t, err) {
+		return
+	}
+	rankings, err := leaderboard.Top("nature", time.Time{}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, rankings, 1) {
+		assert.Equal(t, "nature/physics", rankings[0].Category)
+	}
+}
+
+// TestAccountStorePersistsUsers checks that users and their results survive reopening the database
+func TestAccountStorePersistsUsers(t *testing.T) {
+	db, path := openTestDB(t)
+
+	store, err := NewAccountStore(db, accounts.NewMemoryStore())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	user, err := store.Create(accounts.User{Username: "ada", PasswordHash: "hash", CreatedAt: createdAt})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+
+	_, err = store.Create(accounts.User{Username: "ada", PasswordHash: "other", CreatedAt: createdAt})
+	assert.ErrorIs(t, err, accounts.ErrUsernameTaken)
+
+	outcomes := []accounts.Outcome{{QuestionID: 1, Category: "science", Question: "What is the chemical symbol for water?", Credit: 1, Points: 1, Available: 1}}
+	assert.NoError(t, store.RecordResult(accounts.Result{Username: "ada", Category: "science", Score: 80, ScoreString: "4/5", Outcomes: outcomes, CreatedAt: createdAt}))
+	assert.ErrorIs(t, store.RecordResult(accounts.Result{Username: "bob", Category: "science", CreatedAt: createdAt}), accounts.ErrUserNotFound)
+	assert.NoError(t, db.Close())
+
+	reopened, err := Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reopened.Close()
+
+	store, err = NewAccountStore(reopened, accounts.NewMemoryStore())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	found, err := store.Find("ada")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+	assert.Equal(t, "hash", found.PasswordHash)
+	assert.True(t, createdAt.Equal(found.CreatedAt))
+
+	results, err := store.Results("ada")
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "4/5", results[0].ScoreString)
+		assert.Equal(t, outcomes, results[0].Outcomes)
+	}
+
+	user, err = store.Create(accounts.User{Username: "bob", PasswordHash: "hash", CreatedAt: createdAt})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, user.ID)
+}
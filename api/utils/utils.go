@@ -2,57 +2,306 @@ package utils
 
 import (
 	"errors"
-	"fmt"
+	"math"
+	"math/rand"
+	"quizwizard/api/accounts"
 	"quizwizard/api/globals"
 	"quizwizard/api/models"
+	"quizwizard/api/scores"
+	"quizwizard/api/scoring"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// RandomizeQuestions selects up to five random questions from all categories
-func RandomiseQuestions(questions map[string]models.Questions) models.Questions {
-	// Aggregate all questions from all categories
+const (
+	// SelectionStratified spreads the questions of a random quiz evenly across the categories
+	SelectionStratified = "stratified"
+	// SelectionUniform gives every question of a random quiz the same chance of selection
+	SelectionUniform = "uniform"
+)
+
+// RandomizeQuestions selects up to count random questions of the specified difficulty from all categories.
+// Every question is equally likely to be selected, so larger categories provide more questions. An empty difficulty selects from every question.
+func RandomiseQuestions(questions map[string]models.Questions, difficulty string, count int) models.Questions {
+	// Aggregate all questions of the difficulty from all categories
 	allQuestions := models.Questions{}
 	for _, qs := range questions {
-		allQuestions = append(allQuestions, qs...)
+		allQuestions = append(allQuestions, qs.WithDifficulty(difficulty)...)
 	}
 
 	// Shuffle the aggregated questions
 	shuffledQuestions := allQuestions.ShuffledCopy()
 
-	// Select up to five questions
-	if len(shuffledQuestions) > 5 {
-		return shuffledQuestions[:5]
+	// Select up to count questions
+	if len(shuffledQuestions) > count {
+		return shuffledQuestions[:count]
 	}
 	return shuffledQuestions
 }
 
-// CalculateScore returns the score of a quiz submission as a string and also a percentage
-func CalculateScore(responses []models.QuestionResponse) (string, float64, error) {
+// StratifyQuestions selects up to count random questions of the specified difficulty, spread as evenly as possible across the categories.
+// Categories which run out of questions leave their share to the others. An empty difficulty selects from every question.
+func StratifyQuestions(questions map[string]models.Questions, difficulty string, count int) models.Questions {
+	pools := make([]models.Questions, 0, len(questions))
+	for _, qs := range questions {
+		pool := qs.WithDifficulty(difficulty).ShuffledCopy()
+		if len(pool) > 0 {
+			pools = append(pools, pool)
+		}
+	}
+
+	// Shuffle the categories so that the remainder is not always taken from the same categories
+	rand.Shuffle(len(pools), func(i, j int) {
+		pools[i], pools[j] = pools[j], pools[i]
+	})
+
+	// Take one question from each category in turn
+	selected := models.Questions{}
+	for round := 0; len(selected) < count; round++ {
+		added := false
+		for _, pool := range pools {
+			if round < len(pool) && len(selected) < count {
+				selected = append(selected, pool[round])
+				added = true
+			}
+		}
+
+		if !added {
+			break
+		}
+	}
+
+	return selected.ShuffledCopy()
+}
+
+// ShuffleAnswers returns a random order for the answers of each question, keyed by question ID.
+// True/false answers keep their order, and free-text and numeric questions have no answers to shuffle.
+func ShuffleAnswers(questions models.Questions) map[int][]int {
+	orders := make(map[int][]int, len(questions))
+	for _, question := range questions {
+		switch question.Kind() {
+		case models.TypeTrueFalse, models.TypeFreeText, models.TypeNumeric:
+			continue
+		}
+		orders[question.ID] = rand.Perm(len(question.Answers))
+	}
+	return orders
+}
+
+// QuestionTimeLimits returns the seconds allowed for each timed question, keyed by question ID
+func QuestionTimeLimits(questions models.Questions) map[int]int {
+	limits := make(map[int]int)
+	for _, question := range questions {
+		if limit := globals.TimeLimits.For(question); limit > 0 {
+			limits[question.ID] = limit
+		}
+	}
+	return limits
+}
+
+// ApplyDeadlines replaces the responses to timed questions which were not checked during the quiz with blank answers
+// if the submission arrives after their own deadline. The original responses are not modified.
+func ApplyDeadlines(session models.QuizSession, responses []models.QuestionResponse, now time.Time) []models.QuestionResponse {
+	deadlines := session.SubmissionDeadlines()
+	if len(deadlines) == 0 {
+		return responses
+	}
+
+	applied := make([]models.QuestionResponse, len(responses))
+	for i, response := range responses {
+		deadline, timed := deadlines[response.QuestionID]
+		if timed && now.After(deadline.Add(models.AnswerGracePeriod)) {
+			response = models.BlankResponse(response.QuestionID)
+		}
+		applied[i] = response
+	}
+	return applied
+}
+
+// FindQuestion searches every category for the question with the specified ID
+func FindQuestion(questions map[string]models.Questions, id int) (models.Question, bool) {
+	for _, qs := range questions {
+		for _, question := range qs {
+			if question.ID == id {
+				return question, true
+			}
+		}
+	}
+	return models.Question{}, false
+}
+
+// CalculateScore returns the score of a quiz submission as a string and also a percentage, from the points earned for
+// each question. The score is the points earned under each category's scoring strategy out of the points available,
+// so it may be fractional, such as "2.5/5". Penalties and bonuses can take the points outside of the total, but the
+// percentage is always between 0 and 100.
+func CalculateScore(points []scoring.Points) (string, float64) {
+	// Unanswered questions count towards the total
+	score := 0.0
+	available := 0.0
+	for _, p := range points {
+		score += p.Total
+		available += p.Available
+	}
+
+	return formatScore(score, available), percentage(score, available)
+}
+
+// CalculateCategoryScores returns the score of a quiz submission for each category its questions were drawn from, from
+// the points earned for each question
+func CalculateCategoryScores(points []scoring.Points) map[string]models.CategoryScore {
+	// Unanswered questions count towards the total of their category
+	categoryScores := make(map[string]models.CategoryScore)
+	for _, p := range points {
+		categoryScore := categoryScores[p.Category]
+		categoryScore.Score += p.Total
+		categoryScore.Total += p.Available
+		categoryScores[p.Category] = categoryScore
+	}
+
+	for category, categoryScore := range categoryScores {
+		categoryScore.Strategy = globals.Scoring.For(category)
+		categoryScore.ScoreString = formatScore(categoryScore.Score, categoryScore.Total)
+		categoryScore.ScorePercentage = percentage(categoryScore.Score, categoryScore.Total)
+		categoryScores[category] = categoryScore
+	}
+
+	return categoryScores
+}
+
+// CalculatePoints returns how the points for every marked answer of a quiz submission were earned, in the order the
+// questions were asked. Each category is scored by its own strategy, so streaks only run between questions of the same category.
+func CalculatePoints(answers []scoring.Answer) ([]scoring.Points, error) {
+	categoryAnswers := make(map[string][]scoring.Answer)
+	for _, answer := range answers {
+		categoryAnswers[answer.Category] = append(categoryAnswers[answer.Category], answer)
+	}
+
+	earned := make(map[int]scoring.Points, len(answers))
+	for category, categoryAnswers := range categoryAnswers {
+		scorer, err := globals.Scoring.Scorer(category)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range scorer.Score(categoryAnswers) {
+			earned[p.QuestionID] = p
+		}
+	}
+
+	points := make([]scoring.Points, len(answers))
+	for i, answer := range answers {
+		points[i] = earned[answer.QuestionID]
+	}
+	return points, nil
+}
+
+// CalculateOutcomes returns how every question of a quiz submission was answered, in the order the questions were asked,
+// from its marked answers and the points they earned
+func CalculateOutcomes(answers []scoring.Answer, points []scoring.Points, questions map[string]models.Questions) []accounts.Outcome {
+	outcomes := make([]accounts.Outcome, len(answers))
+	for i, answer := range answers {
+		question, _ := FindQuestion(questions, answer.QuestionID)
+		outcomes[i] = accounts.Outcome{
+			QuestionID: answer.QuestionID,
+			Category:   answer.Category,
+			Question:   question.Question,
+			Credit:     answer.Credit,
+			Skipped:    answer.Skipped,
+			Points:     points[i].Total,
+			Available:  points[i].Available,
+		}
+	}
+	return outcomes
+}
+
+// MarkAnswers returns the marked answer to every question issued for a quiz submission, in the order the questions were asked.
+// Answers are checked against the server's own questions and only questions issued for the session are accepted.
+// Questions without a response are skipped, and questions which have since been deleted are left out.
+func MarkAnswers(session models.QuizSession, responses []models.QuestionResponse, questions map[string]models.Questions) ([]scoring.Answer, error) {
 	if len(responses) == 0 {
 		msg := "no answers were submitted"
-		return "", 0, errors.New(msg)
+		return nil, errors.New(msg)
 	}
 
-	score := 0
+	issued := make(map[int]bool, len(session.QuestionIDs))
+	for _, id := range session.QuestionIDs {
+		issued[id] = false
+	}
+
+	submitted := make(map[int]models.QuestionResponse, len(responses))
 	for _, response := range responses {
-		if response.Question == nil {
+		answered, ok := issued[response.QuestionID]
+		if !ok || answered {
 			msg := "one or more answers were invalid"
-			return "", 0, errors.New(msg)
+			return nil, errors.New(msg)
 		}
-		if response.Question.CorrectAnswerIndex == response.Answer {
-			score++
+		issued[response.QuestionID] = true
+
+		if _, ok := FindQuestion(questions, response.QuestionID); !ok {
+			msg := "one or more answers were invalid"
+			return nil, errors.New(msg)
 		}
+		submitted[response.QuestionID] = response
 	}
 
-	totalQuestions := len(responses)
-	scoreString := fmt.Sprintf("%d/%d", score, totalQuestions)
-	scorePercentage := (float64(score) / float64(totalQuestions)) * 100
+	answers := make([]scoring.Answer, 0, len(session.QuestionIDs))
+	for _, id := range session.QuestionIDs {
+		question, ok := FindQuestion(questions, id)
+		if !ok {
+			continue
+		}
+
+		// Answers refer to the options in the order they were shown
+		question = question.Reordered(session.AnswerOrders[id])
 
-	return scoreString, scorePercentage, nil
+		// Answers which were checked during the quiz are locked in
+		response, answered := submitted[id]
+		if locked, ok := session.Answers[id]; ok {
+			response = locked
+			answered = true
+		}
+
+		answer := scoring.Answer{
+			QuestionID: id,
+			Category:   question.Category,
+			Weight:     question.Weight(),
+			Skipped:    true,
+			TimeTaken:  session.AnswerTimes[id],
+			TimeLimit:  time.Duration(session.TimeLimits[id]) * time.Second,
+		}
+		if answered {
+			answer.Credit = question.Credit(response)
+			answer.Skipped = question.Skipped(response)
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, nil
+}
+
+// formatScore describes a score out of a total, showing partial credit to at most two decimal places
+func formatScore(score float64, total float64) string {
+	return formatPoints(score) + "/" + formatPoints(total)
+}
+
+// formatPoints rounds a number of points to at most two decimal places
+func formatPoints(points float64) string {
+	rounded := math.Round(points*100) / 100
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// percentage returns a score as a percentage of the total, limited to between 0 and 100
+func percentage(score float64, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Min(math.Max(score/total*100, 0), 100)
 }
 
-// CalculateComparison calculates the percentage of users a score is better than
-func CalculateComparison(category string, newScore float64) (float64, error) {
+// CalculateComparison calculates the percentage of users a score is better than.
+// Scores are compared against other quizzes of the same category, or combination of categories, and difficulty.
+func CalculateComparison(category string, difficulty string, newScore float64) (float64, error) {
 	category = strings.Trim(category, " ")
 	category = strings.ToLower(category)
 
@@ -61,9 +310,12 @@ func CalculateComparison(category string, newScore float64) (float64, error) {
 		return 0.0, errors.New(msg)
 	}
 
-	scores, exists := globals.CategoryScores[category]
-	if !exists {
-		msg := "category '" + category + "' does not exist"
+	if err := checkCategories(category); err != nil {
+		return 0.0, err
+	}
+
+	if difficulty != "" && !models.IsDifficulty(difficulty) {
+		msg := "difficulty '" + difficulty + "' does not exist"
 		return 0.0, errors.New(msg)
 	}
 
@@ -72,24 +324,16 @@ func CalculateComparison(category string, newScore float64) (float64, error) {
 		return 0.0, errors.New(msg)
 	}
 
-	if len(scores) == 0 {
-		// First submission for the specified category
-		return 0, nil
+	// The first submission for a category, combination or difficulty is better than 0% of quizzers
+	bucket := scores.Bucket(category, difficulty)
+	if !globals.Scores.HasCategory(bucket) {
+		return 0.0, nil
 	}
-
-	betterThanCount := 0
-	for _, score := range scores {
-		if newScore > score {
-			betterThanCount++
-		}
-	}
-
-	comparisonPercentage := (float64(betterThanCount) / float64(len(scores))) * 100
-	return comparisonPercentage, nil
+	return globals.Scores.Percentile(bucket, newScore)
 }
 
-// AppendCategoryScore stores a new score for a specific category
-func AppendCategoryScore(category string, newScore float64) error {
+// AppendCategoryScore stores a new score for a specific category, or combination of categories, and difficulty
+func AppendCategoryScore(category string, difficulty string, newScore float64) error {
 	category = strings.Trim(category, " ")
 	category = strings.ToLower(category)
 
@@ -98,8 +342,12 @@ func AppendCategoryScore(category string, newScore float64) error {
 		return errors.New(msg)
 	}
 
-	if _, ok := globals.CategoryScores[category]; !ok {
-		msg := "category '" + category + "' does not exist"
+	if err := checkCategories(category); err != nil {
+		return err
+	}
+
+	if difficulty != "" && !models.IsDifficulty(difficulty) {
+		msg := "difficulty '" + difficulty + "' does not exist"
 		return errors.New(msg)
 	}
 
@@ -108,6 +356,50 @@ func AppendCategoryScore(category string, newScore float64) error {
 		return errors.New(msg)
 	}
 
-	globals.CategoryScores[category] = append(globals.CategoryScores[category], newScore)
+	// Buckets for each combination and difficulty are created by their first score
+	for _, level := range scoreLevels(category) {
+		bucket := scores.Bucket(level, difficulty)
+		globals.Scores.AddCategory(bucket)
+		if err := globals.Scores.Append(bucket, newScore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CalculateLevelComparisons calculates the percentage of users a score is better than at each level of the category
+// tree, starting from the category itself and ending with its top level category
+func CalculateLevelComparisons(category string, difficulty string, newScore float64) ([]models.LevelComparison, error) {
+	category = strings.Trim(category, " ")
+	category = strings.ToLower(category)
+
+	comparisons := []models.LevelComparison{}
+	for _, level := range scoreLevels(category) {
+		percentile, err := CalculateComparison(level, difficulty, newScore)
+		if err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, models.LevelComparison{Category: level, Percentile: percentile})
+	}
+	return comparisons, nil
+}
+
+// scoreLevels returns the score buckets a quiz's score is counted in. Scores for a single category also count
+// towards each of its parents, while combinations of categories are only compared with the same combination.
+func scoreLevels(category string) []string {
+	if len(scores.SplitCombination(category)) > 1 {
+		return []string{category}
+	}
+	return models.CategoryLevels(category)
+}
+
+// checkCategories checks that every category within a category combination has a score bucket
+func checkCategories(combination string) error {
+	for _, category := range scores.SplitCombination(combination) {
+		if !globals.Scores.HasCategory(category) {
+			msg := "category '" + category + "' does not exist"
+			return errors.New(msg)
+		}
+	}
 	return nil
 }
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"quizwizard/api/accounts"
 	"quizwizard/api/globals"
 	"quizwizard/api/models"
+	"quizwizard/api/scores"
+	"quizwizard/api/scoring"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,19 +28,22 @@ func TestRandomiseQuestions(t *testing.T) {
 			{ID: 6, Category: "history", Question: "In which year did the Titanic sink?", Answers: []string{"1912", "1913", "1914", "1915"}, CorrectAnswerIndex: 0},
 		},
 		"geography": {
-			{ID: 7, Category: "geography", Question: "What is the capital of France?", Answers: []string{"Berlin", "Madrid", "Paris", "Lisbon"}, CorrectAnswerIndex: 2},
+			{ID: 7, Category: "geography", Question: "What is the capital of France?", Answers: []string{"Berlin", "Madrid", "Paris", "Lisbon"}, CorrectAnswerIndex: 2, Difficulty: models.DifficultyEasy},
 		},
 	}
 
 	tests := []struct {
 		name            string
 		questions       map[string]models.Questions
+		difficulty      string
+		count           int
 		expectedCount   int
 		checkCategories bool
 	}{
 		{
 			name:          "more_than_five_total_questions",
 			questions:     questions,
+			count:         5,
 			expectedCount: 5,
 		},
 		{
@@ -45,21 +52,49 @@ func TestRandomiseQuestions(t *testing.T) {
 				"science": {questions["science"][0]},
 				"math":    {questions["math"][0]},
 			},
+			count:         5,
 			expectedCount: 2,
 		},
+		{
+			name:          "requested_number_of_questions",
+			questions:     questions,
+			count:         3,
+			expectedCount: 3,
+		},
 		{
 			name:            "ensure_categories_are_mixed",
 			questions:       questions,
+			count:           5,
 			expectedCount:   5,
 			checkCategories: true,
 		},
+		{
+			name:          "only_questions_of_the_difficulty",
+			questions:     questions,
+			difficulty:    models.DifficultyEasy,
+			count:         5,
+			expectedCount: 1,
+		},
+		{
+			name:          "no_questions_of_the_difficulty",
+			questions:     questions,
+			difficulty:    models.DifficultyHard,
+			count:         5,
+			expectedCount: 0,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := RandomiseQuestions(tt.questions)
+			result := RandomiseQuestions(tt.questions, tt.difficulty, tt.count)
 			assert.Equal(t, tt.expectedCount, len(result), "Unexpected number of questions returned")
 
+			for _, question := range result {
+				if tt.difficulty != "" {
+					assert.Equal(t, tt.difficulty, question.Level())
+				}
+			}
+
 			if tt.checkCategories {
 				categories := map[string]struct{}{}
 				for _, question := range result {
@@ -71,39 +106,92 @@ func TestRandomiseQuestions(t *testing.T) {
 	}
 }
 
+// TestStratifyQuestions tests the StratifyQuestions utility function
+func TestStratifyQuestions(t *testing.T) {
+	questions := map[string]models.Questions{
+		"science": {},
+		"math": {
+			{ID: 20, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4"}, CorrectAnswerIndex: 1, Difficulty: models.DifficultyEasy},
+		},
+		"history": {
+			{ID: 30, Category: "history", Question: "In which year did the Titanic sink?", Answers: []string{"1912", "1915"}, CorrectAnswerIndex: 0},
+		},
+	}
+	for id := 1; id <= 10; id++ {
+		questions["science"] = append(questions["science"], models.Question{ID: id, Category: "science", Question: "Science question", Answers: []string{"Yes", "No"}})
+	}
+
+	tests := []struct {
+		name               string
+		difficulty         string
+		count              int
+		expectedCategories map[string]int
+	}{
+		{"one_question_from_each_category", "", 3, map[string]int{"science": 1, "math": 1, "history": 1}},
+		{"larger_categories_fill_the_remainder", "", 6, map[string]int{"science": 4, "math": 1, "history": 1}},
+		{"fewer_questions_than_requested", "", 20, map[string]int{"science": 10, "math": 1, "history": 1}},
+		{"only_questions_of_the_difficulty", models.DifficultyEasy, 5, map[string]int{"math": 1}},
+		{"no_questions_of_the_difficulty", models.DifficultyHard, 5, map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := StratifyQuestions(questions, tt.difficulty, tt.count)
+
+			categories := map[string]int{}
+			for _, question := range result {
+				categories[question.Category]++
+			}
+			assert.Equal(t, tt.expectedCategories, categories)
+		})
+	}
+}
+
+// TestStratifyQuestionsSpreadsRemainder checks that the questions left over after an even split are not always taken from the same categories
+func TestStratifyQuestionsSpreadsRemainder(t *testing.T) {
+	questions := map[string]models.Questions{
+		"science": {{ID: 1, Category: "science"}, {ID: 2, Category: "science"}},
+		"math":    {{ID: 3, Category: "math"}, {ID: 4, Category: "math"}},
+	}
+
+	selected := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		result := StratifyQuestions(questions, "", 1)
+		assert.Len(t, result, 1)
+		selected[result[0].Category] = true
+	}
+
+	assert.Len(t, selected, 2, "Expected both categories to be selected")
+}
+
+// scoreSubmission is a helper function which marks and scores a quiz submission
+func scoreSubmission(session models.QuizSession, responses []models.QuestionResponse, questions map[string]models.Questions) ([]scoring.Points, error) {
+	answers, err := MarkAnswers(session, responses, questions)
+	if err != nil {
+		return nil, err
+	}
+	return CalculatePoints(answers)
+}
+
 // TestCalculateScore tests the CalculateScore utility function
 func TestCalculateScore(t *testing.T) {
-	questions := []models.Question{
-		{
-			ID:                 1,
-			Category:           "science",
-			Question:           "What is the chemical symbol for water?",
-			Answers:            []string{"H2O", "O2", "H2O2", "HO"},
-			CorrectAnswerIndex: 0,
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
 		},
-		{
-			ID:                 2,
-			Category:           "math",
-			Question:           "What is 2 + 2?",
-			Answers:            []string{"3", "4", "5", "6"},
-			CorrectAnswerIndex: 1,
+		"math": {
+			{ID: 2, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4", "5", "6"}, CorrectAnswerIndex: 1},
 		},
-		{
-			ID:                 3,
-			Category:           "history",
-			Question:           "Who was the first president of the United States?",
-			Answers:            []string{"George Washington", "Thomas Jefferson", "Abraham Lincoln", "John Adams"},
-			CorrectAnswerIndex: 0,
+		"history": {
+			{ID: 3, Category: "history", Question: "Who was the first president of the United States?", Answers: []string{"George Washington", "Thomas Jefferson", "Abraham Lincoln", "John Adams"}, CorrectAnswerIndex: 0},
 		},
-		{
-			ID:                 4,
-			Category:           "geography",
-			Question:           "What is the capital of France?",
-			Answers:            []string{"Berlin", "Madrid", "Paris", "Lisbon"},
-			CorrectAnswerIndex: 2,
+		"geography": {
+			{ID: 4, Category: "geography", Question: "What is the capital of France?", Answers: []string{"Berlin", "Madrid", "Paris", "Lisbon"}, CorrectAnswerIndex: 2},
 		},
 	}
 
+	session := models.QuizSession{ID: "abc123", Category: "random", QuestionIDs: []int{1, 2, 3, 4}}
+
 	tests := []struct {
 		name            string
 		responses       []models.QuestionResponse
@@ -114,10 +202,10 @@ func TestCalculateScore(t *testing.T) {
 		{
 			name: "success_all_correct_answers",
 			responses: []models.QuestionResponse{
-				{Question: &questions[0], Answer: 0},
-				{Question: &questions[1], Answer: 1},
-				{Question: &questions[2], Answer: 0},
-				{Question: &questions[3], Answer: 2},
+				{QuestionID: 1, Answer: 0},
+				{QuestionID: 2, Answer: 1},
+				{QuestionID: 3, Answer: 0},
+				{QuestionID: 4, Answer: 2},
 			},
 			expectedString:  "4/4",
 			expectedPercent: 100.0,
@@ -126,15 +214,25 @@ func TestCalculateScore(t *testing.T) {
 		{
 			name: "success_all_wrong_answers",
 			responses: []models.QuestionResponse{
-				{Question: &questions[0], Answer: 1},
-				{Question: &questions[1], Answer: 0},
-				{Question: &questions[2], Answer: 1},
-				{Question: &questions[3], Answer: 0},
+				{QuestionID: 1, Answer: 1},
+				{QuestionID: 2, Answer: 0},
+				{QuestionID: 3, Answer: 1},
+				{QuestionID: 4, Answer: 0},
 			},
 			expectedString:  "0/4",
 			expectedPercent: 0.0,
 			expectedError:   "",
 		},
+		{
+			name: "success_unanswered_questions_count_towards_total",
+			responses: []models.QuestionResponse{
+				{QuestionID: 1, Answer: 0},
+				{QuestionID: 2, Answer: 1},
+			},
+			expectedString:  "2/4",
+			expectedPercent: 50.0,
+			expectedError:   "",
+		},
 		{
 			name:            "failure_empty_responses_slice",
 			responses:       []models.QuestionResponse{},
@@ -143,12 +241,20 @@ func TestCalculateScore(t *testing.T) {
 			expectedError:   "no answers were submitted",
 		},
 		{
-			name: "failure_nil_question",
+			name: "failure_question_not_issued_for_session",
 			responses: []models.QuestionResponse{
-				{Question: nil, Answer: 0},
-				{Question: &questions[1], Answer: 1},
-				{Question: &questions[2], Answer: 0},
-				{Question: &questions[3], Answer: 2},
+				{QuestionID: 9, Answer: 0},
+				{QuestionID: 2, Answer: 1},
+			},
+			expectedString:  "",
+			expectedPercent: 0,
+			expectedError:   "one or more answers were invalid",
+		},
+		{
+			name: "failure_duplicate_answer",
+			responses: []models.QuestionResponse{
+				{QuestionID: 1, Answer: 0},
+				{QuestionID: 1, Answer: 0},
 			},
 			expectedString:  "",
 			expectedPercent: 0,
@@ -157,10 +263,10 @@ func TestCalculateScore(t *testing.T) {
 		{
 			name: "positive_answer_out_of_bounds",
 			responses: []models.QuestionResponse{
-				{Question: &questions[0], Answer: 5},
-				{Question: &questions[1], Answer: 1},
-				{Question: &questions[2], Answer: 0},
-				{Question: &questions[3], Answer: 2},
+				{QuestionID: 1, Answer: 5},
+				{QuestionID: 2, Answer: 1},
+				{QuestionID: 3, Answer: 0},
+				{QuestionID: 4, Answer: 2},
 			},
 			expectedString:  "3/4",
 			expectedPercent: 75.0,
@@ -169,10 +275,10 @@ func TestCalculateScore(t *testing.T) {
 		{
 			name: "negative_answer_out_of_bounds",
 			responses: []models.QuestionResponse{
-				{Question: &questions[0], Answer: -3},
-				{Question: &questions[1], Answer: 1},
-				{Question: &questions[2], Answer: 0},
-				{Question: &questions[3], Answer: 2},
+				{QuestionID: 1, Answer: -3},
+				{QuestionID: 2, Answer: 1},
+				{QuestionID: 3, Answer: 0},
+				{QuestionID: 4, Answer: 2},
 			},
 			expectedString:  "3/4",
 			expectedPercent: 75.0,
@@ -182,12 +288,13 @@ func TestCalculateScore(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resultString, resultPercent, err := CalculateScore(tt.responses)
+			points, err := scoreSubmission(session, tt.responses, questions)
 			if tt.expectedError != "" {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedError, err.Error())
 			} else {
 				assert.NoError(t, err)
+				resultString, resultPercent := CalculateScore(points)
 				assert.Equal(t, tt.expectedString, resultString)
 				assert.Equal(t, tt.expectedPercent, resultPercent)
 			}
@@ -195,39 +302,385 @@ func TestCalculateScore(t *testing.T) {
 	}
 }
 
+// TestCalculateScoreWithShuffledAnswers checks that answers are scored against the order in which they were shown
+func TestCalculateScoreWithShuffledAnswers(t *testing.T) {
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+			{ID: 3, Category: "science", Question: "What gas do plants absorb?", Answers: []string{"Oxygen", "Carbon dioxide"}, CorrectAnswerIndex: 1},
+		},
+	}
+
+	session := models.QuizSession{
+		ID:          "abc123",
+		Category:    "science",
+		QuestionIDs: []int{1, 2, 3},
+		AnswerOrders: map[int][]int{
+			1: {3, 2, 1, 0},
+			2: {1, 0, 2, 3},
+			3: {1, 0},
+		},
+		Answers: map[int]models.QuestionResponse{3: {QuestionID: 3, Answer: 0}},
+	}
+
+	// H2O was shown last, Mars was shown first and the locked in answer to question 3 was Carbon dioxide
+	responses := []models.QuestionResponse{
+		{QuestionID: 1, Answer: 3},
+		{QuestionID: 2, Answer: 1},
+		{QuestionID: 3, Answer: 1},
+	}
+
+	points, err := scoreSubmission(session, responses, questions)
+	assert.NoError(t, err)
+	resultString, resultPercent := CalculateScore(points)
+	assert.Equal(t, "2/3", resultString)
+	assert.InDelta(t, 66.67, resultPercent, 0.01)
+}
+
+// TestCalculateScoreWithQuestionTypes checks that every question type is scored and that partial credit is shown
+func TestCalculateScoreWithQuestionTypes(t *testing.T) {
+	bones, guess := 206.0, 218.0
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Type: models.TypeTrueFalse, Question: "Water boils at 100C at sea level.", Answers: []string{"True", "False"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Type: models.TypeMultiSelect, Question: "Which of these are planets?", Answers: []string{"Pluto", "Mars", "Venus", "The Moon"}, CorrectAnswerIndexes: []int{1, 2}},
+			{ID: 3, Category: "science", Type: models.TypeFreeText, Question: "What is the chemical symbol for gold?", AcceptedAnswers: []string{"Au"}},
+			{ID: 4, Category: "science", Type: models.TypeNumeric, Question: "How many bones are in the adult human body?", CorrectValue: &bones, AbsoluteTolerance: 10},
+		},
+	}
+
+	session := models.QuizSession{
+		ID:          "abc123",
+		Category:    "science",
+		QuestionIDs: []int{1, 2, 3, 4},
+		// Mars and Venus were shown first and second, and only Mars was chosen
+		AnswerOrders: map[int][]int{2: {1, 2, 0, 3}},
+	}
+
+	responses := []models.QuestionResponse{
+		{QuestionID: 1, Answer: 0},
+		{QuestionID: 2, Answers: []int{0}},
+		{QuestionID: 3, Text: " au "},
+		{QuestionID: 4, Value: &guess},
+	}
+
+	points, err := scoreSubmission(session, responses, questions)
+	assert.NoError(t, err)
+
+	scoreString, scorePercentage := CalculateScore(points)
+	assert.Equal(t, "3.3/4", scoreString)
+	assert.InDelta(t, 82.5, scorePercentage, 1e-9)
+
+	categoryScores := CalculateCategoryScores(points)
+	assert.InDelta(t, 3.3, categoryScores["science"].Score, 1e-9)
+	assert.Equal(t, "3.3/4", categoryScores["science"].ScoreString)
+}
+
+// TestCalculateScoreWithScoringStrategies checks that each category is scored by its own strategy
+func TestCalculateScoreWithScoringStrategies(t *testing.T) {
+	original := globals.Scoring
+	defer func() { globals.Scoring = original }()
+	globals.Scoring = scoring.Strategies{Categories: map[string]string{"science": "weighted+negative"}}
+
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0, Points: 3},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars"}, CorrectAnswerIndex: 1},
+			{ID: 3, Category: "science", Question: "What is the largest planet?", Answers: []string{"Jupiter", "Saturn"}, CorrectAnswerIndex: 0, Points: 2},
+		},
+		"math": {
+			{ID: 4, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4"}, CorrectAnswerIndex: 1, Points: 5},
+		},
+	}
+
+	session := models.QuizSession{ID: "abc123", Category: "math+science", QuestionIDs: []int{1, 2, 3, 4}}
+
+	// The wrong answer loses a quarter of its point and the skipped question loses nothing
+	responses := []models.QuestionResponse{
+		{QuestionID: 1, Answer: 0},
+		{QuestionID: 2, Answer: 0},
+		{QuestionID: 3, Answer: -1},
+		{QuestionID: 4, Answer: 1},
+	}
+
+	points, err := scoreSubmission(session, responses, questions)
+	assert.NoError(t, err)
+
+	scoreString, scorePercentage := CalculateScore(points)
+	assert.Equal(t, "3.75/7", scoreString)
+	assert.InDelta(t, 375.0/7, scorePercentage, 1e-9)
+
+	categoryScores := CalculateCategoryScores(points)
+	assert.Equal(t, "weighted+negative", categoryScores["science"].Strategy)
+	assert.Equal(t, "2.75/6", categoryScores["science"].ScoreString)
+	assert.InDelta(t, 275.0/6, categoryScores["science"].ScorePercentage, 1e-9)
+	assert.Equal(t, models.CategoryScore{Strategy: "standard", Score: 1, Total: 1, ScoreString: "1/1", ScorePercentage: 100}, categoryScores["math"])
+
+	assert.Equal(t, []scoring.Points{
+		{QuestionID: 1, Category: "science", Available: 3, Earned: 3, Total: 3},
+		{QuestionID: 2, Category: "science", Available: 1, Earned: 0, Penalty: 0.25, Total: -0.25},
+		{QuestionID: 3, Category: "science", Available: 2, Earned: 0, Total: 0},
+		{QuestionID: 4, Category: "math", Available: 1, Earned: 1, Total: 1},
+	}, points)
+}
+
+// TestCalculateScoreWithBonuses checks that bonuses are limited to a percentage of 100
+func TestCalculateScoreWithBonuses(t *testing.T) {
+	original := globals.Scoring
+	defer func() { globals.Scoring = original }()
+	globals.Scoring = scoring.Strategies{Default: "speed+streak"}
+
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars"}, CorrectAnswerIndex: 1},
+		},
+	}
+
+	// Both answers were checked during the quiz, the first halfway through its time limit
+	session := models.QuizSession{
+		ID:          "abc123",
+		Category:    "science",
+		QuestionIDs: []int{1, 2},
+		Answers:     map[int]models.QuestionResponse{1: {QuestionID: 1, Answer: 0}, 2: {QuestionID: 2, Answer: 1}},
+		TimeLimits:  map[int]int{1: 20},
+		AnswerTimes: map[int]time.Duration{1: 10 * time.Second, 2: time.Minute},
+	}
+	responses := []models.QuestionResponse{{QuestionID: 1, Answer: 0}, {QuestionID: 2, Answer: 1}}
+
+	points, err := scoreSubmission(session, responses, questions)
+	assert.NoError(t, err)
+	assert.Equal(t, []scoring.Points{
+		{QuestionID: 1, Category: "science", Available: 1, Earned: 1, SpeedBonus: 0.25, Total: 1.25},
+		{QuestionID: 2, Category: "science", Available: 1, Earned: 1, StreakBonus: 0.1, Total: 1.1},
+	}, points)
+
+	scoreString, scorePercentage := CalculateScore(points)
+	assert.Equal(t, "2.35/2", scoreString)
+	assert.Equal(t, 100.0, scorePercentage)
+}
+
+// TestCalculateOutcomes checks that every issued question has an outcome, in the order the questions were asked
+func TestCalculateOutcomes(t *testing.T) {
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars"}, CorrectAnswerIndex: 1},
+		},
+		"music": {
+			{ID: 3, Category: "music", Question: "How many strings does a standard guitar have?", Answers: []string{"4", "6"}, CorrectAnswerIndex: 1},
+		},
+	}
+	session := models.QuizSession{ID: "abc123", Category: "music+science", QuestionIDs: []int{3, 1, 2}}
+	responses := []models.QuestionResponse{{QuestionID: 1, Answer: 0}, {QuestionID: 3, Answer: 0}}
+
+	answers, err := MarkAnswers(session, responses, questions)
+	assert.NoError(t, err)
+	points, err := CalculatePoints(answers)
+	assert.NoError(t, err)
+
+	outcomes := CalculateOutcomes(answers, points, questions)
+	assert.Equal(t, []accounts.Outcome{
+		{QuestionID: 3, Category: "music", Question: "How many strings does a standard guitar have?", Credit: 0, Points: 0, Available: 1},
+		{QuestionID: 1, Category: "science", Question: "What is the chemical symbol for water?", Credit: 1, Points: 1, Available: 1},
+		{QuestionID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Skipped: true, Points: 0, Available: 1},
+	}, outcomes)
+
+	_, err = MarkAnswers(session, nil, questions)
+	assert.EqualError(t, err, "no answers were submitted")
+}
+
+// TestCalculateCategoryScores tests the CalculateCategoryScores utility function
+func TestCalculateCategoryScores(t *testing.T) {
+	questions := map[string]models.Questions{
+		"science": {
+			{ID: 1, Category: "science", Question: "What is the chemical symbol for water?", Answers: []string{"H2O", "O2", "H2O2", "HO"}, CorrectAnswerIndex: 0},
+			{ID: 2, Category: "science", Question: "What planet is known as the Red Planet?", Answers: []string{"Earth", "Mars", "Jupiter", "Venus"}, CorrectAnswerIndex: 1},
+		},
+		"math": {
+			{ID: 3, Category: "math", Question: "What is 2 + 2?", Answers: []string{"3", "4", "5", "6"}, CorrectAnswerIndex: 1},
+		},
+	}
+
+	session := models.QuizSession{ID: "abc123", Category: "math+science", QuestionIDs: []int{1, 2, 3}}
+
+	tests := []struct {
+		name           string
+		responses      []models.QuestionResponse
+		expectedScores map[string]models.CategoryScore
+		expectedError  string
+	}{
+		{
+			name: "success_score_for_each_category",
+			responses: []models.QuestionResponse{
+				{QuestionID: 1, Answer: 0},
+				{QuestionID: 3, Answer: 0},
+			},
+			expectedScores: map[string]models.CategoryScore{
+				"science": {Strategy: "standard", Score: 1, Total: 2, ScoreString: "1/2", ScorePercentage: 50},
+				"math":    {Strategy: "standard", Score: 0, Total: 1, ScoreString: "0/1", ScorePercentage: 0},
+			},
+		},
+		{
+			name:          "failure_empty_responses_slice",
+			responses:     []models.QuestionResponse{},
+			expectedError: "no answers were submitted",
+		},
+		{
+			name: "failure_question_not_issued_for_session",
+			responses: []models.QuestionResponse{
+				{QuestionID: 9, Answer: 0},
+			},
+			expectedError: "one or more answers were invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := scoreSubmission(session, tt.responses, questions)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedScores, CalculateCategoryScores(points))
+			}
+		})
+	}
+}
+
+// TestShuffleAnswers checks that each question is given a complete order for its answers
+func TestShuffleAnswers(t *testing.T) {
+	questions := models.Questions{
+		{ID: 1, Answers: []string{"H2O", "O2", "H2O2", "HO"}},
+		{ID: 7, Answers: []string{"Yes", "No"}},
+		{ID: 8, Type: models.TypeTrueFalse, Answers: []string{"True", "False"}},
+		{ID: 9, Type: models.TypeFreeText, AcceptedAnswers: []string{"Paris"}},
+		{ID: 10, Type: models.TypeNumeric},
+	}
+
+	orders := ShuffleAnswers(questions)
+
+	assert.Len(t, orders, 2, "Expected true/false, free-text and numeric questions to keep their order")
+	assert.ElementsMatch(t, []int{0, 1, 2, 3}, orders[1])
+	assert.ElementsMatch(t, []int{0, 1}, orders[7])
+}
+
+// TestQuestionTimeLimits checks that only timed questions are given a time limit
+func TestQuestionTimeLimits(t *testing.T) {
+	original := globals.TimeLimits
+	defer func() { globals.TimeLimits = original }()
+	globals.TimeLimits = models.TimeLimits{CategoryDefaults: map[string]int{"science": 30}}
+
+	limits := QuestionTimeLimits(models.Questions{
+		{ID: 1, Category: "science"},
+		{ID: 2, Category: "music"},
+		{ID: 3, Category: "music", TimeLimit: 15},
+	})
+
+	assert.Equal(t, map[int]int{1: 30, 3: 15}, limits)
+}
+
+// TestApplyDeadlines checks that unchecked answers to timed questions are blanked once they are overdue
+func TestApplyDeadlines(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := models.QuizSession{
+		QuestionIDs: []int{1, 2, 3},
+		Answers:     map[int]models.QuestionResponse{1: {QuestionID: 1, Answer: 2}},
+		TimeLimits:  map[int]int{1: 10, 2: 10},
+		AnsweredAt:  map[int]time.Time{1: createdAt.Add(3 * time.Second)},
+		CreatedAt:   createdAt,
+	}
+	responses := []models.QuestionResponse{
+		{QuestionID: 1, Answer: 2},
+		{QuestionID: 2, Answer: 1},
+		{QuestionID: 3, Answer: 0},
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected []models.QuestionResponse
+	}{
+		{"success_within_time_limit", createdAt.Add(5 * time.Second), responses},
+		{"success_within_grace_period", createdAt.Add(14 * time.Second), responses},
+		{"success_overdue", createdAt.Add(time.Minute), []models.QuestionResponse{
+			{QuestionID: 1, Answer: 2},
+			models.BlankResponse(2),
+			{QuestionID: 3, Answer: 0},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := ApplyDeadlines(session, responses, tt.now)
+			assert.Equal(t, tt.expected, applied)
+		})
+	}
+
+	assert.Equal(t, models.QuestionResponse{QuestionID: 2, Answer: 1}, responses[1], "Expected the original responses to be unchanged")
+}
+
+// TestApplyDeadlinesPerQuestion checks that an answer given after its own question's time limit is blanked even though
+// the quiz was submitted within the time allowed for all of its questions
+func TestApplyDeadlinesPerQuestion(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := models.QuizSession{
+		QuestionIDs: []int{1, 2},
+		Answers:     map[int]models.QuestionResponse{},
+		TimeLimits:  map[int]int{1: 10, 2: 30},
+		CreatedAt:   createdAt,
+	}
+	responses := []models.QuestionResponse{
+		{QuestionID: 1, Answer: 0},
+		{QuestionID: 2, Answer: 1},
+	}
+
+	applied := ApplyDeadlines(session, responses, createdAt.Add(25*time.Second))
+
+	assert.Equal(t, []models.QuestionResponse{models.BlankResponse(1), {QuestionID: 2, Answer: 1}}, applied)
+}
+
 // TestCalculateComparison tests the CalculateComparison utility function
 func TestCalculateComparison(t *testing.T) {
-	// Save the original CategoryScores map to restore it later
-	originalCategoryScores := globals.CategoryScores
-	defer func() { globals.CategoryScores = originalCategoryScores }()
+	// Save the original score store to restore it later
+	originalScores := globals.Scores
+	defer func() { globals.Scores = originalScores }()
 
-	// Mock CategoryScores for testing
-	globals.CategoryScores = map[string][]float64{
-		"science": {50.0, 60.0, 70.0, 80.0, 90.0},
-		"math":    {20.0, 30.0, 40.0, 50.0, 60.0},
-		"music":   {},
-	}
+	// Mock the score store for testing
+	globals.Scores = newScoreStore(map[string][]float64{
+		"science":      {50.0, 60.0, 70.0, 80.0, 90.0},
+		"math":         {20.0, 30.0, 40.0, 50.0, 60.0},
+		"music":        {},
+		"science:hard": {10.0, 20.0},
+	})
 
 	tests := []struct {
 		name          string
 		category      string
+		difficulty    string
 		newScore      float64
 		expected      float64
 		expectedError string
 	}{
-		{"success_science_category", "science", 75.0, 60.0, ""},
-		{"success_math_category_with_whitespace_and_capitals", "  MATH   ", 35.0, 40.0, ""},
-		{"success_first_submission", "music", 60.0, 0.0, ""},
-		{"failure_invalid_category", "history", 85.0, 0.0, "category 'history' does not exist"},
-		{"failure_whitespace_category_string", "    ", 85.0, 0.0, "a category must be provided"},
-		{"failure_empty_category_string", "", 85.0, 0.0, "a category must be provided"},
-		{"failure_positive_score_out_of_bounds", "science", 105.0, 0.0, "score must be a value between 0 and 100"},
-		{"failure_negative_score_out_of_bounds", "science", -5.0, 0.0, "score must be a value between 0 and 100"},
+		{"success_science_category", "science", "", 75.0, 60.0, ""},
+		{"success_math_category_with_whitespace_and_capitals", "  MATH   ", "", 35.0, 40.0, ""},
+		{"success_first_submission", "music", "", 60.0, 0.0, ""},
+		{"success_compared_within_difficulty", "science", "hard", 15.0, 50.0, ""},
+		{"success_first_submission_for_difficulty", "science", "easy", 75.0, 0.0, ""},
+		{"failure_invalid_category", "history", "", 85.0, 0.0, "category 'history' does not exist"},
+		{"failure_invalid_difficulty", "science", "extreme", 85.0, 0.0, "difficulty 'extreme' does not exist"},
+		{"success_first_submission_for_combination", "math+science", "", 75.0, 0.0, ""},
+		{"failure_invalid_category_within_combination", "history+science", "", 85.0, 0.0, "category 'history' does not exist"},
+		{"failure_whitespace_category_string", "    ", "", 85.0, 0.0, "a category must be provided"},
+		{"failure_empty_category_string", "", "", 85.0, 0.0, "a category must be provided"},
+		{"failure_positive_score_out_of_bounds", "science", "", 105.0, 0.0, "score must be a value between 0 and 100"},
+		{"failure_negative_score_out_of_bounds", "science", "", -5.0, 0.0, "score must be a value between 0 and 100"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := CalculateComparison(tt.category, tt.newScore)
+			result, err := CalculateComparison(tt.category, tt.difficulty, tt.newScore)
 			if tt.expectedError != "" {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedError, err.Error())
@@ -241,43 +694,108 @@ func TestCalculateComparison(t *testing.T) {
 
 // TestAppendCategoryScore tests the AppendCategoryScore utility function
 func TestAppendCategoryScore(t *testing.T) {
-	// Save the original CategoryScores map to restore it later
-	originalCategoryScores := globals.CategoryScores
-	defer func() { globals.CategoryScores = originalCategoryScores }()
+	// Save the original score store to restore it later
+	originalScores := globals.Scores
+	defer func() { globals.Scores = originalScores }()
 
-	// Mock CategoryScores for testing
-	globals.CategoryScores = map[string][]float64{
+	// Mock the score store for testing
+	globals.Scores = newScoreStore(map[string][]float64{
 		"science": {50.0, 60.0, 70.0, 80.0, 90.0},
 		"math":    {20.0, 30.0, 40.0, 50.0, 60.0},
 		"music":   {},
-	}
+	})
 
 	tests := []struct {
-		name           string
-		category       string
-		newScore       float64
-		expectedError  string
-		expectedScores []float64
+		name          string
+		category      string
+		difficulty    string
+		newScore      float64
+		expectedError string
+		expectedStats scores.Stats
 	}{
-		{"success_science_category", "science", 75.0, "", []float64{50.0, 60.0, 70.0, 80.0, 90.0, 75.0}},
-		{"success_music_category_with_empty_slice", "music", 35.0, "", []float64{35.0}},
-		{"failure_invalid_category", "history", 85.0, "category 'history' does not exist", nil},
-		{"failure_empty_category_string", "", 85.0, "a category must be provided", nil},
-		{"failure_whitespace_category_string", "     ", 85.0, "a category must be provided", nil},
-		{"failure_positive_score_out_of_bounds", "science", 105.0, "score must be a value between 0 and 100", nil},
-		{"failure_negative_score_out_of_bounds", "science", -5.0, "score must be a value between 0 and 100", nil},
+		{"success_science_category", "science", "", 75.0, "", scores.Stats{Count: 6, Mean: 70.83333333333333, Median: 72.5, StdDev: 13.043729868748773, PassRate: 100, Lowest: 50.0, Highest: 90.0, Histogram: []int{0, 0, 0, 0, 0, 1, 1, 2, 1, 1}}},
+		{"success_music_category_with_empty_bucket", "music", "", 35.0, "", scores.Stats{Count: 1, Mean: 35.0, Median: 35.0, Lowest: 35.0, Highest: 35.0, Histogram: []int{0, 0, 0, 1, 0, 0, 0, 0, 0, 0}}},
+		{"success_new_bucket_for_difficulty", "science", "hard", 40.0, "", scores.Stats{Count: 1, Mean: 40.0, Median: 40.0, Lowest: 40.0, Highest: 40.0, Histogram: []int{0, 0, 0, 0, 1, 0, 0, 0, 0, 0}}},
+		{"failure_invalid_category", "history", "", 85.0, "category 'history' does not exist", scores.Stats{}},
+		{"failure_invalid_difficulty", "science", "extreme", 85.0, "difficulty 'extreme' does not exist", scores.Stats{}},
+		{"success_new_bucket_for_combination", "math+science", "", 60.0, "", scores.Stats{Count: 1, Mean: 60.0, Median: 60.0, PassRate: 100, Lowest: 60.0, Highest: 60.0, Histogram: []int{0, 0, 0, 0, 0, 0, 1, 0, 0, 0}}},
+		{"failure_invalid_category_within_combination", "history+science", "", 85.0, "category 'history' does not exist", scores.Stats{}},
+		{"failure_empty_category_string", "", "", 85.0, "a category must be provided", scores.Stats{}},
+		{"failure_whitespace_category_string", "     ", "", 85.0, "a category must be provided", scores.Stats{}},
+		{"failure_positive_score_out_of_bounds", "science", "", 105.0, "score must be a value between 0 and 100", scores.Stats{}},
+		{"failure_negative_score_out_of_bounds", "science", "", -5.0, "score must be a value between 0 and 100", scores.Stats{}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := AppendCategoryScore(tt.category, tt.newScore)
+			err := AppendCategoryScore(tt.category, tt.difficulty, tt.newScore)
 			if tt.expectedError != "" {
 				assert.Error(t, err)
 				assert.Equal(t, tt.expectedError, err.Error())
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedScores, globals.CategoryScores[tt.category])
+				stats, err := globals.Scores.Stats(scores.Bucket(tt.category, tt.difficulty))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStats, stats)
 			}
 		})
 	}
 }
+
+// TestCalculateLevelComparisons tests comparing scores at each level of the category tree
+func TestCalculateLevelComparisons(t *testing.T) {
+	// Save the original score store to restore it later
+	originalScores := globals.Scores
+	defer func() { globals.Scores = originalScores }()
+
+	globals.Scores = newScoreStore(map[string][]float64{
+		"science":         {20.0, 40.0, 60.0, 80.0},
+		"science/physics": {60.0, 80.0},
+		"music":           {40.0},
+	})
+
+	tests := []struct {
+		name          string
+		category      string
+		expected      []models.LevelComparison
+		expectedError string
+	}{
+		{"success_subcategory", "science/physics", []models.LevelComparison{{Category: "science/physics", Percentile: 50}, {Category: "science", Percentile: 75}}, ""},
+		{"success_top_level_category", "science", []models.LevelComparison{{Category: "science", Percentile: 75}}, ""},
+		{"success_combination", "music+science/physics", []models.LevelComparison{{Category: "music+science/physics", Percentile: 0}}, ""},
+		{"failure_invalid_subcategory", "science/optics", nil, "category 'science/optics' does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparisons, err := CalculateLevelComparisons(tt.category, "", 70.0)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, comparisons)
+			}
+		})
+	}
+
+	// Subcategory scores are counted at every level, while combinations are only counted as themselves
+	assert.NoError(t, AppendCategoryScore("science/physics", "hard", 70.0))
+	assert.NoError(t, AppendCategoryScore("music+science/physics", "", 70.0))
+	for bucket, expected := range map[string]int{"science/physics:hard": 1, "science:hard": 1, "science/physics": 2, "science": 4, "music+science/physics": 1} {
+		stats, err := globals.Scores.Stats(bucket)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, stats.Count, bucket)
+	}
+}
+
+// newScoreStore is a helper function which returns an in-memory score store populated with the specified scores
+func newScoreStore(categoryScores map[string][]float64) scores.ScoreStore {
+	store := scores.NewMemoryStore()
+	for category, categoryScores := range categoryScores {
+		store.AddCategory(category)
+		for _, score := range categoryScores {
+			store.Append(category, score)
+		}
+	}
+	return store
+}
@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"quizwizard/cli/config"
 	"quizwizard/cli/models"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
 
+var categoriesTree bool
+
 // categoriesCmd represents the categories command
 var categoriesCmd = &cobra.Command{
 	Use:   "categories",
@@ -20,7 +25,10 @@ var categoriesCmd = &cobra.Command{
 +++ QuizWizard Categories +++
 
 Reach out to the QuizWizard API to retrieve a 
-list of the latest quiz categories.
+list of the latest quiz categories, with their
+number of questions, difficulties and the usual
+length of their quizzes. Use --tree to show how
+categories are divided into subcategories.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		runCategoriesCommand()
@@ -29,6 +37,8 @@ list of the latest quiz categories.
 
 func init() {
 	rootCmd.AddCommand(categoriesCmd)
+
+	categoriesCmd.Flags().BoolVarP(&categoriesTree, "tree", "t", false, "Show the categories as a tree of subcategories")
 }
 
 // runCategoriesCommand will handle all of the steps required to fetch and display the categories
@@ -70,7 +80,7 @@ func fetchCategories(client *http.Client) (*models.CategoriesResponse, error) {
 	}
 
 	if !categoryResponse.Success {
-		return nil, fmt.Errorf("error within categories response: %s", categoryResponse.Message)
+		return nil, &apiError{response: "categories", code: categoryResponse.Code, message: categoryResponse.Message, details: categoryResponse.Details}
 	}
 
 	return &categoryResponse, nil
@@ -83,7 +93,7 @@ func displayCategories(categoryResponse *models.CategoriesResponse) error {
 	}
 
 	if !categoryResponse.Success {
-		return fmt.Errorf("error within categories response: %s", categoryResponse.Message)
+		return &apiError{response: "categories", code: categoryResponse.Code, message: categoryResponse.Message, details: categoryResponse.Details}
 	}
 
 	if len(categoryResponse.Categories) == 0 {
@@ -92,10 +102,87 @@ func displayCategories(categoryResponse *models.CategoriesResponse) error {
 	}
 
 	fmt.Println()
-	for i, category := range categoryResponse.Categories {
-		msg := fmt.Sprintf("%d. %s", i+1, category)
-		fmt.Println(msg)
+	if categoriesTree {
+		return renderCategoryTree(os.Stdout, categoryResponse.Categories)
+	}
+	return renderCategories(os.Stdout, categoryResponse.Categories)
+}
+
+// renderCategories writes the categories and their details as a table with aligned columns
+func renderCategories(w io.Writer, categories []models.Category) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tSLUG\tQUESTIONS\tDIFFICULTIES\tLENGTH\tDESCRIPTION")
+	for _, category := range categories {
+		difficulties := strings.Join(category.Difficulties, ", ")
+		if difficulties == "" {
+			difficulties = "-"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%d\t%s\n",
+			category.Name, category.Slug, category.QuestionCount, difficulties, category.DefaultQuestions, category.Description)
 	}
+	return table.Flush()
+}
 
+// renderCategoryTree writes each top level category followed by its subcategories, drawn as branches beneath it
+func renderCategoryTree(w io.Writer, categories []models.Category) error {
+	present := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		present[category.Slug] = true
+	}
+
+	// Categories whose parent is missing are shown at the top level rather than lost
+	children := make(map[string][]models.Category)
+	for _, category := range categories {
+		parent := category.Parent
+		if !present[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], category)
+	}
+
+	for _, category := range children[""] {
+		fmt.Fprintln(w, categoryTreeLabel(category))
+		writeCategoryBranches(w, children, category.Slug, "")
+	}
 	return nil
 }
+
+// writeCategoryBranches writes the subcategories of a category, indented by the branches of the levels above them
+func writeCategoryBranches(w io.Writer, children map[string][]models.Category, parent string, indent string) {
+	for i, category := range children[parent] {
+		branch, nextIndent := "├── ", indent+"│   "
+		if i == len(children[parent])-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		fmt.Fprintln(w, indent+branch+categoryTreeLabel(category))
+		writeCategoryBranches(w, children, category.Slug, nextIndent)
+	}
+}
+
+// categoryTreeLabel describes a category within the tree by its name, slug and number of questions
+func categoryTreeLabel(category models.Category) string {
+	questions := "questions"
+	if category.QuestionCount == 1 {
+		questions = "question"
+	}
+	return fmt.Sprintf("%s (%s) - %d %s", category.Name, category.Slug, category.QuestionCount, questions)
+}
+
+// resolveCategories replaces each category given by its display name with its slug, ignoring case.
+// Names which match no category are returned unchanged so that the API can report them.
+func resolveCategories(available []models.Category, names []string) []string {
+	resolved := make([]string, len(names))
+	for i, name := range names {
+		resolved[i] = name
+		trimmed := strings.TrimSpace(name)
+		for _, category := range available {
+			if strings.EqualFold(trimmed, category.Slug) || strings.EqualFold(trimmed, category.Name) {
+				resolved[i] = category.Slug
+				break
+			}
+		}
+	}
+	return resolved
+}
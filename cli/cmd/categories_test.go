@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -26,7 +27,7 @@ func TestFetchCategories(t *testing.T) {
 			w.Write([]byte(`{"success": false, "message": "API error"}`))
 		default:
 			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(`{"success": true, "message": "Categories retrieved successfully", "data": ["Science", "Math", "History"]}`))
+			w.Write([]byte(`{"success": true, "message": "Categories retrieved successfully", "data": [{"slug": "science", "name": "Science", "description": "The world around us.", "questionCount": 2, "difficulties": ["easy", "hard"], "defaultQuestions": 5}, {"slug": "random", "name": "Random", "questionCount": 2, "difficulties": ["easy", "hard"], "defaultQuestions": 5}]}`))
 		}
 	}))
 	defer mockServer.Close()
@@ -43,6 +44,11 @@ func TestFetchCategories(t *testing.T) {
 		},
 	}
 
+	expectedCategories := &models.CategoriesResponse{Success: true, Message: "Categories retrieved successfully", Categories: []models.Category{
+		{Slug: "science", Name: "Science", Description: "The world around us.", QuestionCount: 2, Difficulties: []string{"easy", "hard"}, DefaultQuestions: 5},
+		{Slug: "random", Name: "Random", QuestionCount: 2, Difficulties: []string{"easy", "hard"}, DefaultQuestions: 5},
+	}}
+
 	tests := []struct {
 		name           string
 		errorScenario  string
@@ -53,7 +59,7 @@ func TestFetchCategories(t *testing.T) {
 			name:           "successful_response",
 			errorScenario:  "",
 			expectedError:  "",
-			expectedResult: &models.CategoriesResponse{Success: true, Message: "Categories retrieved successfully", Categories: []string{"Science", "Math", "History"}},
+			expectedResult: expectedCategories,
 		},
 		{
 			name:           "failure_due_to_read_error",
@@ -111,7 +117,7 @@ func TestDisplayCategories(t *testing.T) {
 			input: &models.CategoriesResponse{
 				Success:    false,
 				Message:    "API error",
-				Categories: []string{},
+				Categories: []models.Category{},
 			},
 			expectedError: "error within categories response: API error",
 		},
@@ -129,3 +135,74 @@ func TestDisplayCategories(t *testing.T) {
 		})
 	}
 }
+
+// TestRenderCategories checks that categories and their details are written as an aligned table
+func TestRenderCategories(t *testing.T) {
+	categories := []models.Category{
+		{Slug: "computing", Name: "Computer Science", Description: "Programs and machines.", QuestionCount: 6, Difficulties: []string{"easy", "medium"}, DefaultQuestions: 10},
+		{Slug: "history", Name: "History", Difficulties: []string{}, DefaultQuestions: 5},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderCategories(&buf, categories))
+
+	expected := "NAME              SLUG       QUESTIONS  DIFFICULTIES  LENGTH  DESCRIPTION\n" +
+		"Computer Science  computing  6          easy, medium  10      Programs and machines.\n" +
+		"History           history    0          -             5       \n"
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestResolveCategories checks that categories may be chosen by their slugs or display names
+func TestResolveCategories(t *testing.T) {
+	available := []models.Category{
+		{Slug: "computing", Name: "Computer Science"},
+		{Slug: "music", Name: "Music"},
+		{Slug: "random", Name: "Random"},
+	}
+
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{"success_slugs", []string{"computing", "music"}, []string{"computing", "music"}},
+		{"success_display_names", []string{"Computer Science", " music "}, []string{"computing", "music"}},
+		{"success_ignores_case", []string{"COMPUTER science", "Random"}, []string{"computing", "random"}},
+		{"success_unknown_names_are_unchanged", []string{"Art History"}, []string{"Art History"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resolveCategories(available, tc.input))
+		})
+	}
+}
+
+// TestRenderCategoryTree checks that subcategories are drawn as branches beneath their parents
+func TestRenderCategoryTree(t *testing.T) {
+	categories := []models.Category{
+		{Slug: "computing", Name: "Computing", QuestionCount: 6},
+		{Slug: "science", Name: "Science", QuestionCount: 4},
+		{Slug: "science/biology", Name: "Biology", Parent: "science", QuestionCount: 1},
+		{Slug: "science/biology/cells", Name: "Cells", Parent: "science/biology", QuestionCount: 1},
+		{Slug: "science/physics", Name: "Physics", Parent: "science", QuestionCount: 3},
+		{Slug: "science/physics/optics", Name: "Optics", Parent: "science/physics", QuestionCount: 2},
+		{Slug: "science/physics/waves", Name: "Waves", Parent: "science/physics", QuestionCount: 1},
+		{Slug: "art/painting", Name: "Painting", Parent: "art", QuestionCount: 0},
+		{Slug: "random", Name: "Random", QuestionCount: 10},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderCategoryTree(&buf, categories))
+
+	expected := "Computing (computing) - 6 questions\n" +
+		"Science (science) - 4 questions\n" +
+		"├── Biology (science/biology) - 1 question\n" +
+		"│   └── Cells (science/biology/cells) - 1 question\n" +
+		"└── Physics (science/physics) - 3 questions\n" +
+		"    ├── Optics (science/physics/optics) - 2 questions\n" +
+		"    └── Waves (science/physics/waves) - 1 question\n" +
+		"Painting (art/painting) - 0 questions\n" +
+		"Random (random) - 10 questions\n"
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var historyPage int
+var historyLimit int
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the quizzes you have taken",
+	Long: `
++++ QuizWizard History +++
+
+Show the quizzes saved to your account, newest
+first, with how you answered each question.
+You must be logged in to see your history.
+
+Answers are marked + for correct, ~ for partly
+correct, x for wrong and - for skipped.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runHistoryCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().IntVarP(&historyPage, "page", "p", 1, "Specify the page of quizzes to show")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 10, "Specify the number of quizzes to show on each page")
+}
+
+// runHistoryCommand will handle all of the steps required to fetch and display the logged in user's history
+func runHistoryCommand() {
+	fmt.Println("\n+++ QuizWizard History +++")
+
+	client := &http.Client{}
+	historyResponse, err := fetchHistory(client)
+	if err != nil {
+		fmt.Println("\nFailed to fetch history: " + strings.TrimPrefix(err.Error(), "error within history response: "))
+		return
+	}
+
+	err = displayHistory(historyResponse)
+	if err != nil {
+		fmt.Println("\nFailed to display history: " + err.Error())
+		return
+	}
+}
+
+// fetchHistory retrieves a page of the logged in user's past quizzes from the API
+func fetchHistory(client *http.Client) (*models.HistoryResponse, error) {
+	query := neturl.Values{}
+	if historyPage != 0 {
+		query.Set("page", strconv.Itoa(historyPage))
+	}
+	if historyLimit != 0 {
+		query.Set("limit", strconv.Itoa(historyLimit))
+	}
+
+	url := config.ApiUrl + "/me/history"
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating history request: %v", err)
+	}
+
+	err = authorise(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making history request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading history response: %v", err)
+	}
+
+	var historyResponse models.HistoryResponse
+	err = json.Unmarshal([]byte(body), &historyResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling history response: %v", err)
+	}
+
+	if !historyResponse.Success {
+		return nil, &apiError{response: "history", code: historyResponse.Code, message: historyResponse.Message, details: historyResponse.Details}
+	}
+
+	return &historyResponse, nil
+}
+
+// displayHistory outputs a page of past quizzes as a table
+func displayHistory(historyResponse *models.HistoryResponse) error {
+	if historyResponse == nil {
+		return errors.New("history response is nil")
+	}
+
+	if !historyResponse.Success {
+		return &apiError{response: "history", code: historyResponse.Code, message: historyResponse.Message, details: historyResponse.Details}
+	}
+
+	history := historyResponse.History
+	if history.Total == 0 {
+		fmt.Println("\nYou have not saved any quizzes yet")
+		return nil
+	}
+
+	pages := 1
+	if history.Limit > 0 {
+		pages = (history.Total + history.Limit - 1) / history.Limit
+	}
+	fmt.Printf("\nPage %d of %d (%d quizzes)\n\n", history.Page, pages, history.Total)
+
+	if len(history.Results) == 0 {
+		fmt.Printf("There are no quizzes on page %d\n", history.Page)
+		return nil
+	}
+
+	err := renderHistory(os.Stdout, history)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n+ correct  ~ partly correct  x wrong  - skipped")
+	return nil
+}
+
+// renderHistory writes past quizzes as a table with aligned columns and a mark for each answer
+func renderHistory(w io.Writer, history models.History) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "DATE\tCATEGORY\tSCORE\tANSWERS")
+	for _, quiz := range history.Results {
+		category := quiz.Category
+		if quiz.Difficulty != "" {
+			category += " (" + quiz.Difficulty + ")"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s (%.0f%%)\t%s\n",
+			quiz.CreatedAt.Local().Format("2006-01-02 15:04"), category, quiz.ScoreString, quiz.Score, outcomeMarks(quiz.Outcomes))
+	}
+	return table.Flush()
+}
+
+// outcomeMarks returns a mark for each answered question, in the order the questions were asked
+func outcomeMarks(outcomes []models.Outcome) string {
+	var marks strings.Builder
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.Skipped:
+			marks.WriteString("-")
+		case outcome.Credit >= 1:
+			marks.WriteString("+")
+		case outcome.Credit > 0:
+			marks.WriteString("~")
+		default:
+			marks.WriteString("x")
+		}
+	}
+	return marks.String()
+}
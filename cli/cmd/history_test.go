@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFetchHistory tests the fetchHistory function
+func TestFetchHistory(t *testing.T) {
+	var query url.Values
+	var authorization string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		authorization = r.Header.Get("Authorization")
+		switch r.Header.Get("X-Error-Scenario") {
+		case "unmarshal_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("asdasda"))
+		case "api_error":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success": false, "message": "An access token must be provided. Please log in first."}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": true, "message": "History retrieved successfully.", "data": {"page": 2, "limit": 5, "total": 6, "results": [{"category": "science", "score": 50, "scoreString": "1/2", "outcomes": [{"questionId": 1, "category": "science", "question": "What is the chemical symbol for water?", "credit": 1, "points": 1, "available": 1}, {"questionId": 2, "category": "science", "question": "What planet is known as the Red Planet?", "credit": 0, "skipped": true, "points": 0, "available": 1}], "createdAt": "2024-01-01T12:00:00Z"}]}}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	originalFile := config.CredentialsFile
+	config.CredentialsFile = filepath.Join(t.TempDir(), "quizwizard", "credentials.json")
+	defer func() { config.CredentialsFile = originalFile }()
+	assert.NoError(t, config.SaveCredentials(config.Credentials{Username: "ada", Token: "abc.def.ghi", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	originalPage, originalLimit := historyPage, historyLimit
+	historyPage, historyLimit = 2, 5
+	defer func() { historyPage, historyLimit = originalPage, originalLimit }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(mockServer.URL)
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		errorScenario string
+		expectedError string
+	}{
+		{"successful_response", "", ""},
+		{"failure_due_to_unmarshal_error", "unmarshal_error", "error unmarshaling history response"},
+		{"failure_due_to_api_error", "api_error", "error within history response: An access token must be provided. Please log in first."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client.Transport.(*http.Transport).Proxy = func(req *http.Request) (*url.URL, error) {
+				req.Header.Set("X-Error-Scenario", tc.errorScenario)
+				return url.Parse(mockServer.URL)
+			}
+
+			historyResponse, err := fetchHistory(client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "2", query.Get("page"))
+			assert.Equal(t, "5", query.Get("limit"))
+			assert.Equal(t, "Bearer abc.def.ghi", authorization)
+			assert.Equal(t, 6, historyResponse.History.Total)
+			if assert.Len(t, historyResponse.History.Results, 1) {
+				assert.Equal(t, "+-", outcomeMarks(historyResponse.History.Results[0].Outcomes))
+			}
+		})
+	}
+}
+
+// TestDisplayHistory tests the displayHistory function
+func TestDisplayHistory(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         *models.HistoryResponse
+		expectedError string
+	}{
+		{"failure_due_to_nil_history_response", nil, "history response is nil"},
+		{"failure_due_to_unsuccessful_response", &models.HistoryResponse{Success: false, Message: "API error"}, "error within history response: API error"},
+		{"success_no_quizzes", &models.HistoryResponse{Success: true, History: models.History{Page: 1, Limit: 10}}, ""},
+		{"success_beyond_last_page", &models.HistoryResponse{Success: true, History: models.History{Page: 3, Limit: 10, Total: 12}}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := displayHistory(tc.input)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestRenderHistory checks that past quizzes are written as a table with a mark for each answer
+func TestRenderHistory(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 30, 0, 0, time.Local)
+	history := models.History{
+		Page:  1,
+		Limit: 10,
+		Total: 2,
+		Results: []models.PastQuiz{
+			{Category: "science", Difficulty: "hard", Score: 62.5, ScoreString: "2.5/4", CreatedAt: createdAt, Outcomes: []models.Outcome{
+				{Credit: 1}, {Credit: 0.5}, {Credit: 0}, {Credit: 1},
+			}},
+			{Category: "computing+music", Score: 0, ScoreString: "0/2", CreatedAt: createdAt, Outcomes: []models.Outcome{
+				{Skipped: true}, {Credit: 0},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderHistory(&buf, history))
+
+	expected := "DATE              CATEGORY         SCORE        ANSWERS\n" +
+		"2024-01-01 12:30  science (hard)   2.5/4 (62%)  +~x+\n" +
+		"2024-01-01 12:30  computing+music  0/2 (0%)     -x\n"
+	assert.Equal(t, expected, buf.String())
+}
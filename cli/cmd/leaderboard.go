@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var leaderboardCategory string
+var leaderboardPeriod string
+var leaderboardLimit int
+
+// leaderboardCmd represents the leaderboard command
+var leaderboardCmd = &cobra.Command{
+	Use:   "leaderboard",
+	Short: "Show the best scores of named players",
+	Long: `
++++ QuizWizard Leaderboard +++
+
+Show the best score of each named player, either
+for every category or for a specified category or
+combination of categories. Scores can be limited
+to the last day, week or month.
+
+Players with the same score share a rank. Give a
+player name when starting a quiz to appear on
+the leaderboard.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runLeaderboardCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(leaderboardCmd)
+
+	leaderboardCmd.Flags().StringVarP(&leaderboardCategory, "category", "c", "", "Specify the category, or comma separated categories, to rank")
+	leaderboardCmd.Flags().StringVarP(&leaderboardPeriod, "period", "p", "all", "Specify the period to rank (day, week, month or all)")
+	leaderboardCmd.Flags().IntVarP(&leaderboardLimit, "limit", "n", 10, "Specify the number of places to show")
+}
+
+// runLeaderboardCommand will handle all of the steps required to fetch and display the leaderboard
+func runLeaderboardCommand() {
+	fmt.Println("\n+++ QuizWizard Leaderboard +++")
+
+	client := &http.Client{}
+	leaderboardResponse, err := fetchLeaderboard(client)
+	if err != nil {
+		fmt.Println("\nFailed to fetch leaderboard: " + strings.TrimPrefix(err.Error(), "error within leaderboard response: "))
+		return
+	}
+
+	err = displayLeaderboard(leaderboardResponse)
+	if err != nil {
+		fmt.Println("\nFailed to display leaderboard: " + err.Error())
+		return
+	}
+}
+
+// fetchLeaderboard retrieves the leaderboard from the API for the selected category, period and limit
+func fetchLeaderboard(client *http.Client) (*models.LeaderboardResponse, error) {
+	query := neturl.Values{}
+	if category := strings.TrimSpace(leaderboardCategory); category != "" {
+		query.Set("category", strings.ToLower(category))
+	}
+	if period := strings.TrimSpace(leaderboardPeriod); period != "" {
+		query.Set("period", strings.ToLower(period))
+	}
+	if leaderboardLimit != 0 {
+		query.Set("limit", strconv.Itoa(leaderboardLimit))
+	}
+
+	url := config.ApiUrl + "/leaderboard"
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error making leaderboard request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading leaderboard response: %v", err)
+	}
+
+	var leaderboardResponse models.LeaderboardResponse
+	err = json.Unmarshal([]byte(body), &leaderboardResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling leaderboard response: %v", err)
+	}
+
+	if !leaderboardResponse.Success {
+		return nil, &apiError{response: "leaderboard", code: leaderboardResponse.Code, message: leaderboardResponse.Message, details: leaderboardResponse.Details}
+	}
+
+	return &leaderboardResponse, nil
+}
+
+// displayLeaderboard outputs the leaderboard as a table
+func displayLeaderboard(leaderboardResponse *models.LeaderboardResponse) error {
+	if leaderboardResponse == nil {
+		return errors.New("leaderboard response is nil")
+	}
+
+	if !leaderboardResponse.Success {
+		return &apiError{response: "leaderboard", code: leaderboardResponse.Code, message: leaderboardResponse.Message, details: leaderboardResponse.Details}
+	}
+
+	leaderboard := leaderboardResponse.Leaderboard
+	category := leaderboard.Category
+	if category == "" {
+		category = "every category"
+	}
+	fmt.Printf("\nBest scores for %s (%s)\n\n", category, leaderboard.Period)
+
+	if len(leaderboard.Rankings) == 0 {
+		fmt.Println("No named players have been ranked yet")
+		return nil
+	}
+
+	return renderLeaderboard(os.Stdout, leaderboard)
+}
+
+// renderLeaderboard writes the rankings of a leaderboard as a table with aligned columns
+func renderLeaderboard(w io.Writer, leaderboard models.Leaderboard) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "RANK\tPLAYER\tSCORE\tCATEGORY\tDATE")
+	for _, ranking := range leaderboard.Rankings {
+		category := ranking.Category
+		if ranking.Difficulty != "" {
+			category += " (" + ranking.Difficulty + ")"
+		}
+
+		fmt.Fprintf(table, "%d\t%s\t%s (%.0f%%)\t%s\t%s\n",
+			ranking.Rank, ranking.Player, ranking.ScoreString, ranking.Score, category, ranking.CreatedAt.Local().Format("2006-01-02"))
+	}
+	return table.Flush()
+}
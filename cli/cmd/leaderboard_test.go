@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFetchLeaderboard tests the fetchLeaderboard function
+func TestFetchLeaderboard(t *testing.T) {
+	var query url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		switch r.Header.Get("X-Error-Scenario") {
+		case "unmarshal_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("asdasda"))
+		case "api_error":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success": false, "message": "year is not a valid period. Please choose day, week, month, all."}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": true, "message": "Leaderboard retrieved successfully.", "data": {"category": "science", "period": "week", "rankings": [{"rank": 1, "player": "ada", "category": "science", "score": 80, "scoreString": "4/5", "createdAt": "2024-01-01T12:00:00Z"}]}}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	originalCategory, originalPeriod, originalLimit := leaderboardCategory, leaderboardPeriod, leaderboardLimit
+	leaderboardCategory, leaderboardPeriod, leaderboardLimit = " Science ", "WEEK", 5
+	defer func() {
+		leaderboardCategory, leaderboardPeriod, leaderboardLimit = originalCategory, originalPeriod, originalLimit
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(mockServer.URL)
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		errorScenario string
+		expectedError string
+	}{
+		{"successful_response", "", ""},
+		{"failure_due_to_unmarshal_error", "unmarshal_error", "error unmarshaling leaderboard response"},
+		{"failure_due_to_api_error", "api_error", "error within leaderboard response: year is not a valid period. Please choose day, week, month, all."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client.Transport.(*http.Transport).Proxy = func(req *http.Request) (*url.URL, error) {
+				req.Header.Set("X-Error-Scenario", tc.errorScenario)
+				return url.Parse(mockServer.URL)
+			}
+
+			leaderboardResponse, err := fetchLeaderboard(client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "science", query.Get("category"))
+			assert.Equal(t, "week", query.Get("period"))
+			assert.Equal(t, "5", query.Get("limit"))
+			assert.Equal(t, []models.Ranking{
+				{Rank: 1, Player: "ada", Category: "science", Score: 80, ScoreString: "4/5", CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+			}, leaderboardResponse.Leaderboard.Rankings)
+		})
+	}
+}
+
+// TestDisplayLeaderboard tests the displayLeaderboard function
+func TestDisplayLeaderboard(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         *models.LeaderboardResponse
+		expectedError string
+	}{
+		{"failure_due_to_nil_leaderboard_response", nil, "leaderboard response is nil"},
+		{"failure_due_to_unsuccessful_response", &models.LeaderboardResponse{Success: false, Message: "API error"}, "error within leaderboard response: API error"},
+		{"success_empty_leaderboard", &models.LeaderboardResponse{Success: true, Leaderboard: models.Leaderboard{Period: "all"}}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := displayLeaderboard(tc.input)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestRenderLeaderboard checks that the rankings are written as a table with aligned columns
+func TestRenderLeaderboard(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	leaderboard := models.Leaderboard{
+		Period: "all",
+		Rankings: []models.Ranking{
+			{Rank: 1, Player: "Ada Lovelace", Category: "science", Difficulty: "hard", Score: 100, ScoreString: "5/5", CreatedAt: createdAt},
+			{Rank: 2, Player: "bob", Category: "music", Score: 80, ScoreString: "4/5", CreatedAt: createdAt},
+			{Rank: 2, Player: "cy", Category: "computing+music", Score: 80, ScoreString: "8/10", CreatedAt: createdAt},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderLeaderboard(&buf, leaderboard))
+
+	expected := "RANK  PLAYER        SCORE       CATEGORY         DATE\n" +
+		"1     Ada Lovelace  5/5 (100%)  science (hard)   2024-01-01\n" +
+		"2     bob           4/5 (80%)   music            2024-01-01\n" +
+		"2     cy            8/10 (80%)  computing+music  2024-01-01\n"
+	assert.Equal(t, expected, buf.String())
+}
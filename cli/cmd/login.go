@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var loginUsername string
+var loginRegister bool
+
+// loginCmd represents the login command
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to save your quiz results",
+	Long: `
++++ QuizWizard Login +++
+
+Log in with your username and password so that
+the quizzes you take are saved to your account.
+Use the register flag to create an account first.
+
+Your access token is stored in your user config
+directory until you run the 'logout' command or
+it expires.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runLoginCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().StringVarP(&loginUsername, "username", "u", "", "Specify the username to log in as")
+	loginCmd.Flags().BoolVarP(&loginRegister, "register", "r", false, "Create an account before logging in")
+}
+
+// runLoginCommand will handle all of the steps required to log in and store the access token
+func runLoginCommand() {
+	fmt.Println("\n+++ QuizWizard Login +++")
+
+	reader := bufio.NewReader(os.Stdin)
+	username := strings.TrimSpace(loginUsername)
+	if username == "" {
+		username = readCredential(reader, "\nUsername: ", false)
+	}
+	password := readCredential(reader, "Password: ", true)
+	credentials := models.Credentials{Username: username, Password: password}
+
+	client := &http.Client{}
+	if loginRegister {
+		registerResponse, err := register(&credentials, client)
+		if err != nil {
+			fmt.Println("\nFailed to register: " + strings.TrimPrefix(err.Error(), "error within register response: "))
+			return
+		}
+		fmt.Println("\nAccount " + registerResponse.Account.Username + " registered successfully.")
+	}
+
+	loginResponse, err := login(&credentials, client)
+	if err != nil {
+		fmt.Println("\nFailed to log in: " + strings.TrimPrefix(err.Error(), "error within login response: "))
+		return
+	}
+
+	token := loginResponse.AccessToken
+	err = config.SaveCredentials(config.Credentials{Username: token.Username, Token: token.Token, ExpiresAt: token.ExpiresAt})
+	if err != nil {
+		fmt.Println("\nFailed to store login: " + err.Error())
+		return
+	}
+
+	fmt.Println("\nLogged in as " + token.Username + ".")
+	fmt.Println("Your login expires at " + token.ExpiresAt.Local().Format("2006-01-02 15:04") + ".")
+}
+
+// readCredential prints a prompt and returns the line entered. Hidden input is not echoed when reading from a terminal.
+func readCredential(reader *bufio.Reader, prompt string, hidden bool) string {
+	fmt.Print(prompt)
+
+	if hidden && term.IsTerminal(int(os.Stdin.Fd())) {
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return ""
+		}
+		return string(password)
+	}
+
+	line, _ := reader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
+
+// register creates an account with the API
+func register(credentials *models.Credentials, client *http.Client) (*models.RegisterResponse, error) {
+	if credentials == nil {
+		return nil, errors.New("credentials are nil")
+	}
+
+	body, err := postCredentials(config.ApiUrl+"/register", credentials, client)
+	if err != nil {
+		return nil, fmt.Errorf("error sending register request: %v", err)
+	}
+
+	var registerResponse models.RegisterResponse
+	err = json.Unmarshal(body, &registerResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling register response: %v", err)
+	}
+
+	if !registerResponse.Success {
+		return nil, &apiError{response: "register", code: registerResponse.Code, message: registerResponse.Message, details: registerResponse.Details}
+	}
+
+	return &registerResponse, nil
+}
+
+// login exchanges a username and password for an access token from the API
+func login(credentials *models.Credentials, client *http.Client) (*models.LoginResponse, error) {
+	if credentials == nil {
+		return nil, errors.New("credentials are nil")
+	}
+
+	body, err := postCredentials(config.ApiUrl+"/login", credentials, client)
+	if err != nil {
+		return nil, fmt.Errorf("error sending login request: %v", err)
+	}
+
+	var loginResponse models.LoginResponse
+	err = json.Unmarshal(body, &loginResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling login response: %v", err)
+	}
+
+	if !loginResponse.Success {
+		return nil, &apiError{response: "login", code: loginResponse.Code, message: loginResponse.Message, details: loginResponse.Details}
+	}
+
+	return &loginResponse, nil
+}
+
+// postCredentials sends a username and password to an API endpoint and returns the response body
+func postCredentials(url string, credentials *models.Credentials, client *http.Client) ([]byte, error) {
+	jsonData, err := json.Marshal(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
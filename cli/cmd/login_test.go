@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newCredentialsServer is a helper function which returns a mock server for the register and login endpoints,
+// along with a client which sends the error scenario for each request
+func newCredentialsServer(t *testing.T, scenario *string) *http.Client {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-Error-Scenario") {
+		case "unmarshal_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("asdasda"))
+		case "api_error":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success": false, "message": "Incorrect username or password."}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			if r.URL.Path == "/register" {
+				w.Write([]byte(`{"success": true, "message": "Account ada registered successfully.", "data": {"id": 1, "username": "ada", "createdAt": "2024-01-01T12:00:00Z"}}`))
+			} else {
+				w.Write([]byte(`{"success": true, "message": "Logged in successfully.", "data": {"token": "abc.def.ghi", "username": "ada", "expiresAt": "2024-01-02T12:00:00Z"}}`))
+			}
+		}
+	}))
+	t.Cleanup(mockServer.Close)
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	t.Cleanup(func() { config.ApiUrl = originalApiUrl })
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				req.Header.Set("X-Error-Scenario", *scenario)
+				return url.Parse(mockServer.URL)
+			},
+		},
+	}
+}
+
+// TestRegister tests the register function
+func TestRegister(t *testing.T) {
+	var scenario string
+	client := newCredentialsServer(t, &scenario)
+
+	tests := []struct {
+		name          string
+		credentials   *models.Credentials
+		errorScenario string
+		expectedError string
+	}{
+		{"successful_response", &models.Credentials{Username: "ada", Password: "correct horse"}, "", ""},
+		{"failure_due_to_nil_credentials", nil, "", "credentials are nil"},
+		{"failure_due_to_unmarshal_error", &models.Credentials{Username: "ada"}, "unmarshal_error", "error unmarshaling register response"},
+		{"failure_due_to_api_error", &models.Credentials{Username: "ada"}, "api_error", "error within register response: Incorrect username or password."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scenario = tc.errorScenario
+
+			registerResponse, err := register(tc.credentials, client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "ada", registerResponse.Account.Username)
+		})
+	}
+}
+
+// TestLogin tests the login function
+func TestLogin(t *testing.T) {
+	var scenario string
+	client := newCredentialsServer(t, &scenario)
+
+	tests := []struct {
+		name          string
+		credentials   *models.Credentials
+		errorScenario string
+		expectedError string
+	}{
+		{"successful_response", &models.Credentials{Username: "ada", Password: "correct horse"}, "", ""},
+		{"failure_due_to_nil_credentials", nil, "", "credentials are nil"},
+		{"failure_due_to_unmarshal_error", &models.Credentials{Username: "ada"}, "unmarshal_error", "error unmarshaling login response"},
+		{"failure_due_to_api_error", &models.Credentials{Username: "ada"}, "api_error", "error within login response: Incorrect username or password."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scenario = tc.errorScenario
+
+			loginResponse, err := login(tc.credentials, client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "abc.def.ghi", loginResponse.AccessToken.Token)
+			assert.Equal(t, "ada", loginResponse.AccessToken.Username)
+			assert.Equal(t, time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), loginResponse.AccessToken.ExpiresAt)
+		})
+	}
+}
+
+// TestAuthorise checks that only a stored access token which has not expired is sent
+func TestAuthorise(t *testing.T) {
+	originalFile := config.CredentialsFile
+	config.CredentialsFile = filepath.Join(t.TempDir(), "quizwizard", "credentials.json")
+	defer func() { config.CredentialsFile = originalFile }()
+
+	tests := []struct {
+		name        string
+		credentials *config.Credentials
+		expected    string
+	}{
+		{"success_not_logged_in", nil, ""},
+		{"success_logged_in", &config.Credentials{Username: "ada", Token: "abc.def.ghi", ExpiresAt: time.Now().Add(time.Hour)}, "Bearer abc.def.ghi"},
+		{"success_expired_login", &config.Credentials{Username: "ada", Token: "abc.def.ghi", ExpiresAt: time.Now().Add(-time.Hour)}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.credentials != nil {
+				assert.NoError(t, config.SaveCredentials(*tc.credentials))
+			}
+
+			req, _ := http.NewRequest("GET", "http://localhost/questions", nil)
+			assert.NoError(t, authorise(req))
+			assert.Equal(t, tc.expected, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+// TestReadCredential checks that input which is not from a terminal is read a line at a time
+func TestReadCredential(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("ada\r\ncorrect horse \n"))
+
+	assert.Equal(t, "ada", readCredential(reader, "", false))
+	assert.Equal(t, "correct horse ", readCredential(reader, "", true), "Expected passwords to keep their spaces")
+	assert.Equal(t, "", readCredential(reader, "", false))
+}
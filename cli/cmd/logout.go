@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"quizwizard/cli/config"
+
+	"github.com/spf13/cobra"
+)
+
+// logoutCmd represents the logout command
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out and forget your access token",
+	Long: `
++++ QuizWizard Logout +++
+
+Remove the stored access token. Quizzes taken
+after logging out are not saved to any account.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runLogoutCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
+
+// runLogoutCommand removes the stored access token
+func runLogoutCommand() {
+	fmt.Println("\n+++ QuizWizard Logout +++")
+
+	removed, err := config.DeleteCredentials()
+	if err != nil {
+		fmt.Println("\nFailed to log out: " + err.Error())
+		return
+	}
+
+	if !removed {
+		fmt.Println("\nYou are not logged in.")
+		return
+	}
+	fmt.Println("\nLogged out successfully.")
+}
@@ -7,17 +7,36 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"quizwizard/cli/config"
 	"quizwizard/cli/models"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
-var category string
+var categories []string
+var difficulty string
+var count int
+var playerName string
+
+// answerDeadline is when the answer to the current question is due, or zero if it is untimed
+var answerDeadline time.Time
+
+// inputLines delivers each line entered by the user. A single goroutine reads the input so that a prompt which
+// runs out of time does not lose the next line entered.
+var inputLines chan string
+var startInput sync.Once
+
+// errNoQuestions is returned when a quiz is issued without any questions
+var errNoQuestions = errors.New("no questions available")
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
@@ -26,10 +45,20 @@ var startCmd = &cobra.Command{
 	Long: `
 +++ QuizWizard Start +++
 
-Start the quiz and optionally specify a category.
+Start the quiz and optionally specify a category
+and a difficulty (easy, medium or hard). Repeat
+the category flag to mix several categories into
+one quiz.
+
+Give a player name to have your score ranked on
+the leaderboard. Run the 'login' command first to
+save your results to your account.
 
 If no category is specified, "Random" will be
-selected by default.
+selected by default. If no difficulty is specified,
+questions of every difficulty are included. If no
+count is specified, the API chooses how many
+questions to ask.
 
 Run the 'categories' command to retrieve a list
 of the latest categories.
@@ -42,46 +71,51 @@ of the latest categories.
 func init() {
 	rootCmd.AddCommand(startCmd)
 
-	startCmd.Flags().StringVarP(&category, "category", "c", "random", "Specify the category for the quiz")
+	startCmd.Flags().StringSliceVarP(&categories, "category", "c", []string{"random"}, "Specify the category for the quiz, repeat to combine categories")
+	startCmd.Flags().StringVarP(&difficulty, "difficulty", "d", "", "Specify the difficulty for the quiz (easy, medium or hard)")
+	startCmd.Flags().IntVarP(&count, "count", "n", 0, "Specify the number of questions for the quiz")
+	startCmd.Flags().StringVarP(&playerName, "name", "p", "", "Specify a player name to appear on the leaderboard")
 }
 
 // startQuiz will handle all of the steps required to take the quiz and display the results
 func startQuiz() {
 	fmt.Println("\n+++ QuizWizard Starting +++")
 
+	credentials, err := config.LoadCredentials()
+	if err != nil {
+		fmt.Println("\nFailed to load login: " + err.Error())
+		return
+	}
+	if credentials.Token != "" && credentials.Expired(time.Now()) {
+		fmt.Println("\nYour login has expired, so this quiz will not be saved to your account.")
+		fmt.Println("Run the 'login' command to log in again.")
+	} else if credentials.Token != "" {
+		fmt.Println("\nPlaying as " + credentials.Username + ".")
+	}
+
 	client := &http.Client{}
 
-	questionsResponse, err := fetchQuestions(client)
+	// Categories may be chosen by their display names as well as their slugs
+	categoryResponse, err := fetchCategories(client)
 	if err != nil {
-		invalidCategoryError := strings.Contains(err.Error(), "is not a valid category")
-		noQuestionsAvailableError := strings.Contains(err.Error(), "no questions available")
-
-		if invalidCategoryError {
-			msg := "\nFailure: " + category + " is not a valid category."
-			msg += "\n\nUse the 'categories' command for a list of available categories."
-			fmt.Println(msg)
-			return
-		} else if noQuestionsAvailableError {
-			msg := "\nCurrently there are no questions available for the " + category + " category."
-			msg += "\n\nPlease choose a different category or try again later."
-			fmt.Println(msg)
-			return
-		} else {
-			fmt.Println("\nFailed to fetch questions: " + err.Error())
-			return
-		}
+		fmt.Println("\nFailed to fetch categories: " + err.Error())
+		return
 	}
+	categories = resolveCategories(categoryResponse.Categories, categories)
 
-	quizSubmission, err := runQuiz(questionsResponse)
+	questionsResponse, err := fetchQuestions(client)
 	if err != nil {
-		noQuestionsAvailableError := strings.Contains(err.Error(), "no questions available")
-		if noQuestionsAvailableError {
-			fmt.Println(err.Error())
-			return
-		} else {
-			fmt.Println("\nFailed to display questions: " + err.Error())
-			return
-		}
+		fmt.Println(fetchQuestionsFailure(err))
+		return
+	}
+
+	quizSubmission, err := runQuiz(questionsResponse, client)
+	if errors.Is(err, errNoQuestions) {
+		fmt.Println(noQuestionsMessage())
+		return
+	} else if err != nil {
+		fmt.Println("\nFailed to display questions: " + err.Error())
+		return
 	}
 
 	results, err := submitQuiz(quizSubmission, client)
@@ -97,13 +131,33 @@ func startQuiz() {
 	}
 }
 
-// fetchQuestions retrieves the questions from the API for a specified category
+// fetchQuestions retrieves the questions from the API for the specified categories and optional difficulty
 func fetchQuestions(client *http.Client) (*models.QuestionsResponse, error) {
-	category = strings.Trim(category, " ")
-	category = strings.ToLower(category)
+	for i := range categories {
+		categories[i] = strings.Trim(categories[i], " ")
+		categories[i] = strings.ToLower(categories[i])
+	}
+	difficulty = strings.Trim(difficulty, " ")
+	difficulty = strings.ToLower(difficulty)
 
-	url := config.ApiUrl + "/questions?category=" + category
-	resp, err := client.Get(url)
+	url := config.ApiUrl + "/questions?category=" + neturl.QueryEscape(strings.Join(categories, ","))
+	if difficulty != "" {
+		url += "&" + neturl.Values{"difficulty": {difficulty}}.Encode()
+	}
+	if count != 0 {
+		url += "&count=" + strconv.Itoa(count)
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating fetch questions request: %v", err)
+	}
+
+	err = authorise(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error making fetch questions request: %v", err)
 	}
@@ -121,69 +175,151 @@ func fetchQuestions(client *http.Client) (*models.QuestionsResponse, error) {
 	}
 
 	if !questionsResponse.Success {
-		return nil, fmt.Errorf("error within fetch questions response: %s", questionsResponse.Message)
+		return nil, &apiError{response: "fetch questions", code: questionsResponse.Code, message: questionsResponse.Message, details: questionsResponse.Details}
 	}
 
 	return &questionsResponse, nil
 }
 
+// fetchQuestionsFailure explains why the questions for the quiz could not be fetched, using the error code returned by
+// the API
+func fetchQuestionsFailure(err error) string {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		return "\nFailed to fetch questions: " + err.Error()
+	}
+
+	switch {
+	case apiErr.code == models.CodeCategoryNotFound:
+		msg := "\nFailure: " + apiErr.message
+		msg += "\n\nUse the 'categories' command for a list of available categories."
+		return msg
+	case apiErr.code == models.CodeValidationFailed && apiErr.hasField("difficulty"):
+		msg := "\nFailure: " + difficulty + " is not a valid difficulty."
+		msg += "\n\nPlease choose easy, medium or hard."
+		return msg
+	case apiErr.code == models.CodeValidationFailed:
+		return "\nFailure: " + apiErr.message
+	case apiErr.code == models.CodeInvalidCombination:
+		msg := "\nFailure: random cannot be combined with other categories."
+		msg += "\n\nPlease choose either random or a list of categories."
+		return msg
+	case apiErr.code == models.CodeNoQuestions:
+		return noQuestionsMessage()
+	default:
+		return "\nFailed to fetch questions: " + err.Error()
+	}
+}
+
+// noQuestionsMessage explains that the chosen quiz has no questions
+func noQuestionsMessage() string {
+	msg := "\nCurrently there are no questions available for the " + quizName() + " category."
+	msg += "\n\nPlease choose a different category or try again later."
+	return msg
+}
+
 // runQuiz allows the user to take the quiz using an interactive interface
-func runQuiz(questionsResponse *models.QuestionsResponse) (*models.QuizSubmission, error) {
+func runQuiz(questionsResponse *models.QuestionsResponse, client *http.Client) (*models.QuizSubmission, error) {
 	if questionsResponse == nil {
 		return nil, errors.New("fetch questions response is nil")
 	}
 
 	if !questionsResponse.Success {
-		return nil, fmt.Errorf("error within fetch questions response: %s", questionsResponse.Message)
+		return nil, &apiError{response: "fetch questions", code: questionsResponse.Code, message: questionsResponse.Message, details: questionsResponse.Details}
 	}
 
-	if len(questionsResponse.Questions) == 0 {
-		msg := "\nCurrently there are no questions available for the " + category + " category."
-		msg += "\n\nPlease choose a different category or try again later."
-		return nil, errors.New(msg)
+	questions := questionsResponse.Quiz.Questions
+	if len(questions) == 0 {
+		return nil, errNoQuestions
 	}
 
 	submission := models.QuizSubmission{
-		Category:          category,
-		QuestionResponses: make([]models.QuestionAnswer, 0, len(questionsResponse.Questions)),
+		SessionID:         questionsResponse.Quiz.SessionID,
+		PlayerName:        strings.TrimSpace(playerName),
+		QuestionResponses: make([]models.QuestionAnswer, 0, len(questions)),
 	}
 
-	fmt.Println("\nYou have selected the " + category + " category.")
-	fmt.Printf("Please answer all %d questions.\n", len(questionsResponse.Questions))
+	fmt.Println("\nYou have selected the " + quizName() + " category.")
+	fmt.Printf("Please answer all %d questions.\n", len(questions))
 
-	for i, question := range questionsResponse.Questions {
-		fmt.Printf("\n+++ Question %d: %s +++\n\n", i+1, question.Question)
+	for i, question := range questions {
+		fmt.Printf("\n+++ Question %d: %s +++\n", i+1, question.Question)
 
-		for i, answer := range question.Answers {
-			fmt.Printf("%d. %s\n", i+1, answer)
+		answerDeadline = time.Time{}
+		if question.TimeLimit > 0 {
+			fmt.Printf("You have %d seconds to answer.\n", question.TimeLimit)
+			answerDeadline = time.Now().Add(time.Duration(question.TimeLimit) * time.Second)
 		}
 
-		userAnswer, err := promptUser()
-		if err != nil {
-			userAnswer = -1
+		qa := promptAnswer(question)
+
+		// Anything entered after the time limit is discarded in favour of a blank answer
+		if !answerDeadline.IsZero() && !time.Now().Before(answerDeadline) {
+			fmt.Println("\nTime's up!")
+			qa = models.QuestionAnswer{QuestionID: question.ID, Answer: -1}
 		}
-		userAnswer--
+		answerDeadline = time.Time{}
 
-		if userAnswer == question.CorrectAnswerIndex {
-			fmt.Println("\nCorrect! " + question.Answers[question.CorrectAnswerIndex] + " is the right answer.")
-		} else if userAnswer >= 0 && userAnswer < len(question.Answers) {
-			fmt.Println("\nIncorrect! " + question.Answers[userAnswer] + " is the wrong answer.")
+		checkResponse, err := checkAnswer(submission.SessionID, &qa, client)
+		if err != nil {
+			fmt.Println("\nUnable to check your answer: " + err.Error())
 		} else {
-			fmt.Println("\nIncorrect! Your selection was invalid.")
-			userAnswer = -1
+			fmt.Println("\n" + feedback(question, qa, checkResponse.Check))
 		}
 
-		// Store the question and answer
-		qa := models.QuestionAnswer{
-			Question: &question,
-			Answer:   userAnswer,
-		}
 		submission.QuestionResponses = append(submission.QuestionResponses, qa)
 	}
 
 	return &submission, nil
 }
 
+// checkAnswer sends a single answer to the API and returns whether it was correct
+func checkAnswer(sessionID string, questionAnswer *models.QuestionAnswer, client *http.Client) (*models.AnswerCheckResponse, error) {
+	if questionAnswer == nil {
+		return nil, errors.New("question answer is nil")
+	}
+
+	jsonData, err := json.Marshal(questionAnswer)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling check answer: %v", err)
+	}
+
+	url := config.ApiUrl + "/sessions/" + sessionID + "/answers"
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error creating check answer request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	err = authorise(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending check answer request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading check answer response: %v", err)
+	}
+
+	var checkResponse models.AnswerCheckResponse
+	err = json.Unmarshal([]byte(body), &checkResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling check answer response: %v", err)
+	}
+
+	if !checkResponse.Success {
+		return nil, &apiError{response: "check answer", code: checkResponse.Code, message: checkResponse.Message, details: checkResponse.Details}
+	}
+
+	return &checkResponse, nil
+}
+
 // submitQuiz sends the selected answers for each question to the API
 func submitQuiz(quizSubmission *models.QuizSubmission, client *http.Client) (*models.QuizSubmissionResponse, error) {
 	if quizSubmission == nil {
@@ -202,6 +338,11 @@ func submitQuiz(quizSubmission *models.QuizSubmission, client *http.Client) (*mo
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	err = authorise(req)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error sending post submission request: %v", err)
@@ -220,12 +361,48 @@ func submitQuiz(quizSubmission *models.QuizSubmission, client *http.Client) (*mo
 	}
 
 	if !submissionResponse.Success {
-		return nil, fmt.Errorf("error within post submission response: %s", submissionResponse.Message)
+		return nil, &apiError{response: "post submission", code: submissionResponse.Code, message: submissionResponse.Message, details: submissionResponse.Details}
 	}
 
 	return &submissionResponse, nil
 }
 
+// authorise adds the stored access token to a request, unless the user has not logged in or their login has expired
+func authorise(req *http.Request) error {
+	credentials, err := config.LoadCredentials()
+	if err != nil {
+		return fmt.Errorf("error loading login: %v", err)
+	}
+
+	if credentials.Token != "" && !credentials.Expired(time.Now()) {
+		req.Header.Set("Authorization", "Bearer "+credentials.Token)
+	}
+	return nil
+}
+
+// apiError is returned when the API responds unsuccessfully. The code identifies why the request failed, so callers
+// should branch on it rather than on the message.
+type apiError struct {
+	response string
+	code     string
+	message  string
+	details  []models.FieldError
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("error within %s response: %s", e.response, e.message)
+}
+
+// hasField reports whether the API found a problem with the named parameter or field
+func (e *apiError) hasField(field string) bool {
+	for _, detail := range e.details {
+		if detail.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
 // displayResults outputs the results of the quiz submission
 func displayResults(results *models.QuizSubmissionResponse) error {
 	if results == nil {
@@ -233,24 +410,341 @@ func displayResults(results *models.QuizSubmissionResponse) error {
 	}
 
 	if !results.Success {
-		return fmt.Errorf("error within quiz submission response: %s", results.Message)
+		return &apiError{response: "quiz submission", code: results.Code, message: results.Message, details: results.Details}
 	}
 
 	fmt.Println("\n+++ Quiz Results +++")
 	fmt.Println("\nRaw score: " + results.Results.ScoreString)
 	fmt.Println("Percentage score: " + fmt.Sprintf("%.0f%%", results.Results.ScorePercentage))
+	if len(results.Results.Breakdown) > 1 {
+		names := make([]string, 0, len(results.Results.Breakdown))
+		for name := range results.Results.Breakdown {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Println("\nCategory breakdown:")
+		for _, name := range names {
+			score := results.Results.Breakdown[name]
+			fmt.Printf("  %s: %s (%.0f%%)\n", name, score.ScoreString, score.ScorePercentage)
+		}
+	}
+
+	adjustments := pointsAdjustments(results.Results.Points)
+	if len(adjustments) > 0 {
+		fmt.Println("\nPoints adjustments:")
+		for _, adjustment := range adjustments {
+			fmt.Println("  " + adjustment)
+		}
+	}
+
 	fmt.Println("\n" + results.Results.Comparison)
+	// The first level is the quiz's own category, which the comparison already describes
+	if len(results.Results.Levels) > 1 {
+		for _, level := range results.Results.Levels[1:] {
+			fmt.Printf("Across all of %s, your score was better than %.0f%% of quizzers.\n", level.Category, level.Percentile)
+		}
+	}
+	if results.Results.Player != "" {
+		fmt.Println("Your score has been added to the leaderboard as " + results.Results.Player + ".")
+	}
+	if results.Results.Username != "" {
+		fmt.Println("Your result has been saved to your account, " + results.Results.Username + ".")
+	}
 
 	return nil
 }
 
+// pointsAdjustments describes the penalties and bonuses which changed the points earned, leaving out any which did not apply
+func pointsAdjustments(points []models.Points) []string {
+	penalty, speedBonus, streakBonus := 0.0, 0.0, 0.0
+	for _, p := range points {
+		penalty += p.Penalty
+		speedBonus += p.SpeedBonus
+		streakBonus += p.StreakBonus
+	}
+
+	adjustments := []string{}
+	if penalty > 0 {
+		adjustments = append(adjustments, "Wrong answer penalties: -"+formatPoints(penalty))
+	}
+	if speedBonus > 0 {
+		adjustments = append(adjustments, "Speed bonus: +"+formatPoints(speedBonus))
+	}
+	if streakBonus > 0 {
+		adjustments = append(adjustments, "Streak bonus: +"+formatPoints(streakBonus))
+	}
+	return adjustments
+}
+
+// formatPoints rounds a number of points to at most two decimal places
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64)
+}
+
+// quizName describes the selected categories along with the difficulty, if one was specified
+func quizName() string {
+	category := strings.Join(categories, ", ")
+	if difficulty == "" {
+		return category
+	}
+	return category + " (" + difficulty + ")"
+}
+
+// promptAnswer shows the answers to a question, if it has any, and asks the user for an answer of the appropriate kind
+func promptAnswer(question models.Question) models.QuestionAnswer {
+	qa := models.QuestionAnswer{QuestionID: question.ID}
+
+	if question.Type == models.TypeFreeText {
+		qa.Text = readInput("\nEnter your answer: ")
+		return qa
+	}
+
+	if question.Type == models.TypeNumeric {
+		prompt := "\nEnter a number: "
+		if question.Unit != "" {
+			prompt = "\nEnter a number in " + question.Unit + ": "
+		}
+
+		value, err := parseNumber(readInput(prompt))
+		if err == nil {
+			qa.Value = &value
+		}
+		return qa
+	}
+
+	fmt.Println()
+	for i, prompt := range question.Prompts {
+		fmt.Printf("%c. %s\n", 'A'+i, prompt)
+	}
+	if len(question.Prompts) > 0 {
+		fmt.Println()
+	}
+	for i, answer := range question.Answers {
+		fmt.Printf("%d. %s\n", i+1, answer)
+	}
+
+	switch question.Type {
+	case models.TypeOrdering:
+		answers, err := parseOrder(readInput("\nEnter every option number in order, such as 3 1 4 2: "), len(question.Answers))
+		if err != nil {
+			answers = []int{}
+		}
+		qa.Answers = answers
+		return qa
+	case models.TypeMatching:
+		answers, err := parseMatches(readInput("\nEnter a match for each letter, such as A2 B1: "), len(question.Prompts), len(question.Answers))
+		if err != nil {
+			answers = []int{}
+		}
+		qa.Answers = answers
+		return qa
+	case models.TypeMultiSelect:
+		answers, err := parseOptions(readInput("\nEnter option numbers separated by commas: "), len(question.Answers))
+		if err != nil {
+			answers = []int{}
+		}
+		qa.Answers = answers
+		return qa
+	}
+
+	userAnswer, err := promptUser()
+	if err != nil {
+		userAnswer = -1
+	}
+	userAnswer--
+
+	if userAnswer < 0 || userAnswer >= len(question.Answers) {
+		userAnswer = -1
+	}
+	qa.Answer = userAnswer
+
+	return qa
+}
+
+// feedback describes the outcome of checking an answer
+func feedback(question models.Question, qa models.QuestionAnswer, check models.AnswerCheck) string {
+	if check.TimedOut {
+		return "Out of time! The right answer is " + check.CorrectAnswer + "."
+	}
+
+	switch question.Type {
+	case models.TypeMultiSelect:
+		if check.Correct {
+			return "Correct! The right answers are " + check.CorrectAnswer + "."
+		} else if check.Credit > 0 {
+			return "Partially correct! The right answers are " + check.CorrectAnswer + "."
+		}
+		return "Incorrect! The right answers are " + check.CorrectAnswer + "."
+	case models.TypeOrdering, models.TypeMatching:
+		outcome := "Incorrect!"
+		if check.Correct {
+			outcome = "Correct!"
+		} else if check.Credit > 0 {
+			outcome = "Partially correct!"
+		}
+
+		if question.Type == models.TypeOrdering {
+			return outcome + " The right order is " + check.CorrectAnswer + "."
+		}
+		return outcome + " The right matches are " + check.CorrectAnswer + "."
+	case models.TypeFreeText:
+		if check.Correct {
+			return "Correct! " + check.CorrectAnswer + " is the right answer."
+		}
+		return "Incorrect! The right answer is " + check.CorrectAnswer + "."
+	case models.TypeNumeric:
+		if check.Correct {
+			return "Correct! " + check.CorrectAnswer + " is the right answer."
+		} else if check.Credit > 0 {
+			return "Close! The right answer is " + check.CorrectAnswer + "."
+		}
+		return "Incorrect! The right answer is " + check.CorrectAnswer + "."
+	default:
+		if check.Correct {
+			return "Correct! " + check.CorrectAnswer + " is the right answer."
+		} else if qa.Answer >= 0 && qa.Answer < len(question.Answers) {
+			return "Incorrect! " + question.Answers[qa.Answer] + " is the wrong answer."
+		}
+		return "Incorrect! Your selection was invalid."
+	}
+}
+
+// parseOptions converts comma separated option numbers into unique answer indexes
+func parseOptions(input string, optionCount int) ([]int, error) {
+	answers := []int{}
+	seen := make(map[int]bool)
+
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		option, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing user input: %v", err)
+		}
+		if option < 1 || option > optionCount {
+			return nil, fmt.Errorf("option %d is out of range", option)
+		}
+
+		if !seen[option] {
+			seen[option] = true
+			answers = append(answers, option-1)
+		}
+	}
+
+	if len(answers) == 0 {
+		msg := "no options were selected"
+		return nil, errors.New(msg)
+	}
+
+	return answers, nil
+}
+
+// parseOrder converts option numbers separated by spaces or commas into answer indexes, requiring every option exactly once
+func parseOrder(input string, optionCount int) ([]int, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) != optionCount {
+		return nil, fmt.Errorf("every option from 1 to %d must be entered", optionCount)
+	}
+
+	answers := make([]int, 0, optionCount)
+	seen := make(map[int]bool)
+	for _, field := range fields {
+		option, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing user input: %v", err)
+		}
+		if option < 1 || option > optionCount {
+			return nil, fmt.Errorf("option %d is out of range", option)
+		}
+		if seen[option] {
+			return nil, fmt.Errorf("option %d is entered more than once", option)
+		}
+
+		seen[option] = true
+		answers = append(answers, option-1)
+	}
+
+	return answers, nil
+}
+
+// parseMatches converts pairs such as "A2 B1" into the answer index matched to each prompt.
+// Prompts which are not matched are given an index of -1.
+func parseMatches(input string, promptCount int, optionCount int) ([]int, error) {
+	answers := make([]int, promptCount)
+	for i := range answers {
+		answers[i] = -1
+	}
+
+	fields := strings.FieldsFunc(strings.ToUpper(input), func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		msg := "no matches were entered"
+		return nil, errors.New(msg)
+	}
+
+	for _, field := range fields {
+		prompt := int(field[0] - 'A')
+		if prompt < 0 || prompt >= promptCount {
+			return nil, fmt.Errorf("%s does not start with a letter from A to %c", field, 'A'+promptCount-1)
+		}
+
+		option, err := strconv.Atoi(field[1:])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing user input: %v", err)
+		}
+		if option < 1 || option > optionCount {
+			return nil, fmt.Errorf("option %d is out of range", option)
+		}
+		if answers[prompt] != -1 {
+			return nil, fmt.Errorf("%c is matched more than once", 'A'+prompt)
+		}
+
+		answers[prompt] = option - 1
+	}
+
+	return answers, nil
+}
+
+// parseNumber converts a number entered by the user, which may group its digits with commas, spaces or underscores
+// such as "8,848.86" or "1 000 000", into a float
+func parseNumber(input string) (float64, error) {
+	input = strings.TrimSpace(input)
+	digits := strings.NewReplacer(" ", ",", "_", ",").Replace(input)
+
+	if strings.Contains(digits, ",") {
+		whole := digits
+		if i := strings.Index(digits, "."); i >= 0 {
+			whole = digits[:i]
+		}
+		whole = strings.TrimLeft(whole, "+-")
+
+		// Every group after the first must have three digits, so "1,5" is not mistaken for fifteen
+		groups := strings.Split(whole, ",")
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return 0, fmt.Errorf("error parsing user input: %s is not a valid number", input)
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return 0, fmt.Errorf("error parsing user input: %s is not a valid number", input)
+			}
+		}
+		digits = strings.ReplaceAll(digits, ",", "")
+	}
+
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("error parsing user input: %s is not a valid number", input)
+	}
+
+	return value, nil
+}
+
 // promptUser asks the user to select an answer by entering an option number
 func promptUser() (int, error) {
-	fmt.Print("\nEnter option number: ")
-
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	input := strings.TrimSpace(scanner.Text())
+	input := readInput("\nEnter option number: ")
 
 	intVal, err := strconv.Atoi(input)
 	if err != nil {
@@ -259,3 +753,65 @@ func promptUser() (int, error) {
 
 	return intVal, nil
 }
+
+// readInput prints a prompt and returns the line entered by the user.
+// If the current question is timed, a countdown is shown and nothing is returned once time runs out.
+func readInput(prompt string) string {
+	if !answerDeadline.IsZero() {
+		return readTimedInput(prompt, answerDeadline)
+	}
+
+	fmt.Print(prompt)
+	return <-userInput()
+}
+
+// readTimedInput prints a prompt below a countdown which is updated every second until the user enters a line or the deadline passes
+func readTimedInput(prompt string, deadline time.Time) string {
+	text := strings.TrimLeft(prompt, "\n")
+	fmt.Print(prompt[:len(prompt)-len(text)])
+	fmt.Println(countdown(time.Until(deadline)))
+	fmt.Print(text)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	for {
+		select {
+		case line := <-userInput():
+			return line
+		case <-ticker.C:
+			// Save the cursor, rewrite the countdown on the line above and restore the cursor to where the user is typing
+			fmt.Print("\0337\033[1A\r\033[K" + countdown(time.Until(deadline)) + "\0338")
+		case <-timer.C:
+			fmt.Println()
+			return ""
+		}
+	}
+}
+
+// countdown describes the whole seconds remaining to answer a question
+func countdown(remaining time.Duration) string {
+	seconds := int(math.Ceil(remaining.Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+	return fmt.Sprintf("Time remaining: %ds", seconds)
+}
+
+// userInput starts reading the user's input on first use and returns the lines entered.
+// The channel is closed once the input ends, after which every read returns an empty line.
+func userInput() <-chan string {
+	startInput.Do(func() {
+		inputLines = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				inputLines <- strings.TrimSpace(scanner.Text())
+			}
+			close(inputLines)
+		}()
+	})
+	return inputLines
+}
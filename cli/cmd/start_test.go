@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"quizwizard/cli/config"
 	"quizwizard/cli/models"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,10 +25,10 @@ func TestFetchQuestions(t *testing.T) {
 			w.Write([]byte("asdasda"))
 		case "api_error":
 			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(`{"success": false, "message": "API error"}`))
+			w.Write([]byte(`{"success": false, "code": "NO_QUESTIONS", "message": "API error"}`))
 		default:
 			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(`{"success": true, "message": "Questions retrieved successfully", "data": []}`))
+			w.Write([]byte(`{"success": true, "message": "Questions retrieved successfully", "data": {"sessionId": "abc123", "category": "random", "questions": []}}`))
 		}
 	}))
 	defer mockServer.Close()
@@ -53,7 +55,7 @@ func TestFetchQuestions(t *testing.T) {
 			name:           "successful_response",
 			errorScenario:  "",
 			expectedError:  "",
-			expectedResult: &models.QuestionsResponse{Success: true, Message: "Questions retrieved successfully", Questions: []models.Question{}},
+			expectedResult: &models.QuestionsResponse{Success: true, Message: "Questions retrieved successfully", Quiz: models.Quiz{SessionID: "abc123", Category: "random", Questions: []models.Question{}}},
 		},
 		{
 			name:           "failure_due_to_read_error",
@@ -86,6 +88,9 @@ func TestFetchQuestions(t *testing.T) {
 			if tc.expectedError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tc.expectedError)
+				if apiErr, ok := err.(*apiError); ok {
+					assert.Equal(t, models.CodeNoQuestions, apiErr.code)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.expectedResult, categoryResponse)
@@ -94,6 +99,122 @@ func TestFetchQuestions(t *testing.T) {
 	}
 }
 
+// TestFetchQuestionsWithOptions checks that the selected difficulty and count are sent to the API
+func TestFetchQuestionsWithOptions(t *testing.T) {
+	var query url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "message": "Questions retrieved successfully", "data": {"sessionId": "abc123", "category": "science", "difficulty": "hard", "questions": []}}`))
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	originalCategories, originalDifficulty, originalCount := categories, difficulty, count
+	categories, difficulty, count = []string{"Science"}, " HARD ", 8
+	defer func() { categories, difficulty, count = originalCategories, originalDifficulty, originalCount }()
+
+	questionsResponse, err := fetchQuestions(&http.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, "science", query.Get("category"))
+	assert.Equal(t, "hard", query.Get("difficulty"))
+	assert.Equal(t, "8", query.Get("count"))
+	assert.Equal(t, "hard", questionsResponse.Quiz.Difficulty)
+	assert.Equal(t, "science (hard)", quizName())
+
+	// A difficulty containing query characters is escaped rather than adding parameters of its own
+	difficulty, count = "hard&count=99", 0
+	_, err = fetchQuestions(&http.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, "hard&count=99", query.Get("difficulty"))
+	assert.Empty(t, query.Get("count"))
+}
+
+// TestFetchQuestionsWithMultipleCategories checks that every selected category is sent to the API
+func TestFetchQuestionsWithMultipleCategories(t *testing.T) {
+	var query url.Values
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true, "message": "Questions retrieved successfully", "data": {"sessionId": "abc123", "category": "computing+music", "categories": ["computing", "music"], "questions": []}}`))
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	originalCategories, originalDifficulty, originalCount := categories, difficulty, count
+	categories, difficulty, count = []string{" Music", "computing"}, "", 0
+	defer func() { categories, difficulty, count = originalCategories, originalDifficulty, originalCount }()
+
+	questionsResponse, err := fetchQuestions(&http.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, "music,computing", query.Get("category"))
+	assert.Equal(t, []string{"computing", "music"}, questionsResponse.Quiz.Categories)
+	assert.Equal(t, "music, computing", quizName())
+}
+
+// TestFetchQuestionsFailure tests that failures to fetch questions are explained using the error code from the API
+func TestFetchQuestionsFailure(t *testing.T) {
+	originalCategories, originalDifficulty := categories, difficulty
+	categories, difficulty = []string{"science"}, "extreme"
+	defer func() { categories, difficulty = originalCategories, originalDifficulty }()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "category_not_found",
+			err:      &apiError{response: "fetch questions", code: models.CodeCategoryNotFound, message: "science is not a valid category."},
+			expected: "\nFailure: science is not a valid category.\n\nUse the 'categories' command for a list of available categories.",
+		},
+		{
+			name: "invalid_difficulty",
+			err: &apiError{response: "fetch questions", code: models.CodeValidationFailed, message: "extreme is not a valid difficulty.",
+				details: []models.FieldError{{Field: "difficulty", Message: "extreme is not a valid difficulty."}}},
+			expected: "\nFailure: extreme is not a valid difficulty.\n\nPlease choose easy, medium or hard.",
+		},
+		{
+			name: "invalid_count",
+			err: &apiError{response: "fetch questions", code: models.CodeValidationFailed, message: "0 is not a valid question count.",
+				details: []models.FieldError{{Field: "count", Message: "0 is not a valid question count."}}},
+			expected: "\nFailure: 0 is not a valid question count.",
+		},
+		{
+			name:     "invalid_combination",
+			err:      &apiError{response: "fetch questions", code: models.CodeInvalidCombination, message: "random cannot be combined with other categories."},
+			expected: "\nFailure: random cannot be combined with other categories.\n\nPlease choose either random or a list of categories.",
+		},
+		{
+			name:     "no_questions",
+			err:      &apiError{response: "fetch questions", code: models.CodeNoQuestions, message: "There are no questions available."},
+			expected: "\nCurrently there are no questions available for the science (extreme) category.\n\nPlease choose a different category or try again later.",
+		},
+		{
+			name:     "unknown_code",
+			err:      &apiError{response: "fetch questions", code: "INTERNAL_ERROR", message: "An unexpected error occurred."},
+			expected: "\nFailed to fetch questions: error within fetch questions response: An unexpected error occurred.",
+		},
+		{
+			name:     "request_error",
+			err:      errors.New("error making fetch questions request: connection refused"),
+			expected: "\nFailed to fetch questions: error making fetch questions request: connection refused",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, fetchQuestionsFailure(tc.err))
+		})
+	}
+}
+
 // TestRunQuiz tests the runQuiz function
 func TestRunQuiz(t *testing.T) {
 	tests := []struct {
@@ -117,17 +238,17 @@ func TestRunQuiz(t *testing.T) {
 		{
 			name: "failure_due_to_empty_questions_response",
 			input: &models.QuestionsResponse{
-				Success:   true,
-				Message:   "Success!",
-				Questions: []models.Question{},
+				Success: true,
+				Message: "Success!",
+				Quiz:    models.Quiz{SessionID: "abc123", Questions: []models.Question{}},
 			},
-			expectedError: "\nCurrently there are no questions available for the random category.\n\nPlease choose a different category or try again later.",
+			expectedError: "no questions available",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			res, err := runQuiz(tc.input)
+			res, err := runQuiz(tc.input, &http.Client{})
 			if tc.expectedError != "" {
 				assert.Nil(t, res)
 				assert.Error(t, err)
@@ -137,6 +258,121 @@ func TestRunQuiz(t *testing.T) {
 			}
 		})
 	}
+	_, err := runQuiz(&models.QuestionsResponse{Success: true}, &http.Client{})
+	assert.ErrorIs(t, err, errNoQuestions, "Expected an empty quiz to be reported with errNoQuestions")
+}
+
+// TestCheckAnswer tests the checkAnswer function
+func TestCheckAnswer(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-Error-Scenario") {
+		case "read_error":
+			w.Header().Set("Content-Length", "1")
+			w.WriteHeader(http.StatusOK)
+		case "unmarshal_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("asdasda"))
+		case "api_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": false, "message": "API error"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"success": true,
+				"message": "Answer checked successfully.",
+				"data": {
+					"questionId": 1,
+					"correct": false,
+					"correctAnswerIndex": 0,
+					"correctAnswer": "Freddie Mercury"
+				}
+			}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(mockServer.URL)
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		errorScenario  string
+		input          *models.QuestionAnswer
+		expectedError  string
+		expectedResult *models.AnswerCheckResponse
+	}{
+		{
+			name:          "successful_response",
+			errorScenario: "",
+			input:         &models.QuestionAnswer{QuestionID: 1, Answer: 2},
+			expectedError: "",
+			expectedResult: &models.AnswerCheckResponse{
+				Success: true,
+				Message: "Answer checked successfully.",
+				Check: models.AnswerCheck{
+					QuestionID:         1,
+					Correct:            false,
+					CorrectAnswerIndex: 0,
+					CorrectAnswer:      "Freddie Mercury",
+				},
+			},
+		},
+		{
+			name:           "failure_due_to_read_error",
+			errorScenario:  "read_error",
+			input:          &models.QuestionAnswer{QuestionID: 1, Answer: 2},
+			expectedError:  "error reading check answer response",
+			expectedResult: nil,
+		},
+		{
+			name:           "failure_due_to_unmarshal_error",
+			errorScenario:  "unmarshal_error",
+			input:          &models.QuestionAnswer{QuestionID: 1, Answer: 2},
+			expectedError:  "error unmarshaling check answer response",
+			expectedResult: nil,
+		},
+		{
+			name:           "failure_due_to_api_error",
+			errorScenario:  "api_error",
+			input:          &models.QuestionAnswer{QuestionID: 1, Answer: 2},
+			expectedError:  "error within check answer response: API error",
+			expectedResult: nil,
+		},
+		{
+			name:           "nil_answer",
+			errorScenario:  "",
+			input:          nil,
+			expectedError:  "question answer is nil",
+			expectedResult: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client.Transport.(*http.Transport).Proxy = func(req *http.Request) (*url.URL, error) {
+				req.Header.Set("X-Error-Scenario", tc.errorScenario)
+				return url.Parse(mockServer.URL)
+			}
+
+			checkResponse, err := checkAnswer("abc123", tc.input, client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, checkResponse)
+			}
+		})
+	}
 }
 
 // TestSubmitQuiz tests the submitQuiz function
@@ -190,9 +426,9 @@ func TestSubmitQuiz(t *testing.T) {
 			name:          "successful_response",
 			errorScenario: "",
 			input: &models.QuizSubmission{
-				Category: "Science",
+				SessionID: "abc123",
 				QuestionResponses: []models.QuestionAnswer{
-					{Question: &models.Question{ID: 1, CorrectAnswerIndex: 0}, Answer: 0},
+					{QuestionID: 1, Answer: 0},
 				},
 			},
 			expectedError: "",
@@ -210,9 +446,9 @@ func TestSubmitQuiz(t *testing.T) {
 			name:          "failure_due_to_read_error",
 			errorScenario: "read_error",
 			input: &models.QuizSubmission{
-				Category: "Science",
+				SessionID: "abc123",
 				QuestionResponses: []models.QuestionAnswer{
-					{Question: &models.Question{ID: 1, CorrectAnswerIndex: 0}, Answer: 0},
+					{QuestionID: 1, Answer: 0},
 				},
 			},
 			expectedError:  "error reading post submission response",
@@ -222,9 +458,9 @@ func TestSubmitQuiz(t *testing.T) {
 			name:          "failure_due_to_unmarshal_error",
 			errorScenario: "unmarshal_error",
 			input: &models.QuizSubmission{
-				Category: "Science",
+				SessionID: "abc123",
 				QuestionResponses: []models.QuestionAnswer{
-					{Question: &models.Question{ID: 1, CorrectAnswerIndex: 0}, Answer: 0},
+					{QuestionID: 1, Answer: 0},
 				},
 			},
 			expectedError:  "error unmarshaling post submission response",
@@ -234,9 +470,9 @@ func TestSubmitQuiz(t *testing.T) {
 			name:          "failure_due_to_api_error",
 			errorScenario: "api_error",
 			input: &models.QuizSubmission{
-				Category: "Science",
+				SessionID: "abc123",
 				QuestionResponses: []models.QuestionAnswer{
-					{Question: &models.Question{ID: 1, CorrectAnswerIndex: 0}, Answer: 0},
+					{QuestionID: 1, Answer: 0},
 				},
 			},
 			expectedError:  "error within post submission response: API error",
@@ -290,6 +526,50 @@ func TestDisplayResults(t *testing.T) {
 			},
 			expectedError: "error within quiz submission response: API error",
 		},
+		{
+			name: "success_with_category_breakdown",
+			input: &models.QuizSubmissionResponse{
+				Success: true,
+				Results: models.Results{
+					ScoreString:     "1/2",
+					ScorePercentage: 50,
+					Breakdown: map[string]models.CategoryScore{
+						"computing": {Score: 0, Total: 1, ScoreString: "0/1"},
+						"music":     {Score: 1, Total: 1, ScoreString: "1/1", ScorePercentage: 100},
+					},
+				},
+			},
+		},
+		{
+			name: "success_with_category_levels",
+			input: &models.QuizSubmissionResponse{
+				Success: true,
+				Results: models.Results{
+					ScoreString:     "1/2",
+					ScorePercentage: 50,
+					Comparison:      "Your score for the science/physics category was better than 50% of all quizzers.",
+					Levels: []models.LevelComparison{
+						{Category: "science/physics", Percentile: 50},
+						{Category: "science", Percentile: 25},
+					},
+				},
+			},
+		},
+		{
+			name: "success_with_points_adjustments",
+			input: &models.QuizSubmissionResponse{
+				Success: true,
+				Results: models.Results{
+					ScoreString:     "1.75/2",
+					ScorePercentage: 87.5,
+					Points: []models.Points{
+						{QuestionID: 1, Category: "music", Available: 1, Earned: 1, StreakBonus: 0, Total: 1},
+						{QuestionID: 2, Category: "music", Available: 1, Earned: 1, StreakBonus: 0.1, Total: 1.1},
+						{QuestionID: 3, Category: "music", Available: 1, Earned: 0, Penalty: 0.35, Total: -0.35},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -304,3 +584,210 @@ func TestDisplayResults(t *testing.T) {
 		})
 	}
 }
+
+// TestParseOptions tests the parseOptions function
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedAnswers []int
+		expectedError   string
+	}{
+		{"success_single_option", "2", []int{1}, ""},
+		{"success_comma_separated_options", "1, 3,4", []int{0, 2, 3}, ""},
+		{"success_duplicates_ignored", "3,3,1", []int{2, 0}, ""},
+		{"failure_due_to_invalid_number", "1,two", nil, "error parsing user input: strconv.Atoi: parsing \"two\": invalid syntax"},
+		{"failure_due_to_out_of_range_option", "1,5", nil, "option 5 is out of range"},
+		{"failure_due_to_no_options", " , ", nil, "no options were selected"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			answers, err := parseOptions(tc.input, 4)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedAnswers, answers)
+			}
+		})
+	}
+}
+
+// TestFeedback tests the feedback function for each type of question
+func TestFeedback(t *testing.T) {
+	singleChoice := models.Question{ID: 1, Answers: []string{"Freddie Mercury", "John Lennon"}}
+	multiSelect := models.Question{ID: 2, Type: models.TypeMultiSelect, Answers: []string{"Helium", "Oxygen", "Neon"}}
+	freeText := models.Question{ID: 3, Type: models.TypeFreeText}
+	numeric := models.Question{ID: 4, Type: models.TypeNumeric, Unit: "metres"}
+	ordering := models.Question{ID: 5, Type: models.TypeOrdering, Answers: []string{"Revolver", "Help!"}}
+	matching := models.Question{ID: 6, Type: models.TypeMatching, Prompts: []string{"Camel", "Polar bear"}, Answers: []string{"Arctic", "Desert"}}
+
+	tests := []struct {
+		name     string
+		question models.Question
+		answer   models.QuestionAnswer
+		check    models.AnswerCheck
+		expected string
+	}{
+		{"success_single_choice_correct", singleChoice, models.QuestionAnswer{Answer: 0}, models.AnswerCheck{Correct: true, Credit: 1, CorrectAnswer: "Freddie Mercury"}, "Correct! Freddie Mercury is the right answer."},
+		{"success_single_choice_incorrect", singleChoice, models.QuestionAnswer{Answer: 1}, models.AnswerCheck{CorrectAnswer: "Freddie Mercury"}, "Incorrect! John Lennon is the wrong answer."},
+		{"success_single_choice_invalid", singleChoice, models.QuestionAnswer{Answer: -1}, models.AnswerCheck{CorrectAnswer: "Freddie Mercury"}, "Incorrect! Your selection was invalid."},
+		{"success_multi_select_correct", multiSelect, models.QuestionAnswer{Answers: []int{0, 2}}, models.AnswerCheck{Correct: true, Credit: 1, CorrectAnswer: "Helium, Neon"}, "Correct! The right answers are Helium, Neon."},
+		{"success_multi_select_partially_correct", multiSelect, models.QuestionAnswer{Answers: []int{0}}, models.AnswerCheck{Credit: 0.5, CorrectAnswer: "Helium, Neon"}, "Partially correct! The right answers are Helium, Neon."},
+		{"success_multi_select_incorrect", multiSelect, models.QuestionAnswer{Answers: []int{1}}, models.AnswerCheck{CorrectAnswer: "Helium, Neon"}, "Incorrect! The right answers are Helium, Neon."},
+		{"success_free_text_correct", freeText, models.QuestionAnswer{Text: "au"}, models.AnswerCheck{Correct: true, Credit: 1, CorrectAnswer: "Au"}, "Correct! Au is the right answer."},
+		{"success_free_text_incorrect", freeText, models.QuestionAnswer{Text: "Ag"}, models.AnswerCheck{CorrectAnswer: "Au"}, "Incorrect! The right answer is Au."},
+		{"success_ordering_correct", ordering, models.QuestionAnswer{Answers: []int{1, 0}}, models.AnswerCheck{Correct: true, Credit: 1, CorrectAnswer: "Help!, Revolver"}, "Correct! The right order is Help!, Revolver."},
+		{"success_ordering_incorrect", ordering, models.QuestionAnswer{Answers: []int{0, 1}}, models.AnswerCheck{CorrectAnswer: "Help!, Revolver"}, "Incorrect! The right order is Help!, Revolver."},
+		{"success_matching_partially_correct", matching, models.QuestionAnswer{Answers: []int{1, -1}}, models.AnswerCheck{Credit: 0.5, CorrectAnswer: "Camel: Desert, Polar bear: Arctic"}, "Partially correct! The right matches are Camel: Desert, Polar bear: Arctic."},
+		{"success_numeric_correct", numeric, models.QuestionAnswer{}, models.AnswerCheck{Correct: true, Credit: 1, CorrectAnswer: "8849 metres"}, "Correct! 8849 metres is the right answer."},
+		{"success_numeric_close", numeric, models.QuestionAnswer{}, models.AnswerCheck{Credit: 0.4, CorrectAnswer: "8849 metres"}, "Close! The right answer is 8849 metres."},
+		{"success_numeric_incorrect", numeric, models.QuestionAnswer{}, models.AnswerCheck{CorrectAnswer: "8849 metres"}, "Incorrect! The right answer is 8849 metres."},
+		{"success_timed_out", singleChoice, models.QuestionAnswer{Answer: -1}, models.AnswerCheck{TimedOut: true, CorrectAnswer: "Freddie Mercury"}, "Out of time! The right answer is Freddie Mercury."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, feedback(tc.question, tc.answer, tc.check))
+		})
+	}
+}
+
+// TestCountdown tests the countdown function
+func TestCountdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining time.Duration
+		expected  string
+	}{
+		{"success_whole_seconds", 30 * time.Second, "Time remaining: 30s"},
+		{"success_rounds_up_part_seconds", 4200 * time.Millisecond, "Time remaining: 5s"},
+		{"success_expired", -time.Second, "Time remaining: 0s"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, countdown(tc.remaining))
+		})
+	}
+}
+
+// TestPointsAdjustments tests the pointsAdjustments function
+func TestPointsAdjustments(t *testing.T) {
+	tests := []struct {
+		name     string
+		points   []models.Points
+		expected []string
+	}{
+		{"success_no_adjustments", []models.Points{{QuestionID: 1, Available: 1, Earned: 1, Total: 1}}, []string{}},
+		{
+			"success_every_adjustment",
+			[]models.Points{
+				{QuestionID: 1, Available: 1, Earned: 1, SpeedBonus: 0.333, Total: 1.333},
+				{QuestionID: 2, Available: 1, Earned: 0, Penalty: 0.25, Total: -0.25},
+				{QuestionID: 3, Available: 2, Earned: 2, SpeedBonus: 0.5, StreakBonus: 0.2, Total: 2.7},
+			},
+			[]string{"Wrong answer penalties: -0.25", "Speed bonus: +0.83", "Streak bonus: +0.2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, pointsAdjustments(tc.points))
+		})
+	}
+}
+
+// TestParseNumber tests the parseNumber function
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedValue float64
+		expectedError string
+	}{
+		{"success_whole_number", "8", 8, ""},
+		{"success_decimal", " 3.14 ", 3.14, ""},
+		{"success_negative", "-40", -40, ""},
+		{"success_comma_separators", "8,848.86", 8848.86, ""},
+		{"success_space_separators", "1 000 000", 1000000, ""},
+		{"success_underscore_separators", "-1_000", -1000, ""},
+		{"failure_due_to_misplaced_separator", "1,5", 0, "error parsing user input: 1,5 is not a valid number"},
+		{"failure_due_to_leading_separator", ",100", 0, "error parsing user input: ,100 is not a valid number"},
+		{"failure_due_to_text", "eight", 0, "error parsing user input: eight is not a valid number"},
+		{"failure_due_to_empty_input", "", 0, "error parsing user input:  is not a valid number"},
+		{"failure_due_to_infinity", "Inf", 0, "error parsing user input: Inf is not a valid number"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := parseNumber(tc.input)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+// TestParseOrder tests the parseOrder function
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedAnswers []int
+		expectedError   string
+	}{
+		{"success_space_separated", "3 1 4 2", []int{2, 0, 3, 1}, ""},
+		{"success_comma_separated", "1,2, 4,3", []int{0, 1, 3, 2}, ""},
+		{"failure_due_to_missing_option", "3 1 4", nil, "every option from 1 to 4 must be entered"},
+		{"failure_due_to_repeated_option", "3 1 3 2", nil, "option 3 is entered more than once"},
+		{"failure_due_to_out_of_range_option", "3 1 5 2", nil, "option 5 is out of range"},
+		{"failure_due_to_invalid_number", "3 1 x 2", nil, "error parsing user input: strconv.Atoi: parsing \"x\": invalid syntax"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			answers, err := parseOrder(tc.input, 4)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedAnswers, answers)
+			}
+		})
+	}
+}
+
+// TestParseMatches tests the parseMatches function
+func TestParseMatches(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedAnswers []int
+		expectedError   string
+	}{
+		{"success_every_prompt_matched", "A2 B1 C4", []int{1, 0, 3}, ""},
+		{"success_lowercase_and_commas", "c3, a1", []int{0, -1, 2}, ""},
+		{"failure_due_to_no_matches", "  ", nil, "no matches were entered"},
+		{"failure_due_to_unknown_letter", "A1 D2", nil, "D2 does not start with a letter from A to C"},
+		{"failure_due_to_out_of_range_option", "A5", nil, "option 5 is out of range"},
+		{"failure_due_to_missing_option", "A", nil, "error parsing user input: strconv.Atoi: parsing \"\": invalid syntax"},
+		{"failure_due_to_repeated_letter", "A1 A2", nil, "A is matched more than once"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			answers, err := parseMatches(tc.input, 3, 4)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedAnswers, answers)
+			}
+		})
+	}
+}
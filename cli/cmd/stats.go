@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// sparkBlocks are the characters used to draw a sparkline, from the lowest score to the highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// maxBarWidth is the number of characters in the bar of the fullest histogram bucket
+const maxBarWidth = 40
+
+var statsCategory string
+var statsDifficulty string
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics about the quizzes you have taken",
+	Long: `
++++ QuizWizard Stats +++
+
+Show your average and best scores, your accuracy
+in each category, your best and worst categories
+and how your scores have changed over time.
+You must be logged in to see your statistics.
+
+Specify a category to see how everyone has scored
+in it instead, including a histogram of scores.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if strings.TrimSpace(statsCategory) != "" {
+			runCategoryStatsCommand()
+			return
+		}
+		runStatsCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVarP(&statsCategory, "category", "c", "", "Specify the category, or comma separated categories, to show everyone's scores for")
+	statsCmd.Flags().StringVarP(&statsDifficulty, "difficulty", "d", "", "Specify the difficulty to show everyone's scores for (easy, medium or hard)")
+}
+
+// runStatsCommand will handle all of the steps required to fetch and display the logged in user's statistics
+func runStatsCommand() {
+	fmt.Println("\n+++ QuizWizard Stats +++")
+
+	client := &http.Client{}
+	statsResponse, err := fetchStats(client)
+	if err != nil {
+		fmt.Println("\nFailed to fetch stats: " + strings.TrimPrefix(err.Error(), "error within stats response: "))
+		return
+	}
+
+	err = displayStats(statsResponse)
+	if err != nil {
+		fmt.Println("\nFailed to display stats: " + err.Error())
+		return
+	}
+}
+
+// runCategoryStatsCommand will handle all of the steps required to fetch and display the statistics for a category
+func runCategoryStatsCommand() {
+	fmt.Println("\n+++ QuizWizard Stats +++")
+
+	client := &http.Client{}
+	categoryStatsResponse, err := fetchCategoryStats(client)
+	if err != nil {
+		fmt.Println("\nFailed to fetch category stats: " + strings.TrimPrefix(err.Error(), "error within category stats response: "))
+		return
+	}
+
+	err = displayCategoryStats(categoryStatsResponse)
+	if err != nil {
+		fmt.Println("\nFailed to display category stats: " + err.Error())
+		return
+	}
+}
+
+// fetchStats retrieves the logged in user's statistics from the API
+func fetchStats(client *http.Client) (*models.StatsResponse, error) {
+	req, err := http.NewRequest("GET", config.ApiUrl+"/me/stats", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating stats request: %v", err)
+	}
+
+	err = authorise(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making stats request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading stats response: %v", err)
+	}
+
+	var statsResponse models.StatsResponse
+	err = json.Unmarshal([]byte(body), &statsResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling stats response: %v", err)
+	}
+
+	if !statsResponse.Success {
+		return nil, &apiError{response: "stats", code: statsResponse.Code, message: statsResponse.Message, details: statsResponse.Details}
+	}
+
+	return &statsResponse, nil
+}
+
+// displayStats outputs the logged in user's statistics
+func displayStats(statsResponse *models.StatsResponse) error {
+	if statsResponse == nil {
+		return errors.New("stats response is nil")
+	}
+
+	if !statsResponse.Success {
+		return &apiError{response: "stats", code: statsResponse.Code, message: statsResponse.Message, details: statsResponse.Details}
+	}
+
+	if statsResponse.Stats.Quizzes == 0 {
+		fmt.Println("\nYou have not saved any quizzes yet")
+		return nil
+	}
+
+	return renderStats(os.Stdout, statsResponse.Stats)
+}
+
+// renderStats writes the overall statistics followed by tables of accuracy by category and scores by day
+func renderStats(w io.Writer, stats models.Stats) error {
+	fmt.Fprintf(w, "\nQuizzes: %d\n", stats.Quizzes)
+	fmt.Fprintf(w, "Questions: %d\n", stats.Questions)
+	fmt.Fprintf(w, "Average score: %.0f%%\n", stats.AverageScore)
+	fmt.Fprintf(w, "Best score: %.0f%%\n", stats.BestScore)
+	fmt.Fprintf(w, "Accuracy: %.0f%%\n", stats.Accuracy)
+	if len(stats.Recent) > 0 {
+		fmt.Fprintf(w, "Recent scores: %s\n", sparkline(stats.Recent))
+	}
+
+	if len(stats.Categories) > 0 {
+		fmt.Fprintln(w)
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "CATEGORY\tQUIZZES\tQUESTIONS\tCORRECT\tACCURACY")
+		for _, category := range stats.Categories {
+			fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%.0f%%\n",
+				category.Category, category.Quizzes, category.Questions, category.Correct, category.Accuracy)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if stats.BestCategory != "" {
+		fmt.Fprintf(w, "\nBest category: %s\n", stats.BestCategory)
+		fmt.Fprintf(w, "Worst category: %s\n", stats.WorstCategory)
+	}
+
+	if len(stats.Trend) > 0 {
+		fmt.Fprintln(w)
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "DATE\tQUIZZES\tAVERAGE")
+		for _, day := range stats.Trend {
+			fmt.Fprintf(table, "%s\t%d\t%.0f%%\n", day.Date, day.Quizzes, day.AverageScore)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sparkline draws a block for each score, with heights on a fixed scale from 0% to 100% so that sparklines of
+// different users can be compared
+func sparkline(scores []float64) string {
+	var line strings.Builder
+	for _, score := range scores {
+		score = math.Max(0, math.Min(100, score))
+		level := int(math.Round(score / 100 * float64(len(sparkBlocks)-1)))
+		line.WriteRune(sparkBlocks[level])
+	}
+	return line.String()
+}
+
+// fetchCategoryStats retrieves the statistics for the selected category, or combination of categories, and difficulty
+func fetchCategoryStats(client *http.Client) (*models.CategoryStatsResponse, error) {
+	names := []string{}
+	for _, name := range strings.Split(statsCategory, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	url := config.ApiUrl + "/categories/" + neturl.PathEscape(strings.Join(names, "+")) + "/stats"
+	if difficulty := strings.ToLower(strings.TrimSpace(statsDifficulty)); difficulty != "" {
+		url += "?difficulty=" + neturl.QueryEscape(difficulty)
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error making category stats request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading category stats response: %v", err)
+	}
+
+	var categoryStatsResponse models.CategoryStatsResponse
+	err = json.Unmarshal([]byte(body), &categoryStatsResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling category stats response: %v", err)
+	}
+
+	if !categoryStatsResponse.Success {
+		return nil, &apiError{response: "category stats", code: categoryStatsResponse.Code, message: categoryStatsResponse.Message, details: categoryStatsResponse.Details}
+	}
+
+	return &categoryStatsResponse, nil
+}
+
+// displayCategoryStats outputs the statistics for a category
+func displayCategoryStats(categoryStatsResponse *models.CategoryStatsResponse) error {
+	if categoryStatsResponse == nil {
+		return errors.New("category stats response is nil")
+	}
+
+	if !categoryStatsResponse.Success {
+		return &apiError{response: "category stats", code: categoryStatsResponse.Code, message: categoryStatsResponse.Message, details: categoryStatsResponse.Details}
+	}
+
+	statistics := categoryStatsResponse.Statistics
+	name := statistics.Category
+	if statistics.Difficulty != "" {
+		name += " (" + statistics.Difficulty + ")"
+	}
+	fmt.Printf("\nScores for %s\n", name)
+
+	if statistics.Stats.Count == 0 {
+		fmt.Println("\nNo quizzes have been submitted yet")
+		return nil
+	}
+
+	return renderCategoryStats(os.Stdout, statistics)
+}
+
+// renderCategoryStats writes the summary statistics for a category followed by its histogram of scores
+func renderCategoryStats(w io.Writer, statistics models.CategoryStatistics) error {
+	stats := statistics.Stats
+	fmt.Fprintf(w, "\nSubmissions: %d\n", stats.Count)
+	fmt.Fprintf(w, "Mean: %.1f%%\n", stats.Mean)
+	fmt.Fprintf(w, "Median: %.1f%%\n", stats.Median)
+	fmt.Fprintf(w, "Standard deviation: %.1f\n", stats.StdDev)
+	fmt.Fprintf(w, "Pass rate: %.0f%% (scoring at least %.0f%%)\n", stats.PassRate, statistics.PassMark)
+	fmt.Fprintf(w, "Range: %.0f%% to %.0f%%\n\n", stats.Lowest, stats.Highest)
+
+	fmt.Fprint(w, histogramBars(stats.Histogram))
+	return nil
+}
+
+// histogramBars draws a bar of '#' characters for each tenth of the range from 0% to 100%, scaled so that the fullest
+// bucket is maxBarWidth characters wide. Buckets with any scores always have a bar.
+func histogramBars(histogram []int) string {
+	if len(histogram) == 0 {
+		return ""
+	}
+
+	fullest := 0
+	for _, count := range histogram {
+		if count > fullest {
+			fullest = count
+		}
+	}
+
+	var bars strings.Builder
+	width := 100 / len(histogram)
+	for i, count := range histogram {
+		upper := (i+1)*width - 1
+		if i == len(histogram)-1 {
+			upper = 100
+		}
+
+		bar := ""
+		if count > 0 {
+			length := int(math.Max(1, math.Round(float64(count)/float64(fullest)*maxBarWidth)))
+			bar = strings.Repeat("#", length) + " "
+		}
+		label := fmt.Sprintf("%d-%d%%", i*width, upper)
+		fmt.Fprintf(&bars, "%-7s | %s%d\n", label, bar, count)
+	}
+	return bars.String()
+}
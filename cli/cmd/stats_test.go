@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"quizwizard/cli/config"
+	"quizwizard/cli/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFetchStats tests the fetchStats function
+func TestFetchStats(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-Error-Scenario") {
+		case "unmarshal_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("asdasda"))
+		case "api_error":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success": false, "message": "An access token must be provided. Please log in first."}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": true, "message": "Statistics retrieved successfully.", "data": {"quizzes": 2, "questions": 3, "averageScore": 75, "bestScore": 100, "accuracy": 66.67, "categories": [{"category": "music", "quizzes": 1, "questions": 1, "correct": 1, "accuracy": 100}, {"category": "science", "quizzes": 1, "questions": 2, "correct": 1, "accuracy": 50}], "bestCategory": "music", "worstCategory": "science", "trend": [{"date": "2024-01-01", "quizzes": 2, "averageScore": 75}], "recent": [50, 100]}}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(mockServer.URL)
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		errorScenario string
+		expectedError string
+	}{
+		{"successful_response", "", ""},
+		{"failure_due_to_unmarshal_error", "unmarshal_error", "error unmarshaling stats response"},
+		{"failure_due_to_api_error", "api_error", "error within stats response: An access token must be provided. Please log in first."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client.Transport.(*http.Transport).Proxy = func(req *http.Request) (*url.URL, error) {
+				req.Header.Set("X-Error-Scenario", tc.errorScenario)
+				return url.Parse(mockServer.URL)
+			}
+
+			statsResponse, err := fetchStats(client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 2, statsResponse.Stats.Quizzes)
+			assert.Equal(t, "music", statsResponse.Stats.BestCategory)
+			assert.Equal(t, []float64{50, 100}, statsResponse.Stats.Recent)
+		})
+	}
+}
+
+// TestDisplayStats tests the displayStats function
+func TestDisplayStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         *models.StatsResponse
+		expectedError string
+	}{
+		{"failure_due_to_nil_stats_response", nil, "stats response is nil"},
+		{"failure_due_to_unsuccessful_response", &models.StatsResponse{Success: false, Message: "API error"}, "error within stats response: API error"},
+		{"success_no_quizzes", &models.StatsResponse{Success: true}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := displayStats(tc.input)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestRenderStats checks that the overall statistics are followed by tables of categories and days
+func TestRenderStats(t *testing.T) {
+	stats := models.Stats{
+		Quizzes:      2,
+		Questions:    3,
+		AverageScore: 75,
+		BestScore:    100,
+		Accuracy:     66.67,
+		Categories: []models.CategoryStats{
+			{Category: "music", Quizzes: 1, Questions: 1, Correct: 1, Accuracy: 100},
+			{Category: "science", Quizzes: 1, Questions: 2, Correct: 1, Accuracy: 50},
+		},
+		BestCategory:  "music",
+		WorstCategory: "science",
+		Trend:         []models.DailyStats{{Date: "2024-01-01", Quizzes: 2, AverageScore: 75}},
+		Recent:        []float64{50, 100},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderStats(&buf, stats))
+
+	expected := "\nQuizzes: 2\n" +
+		"Questions: 3\n" +
+		"Average score: 75%\n" +
+		"Best score: 100%\n" +
+		"Accuracy: 67%\n" +
+		"Recent scores: ▅█\n" +
+		"\n" +
+		"CATEGORY  QUIZZES  QUESTIONS  CORRECT  ACCURACY\n" +
+		"music     1        1          1        100%\n" +
+		"science   1        2          1        50%\n" +
+		"\n" +
+		"Best category: music\n" +
+		"Worst category: science\n" +
+		"\n" +
+		"DATE        QUIZZES  AVERAGE\n" +
+		"2024-01-01  2        75%\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestSparkline checks that scores are drawn on a fixed scale and out of range scores are clamped
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		scores   []float64
+		expected string
+	}{
+		{"success_empty", []float64{}, ""},
+		{"success_full_range", []float64{0, 14.3, 28.6, 42.9, 57.1, 71.4, 85.7, 100}, "▁▂▃▄▅▆▇█"},
+		{"success_fixed_scale", []float64{40, 50, 60}, "▄▅▅"},
+		{"success_out_of_range", []float64{-10, 110}, "▁█"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sparkline(tc.scores))
+		})
+	}
+}
+
+// TestFetchCategoryStats tests the fetchCategoryStats function
+func TestFetchCategoryStats(t *testing.T) {
+	var path, difficulty string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		difficulty = r.URL.Query().Get("difficulty")
+		switch r.Header.Get("X-Error-Scenario") {
+		case "unmarshal_error":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("asdasda"))
+		case "api_error":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "message": "history is not a valid category."}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success": true, "message": "Category statistics retrieved successfully.", "data": {"category": "music+science", "difficulty": "hard", "passMark": 50, "stats": {"count": 4, "mean": 55, "median": 50, "stdDev": 29.58, "passRate": 50, "lowest": 20, "highest": 100, "histogram": [0, 0, 1, 0, 1, 0, 1, 0, 0, 1]}}}`))
+		}
+	}))
+	defer mockServer.Close()
+
+	originalApiUrl := config.ApiUrl
+	config.ApiUrl = mockServer.URL
+	defer func() { config.ApiUrl = originalApiUrl }()
+
+	originalCategory, originalDifficulty := statsCategory, statsDifficulty
+	statsCategory, statsDifficulty = " Science, music ", "HARD"
+	defer func() { statsCategory, statsDifficulty = originalCategory, originalDifficulty }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return url.Parse(mockServer.URL)
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		errorScenario string
+		expectedError string
+	}{
+		{"successful_response", "", ""},
+		{"failure_due_to_unmarshal_error", "unmarshal_error", "error unmarshaling category stats response"},
+		{"failure_due_to_api_error", "api_error", "error within category stats response: history is not a valid category."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client.Transport.(*http.Transport).Proxy = func(req *http.Request) (*url.URL, error) {
+				req.Header.Set("X-Error-Scenario", tc.errorScenario)
+				return url.Parse(mockServer.URL)
+			}
+
+			categoryStatsResponse, err := fetchCategoryStats(client)
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "/categories/science+music/stats", path)
+			assert.Equal(t, "hard", difficulty)
+			assert.Equal(t, 4, categoryStatsResponse.Statistics.Stats.Count)
+			assert.Equal(t, []int{0, 0, 1, 0, 1, 0, 1, 0, 0, 1}, categoryStatsResponse.Statistics.Stats.Histogram)
+		})
+	}
+}
+
+// TestDisplayCategoryStats tests the displayCategoryStats function
+func TestDisplayCategoryStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         *models.CategoryStatsResponse
+		expectedError string
+	}{
+		{"failure_due_to_nil_category_stats_response", nil, "category stats response is nil"},
+		{"failure_due_to_unsuccessful_response", &models.CategoryStatsResponse{Success: false, Message: "API error"}, "error within category stats response: API error"},
+		{"success_no_submissions", &models.CategoryStatsResponse{Success: true, Statistics: models.CategoryStatistics{Category: "music"}}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := displayCategoryStats(tc.input)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestRenderCategoryStats checks that the summary statistics are followed by a bar for each tenth of the scores
+func TestRenderCategoryStats(t *testing.T) {
+	statistics := models.CategoryStatistics{
+		Category: "science",
+		PassMark: 50,
+		Stats: models.ScoreStats{
+			Count:     7,
+			Mean:      62.857,
+			Median:    60,
+			StdDev:    21.19,
+			PassRate:  71.43,
+			Lowest:    20,
+			Highest:   100,
+			Histogram: []int{0, 0, 1, 0, 1, 0, 2, 1, 0, 2},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, renderCategoryStats(&buf, statistics))
+
+	expected := "\nSubmissions: 7\n" +
+		"Mean: 62.9%\n" +
+		"Median: 60.0%\n" +
+		"Standard deviation: 21.2\n" +
+		"Pass rate: 71% (scoring at least 50%)\n" +
+		"Range: 20% to 100%\n" +
+		"\n" +
+		"0-9%    | 0\n" +
+		"10-19%  | 0\n" +
+		"20-29%  | #################### 1\n" +
+		"30-39%  | 0\n" +
+		"40-49%  | #################### 1\n" +
+		"50-59%  | 0\n" +
+		"60-69%  | ######################################## 2\n" +
+		"70-79%  | #################### 1\n" +
+		"80-89%  | 0\n" +
+		"90-100% | ######################################## 2\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestHistogramBars checks that bars are scaled to the fullest bucket and that every bucket with scores has a bar
+func TestHistogramBars(t *testing.T) {
+	histogram := []int{1000, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+	bars := strings.Split(histogramBars(histogram), "\n")
+
+	assert.Equal(t, "0-9%    | "+strings.Repeat("#", maxBarWidth)+" 1000", bars[0])
+	assert.Equal(t, "10-19%  | # 1", bars[1], "Expected a bucket with few scores to still have a bar")
+	assert.Equal(t, "20-29%  | 0", bars[2])
+}
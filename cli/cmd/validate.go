@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"quizwizard/api/lint"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate <file>...",
+	Short: "Check question files for problems",
+	Long: `
++++ QuizWizard Validate +++
+
+Check one or more question files for problems such as
+out of range answers, duplicate IDs and empty fields.
+Every problem is reported with its line and column.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		runValidateCommand(args, jsonOutput)
+	},
+}
+
+func init() {
+	validateCmd.Flags().Bool("json", false, "Print a machine readable JSON report")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// runValidateCommand will lint each question file and exit with a non-zero status if any problems are found
+func runValidateCommand(filenames []string, jsonOutput bool) {
+	valid, err := validateFiles(os.Stdout, filenames, jsonOutput)
+	if err != nil {
+		fmt.Println("\nFailed to validate questions: " + err.Error())
+		os.Exit(1)
+	}
+
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// validateFiles lints each question file, writes the results and reports whether every file is valid
+func validateFiles(out io.Writer, filenames []string, jsonOutput bool) (bool, error) {
+	reports := make([]lint.Report, 0, len(filenames))
+	valid := true
+	for _, filename := range filenames {
+		report, err := lint.LintFile(filename)
+		if err != nil {
+			return false, err
+		}
+
+		reports = append(reports, report)
+		valid = valid && report.Valid
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			return false, fmt.Errorf("error encoding report: %v", err)
+		}
+		return valid, nil
+	}
+
+	for _, report := range reports {
+		for _, problem := range report.Problems {
+			fmt.Fprintf(out, "%s:%s\n", report.File, problem)
+		}
+
+		if report.Valid {
+			fmt.Fprintf(out, "%s: OK (%d categories, %d questions)\n", report.File, report.Categories, report.Questions)
+		} else {
+			fmt.Fprintf(out, "%s: %d problem(s) found\n", report.File, len(report.Problems))
+		}
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"quizwizard/api/lint"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateFiles tests the validateFiles function
+func TestValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	validFile := filepath.Join(dir, "valid.json")
+	invalidFile := filepath.Join(dir, "invalid.json")
+
+	err := os.WriteFile(validFile, []byte(`{"science": [{"id": 1, "category": "science", "question": "What is H2O?", "answers": ["Water", "Salt"], "correctAnswerIndex": 0}]}`), 0o644)
+	assert.NoError(t, err)
+	err = os.WriteFile(invalidFile, []byte(`{"science": [{"id": 1, "category": "science", "question": "What is H2O?", "answers": ["Water", "Salt"], "correctAnswerIndex": 2}]}`), 0o644)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		filenames      []string
+		expectedValid  bool
+		expectedError  string
+		expectedOutput string
+	}{
+		{
+			name:           "success_valid_file",
+			filenames:      []string{validFile},
+			expectedValid:  true,
+			expectedOutput: validFile + ": OK (1 categories, 1 questions)\n",
+		},
+		{
+			name:          "failure_due_to_invalid_file",
+			filenames:     []string{validFile, invalidFile},
+			expectedValid: false,
+			expectedOutput: validFile + ": OK (1 categories, 1 questions)\n" +
+				invalidFile + ":1:105: question 1: correctAnswerIndex 2 is out of range\n" +
+				invalidFile + ": 1 problem(s) found\n",
+		},
+		{
+			name:          "failure_due_to_missing_file",
+			filenames:     []string{filepath.Join(dir, "missing.json")},
+			expectedError: "failed to read file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			valid, err := validateFiles(&out, test.filenames, false)
+
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedValid, valid)
+				assert.Equal(t, test.expectedOutput, out.String())
+			}
+		})
+	}
+}
+
+// TestValidateFilesJSON tests the JSON report mode of the validateFiles function
+func TestValidateFilesJSON(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "questions.json")
+	err := os.WriteFile(filename, []byte(`{"science": [{"id": 1, "category": "music", "question": "What is H2O?", "answers": ["Water", "Salt"], "correctAnswerIndex": 0}]}`), 0o644)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	valid, err := validateFiles(&out, []string{filename}, true)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	var reports []lint.Report
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &reports))
+	assert.Equal(t, []lint.Report{
+		{
+			File:       filename,
+			Categories: 1,
+			Questions:  1,
+			Problems: []lint.Problem{
+				{Line: 1, Column: 24, Category: "science", QuestionID: 1, Message: "category 'music' does not match the 'science' category it is listed under"},
+			},
+		},
+	}, reports)
+}
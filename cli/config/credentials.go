@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CredentialsFile is the path of the user config file which stores the access token of the logged in user.
+// Nothing is stored or loaded when it is empty.
+var CredentialsFile string
+
+// Credentials represents the access token of the logged in user
+type Credentials struct {
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Expired reports whether the access token has expired
+func (c Credentials) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// DefaultCredentialsFile returns the credentials file within the user's config directory, such as ~/.config/quizwizard/credentials.json
+func DefaultCredentialsFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding user config directory: %v", err)
+	}
+	return filepath.Join(dir, "quizwizard", "credentials.json"), nil
+}
+
+// LoadCredentials reads the stored access token, returning empty credentials if the user has not logged in
+func LoadCredentials() (Credentials, error) {
+	if CredentialsFile == "" {
+		return Credentials{}, nil
+	}
+
+	data, err := os.ReadFile(CredentialsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return Credentials{}, nil
+	} else if err != nil {
+		return Credentials{}, fmt.Errorf("error reading credentials file: %v", err)
+	}
+
+	var credentials Credentials
+	err = json.Unmarshal(data, &credentials)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error unmarshaling credentials file: %v", err)
+	}
+
+	return credentials, nil
+}
+
+// SaveCredentials stores an access token in a file which only the user can read
+func SaveCredentials(credentials Credentials) error {
+	if CredentialsFile == "" {
+		return errors.New("no credentials file is configured")
+	}
+
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling credentials: %v", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(CredentialsFile), 0o700)
+	if err != nil {
+		return fmt.Errorf("error creating credentials directory: %v", err)
+	}
+
+	err = os.WriteFile(CredentialsFile, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("error writing credentials file: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteCredentials removes the stored access token, reporting whether there was one to remove
+func DeleteCredentials() (bool, error) {
+	if CredentialsFile == "" {
+		return false, nil
+	}
+
+	err := os.Remove(CredentialsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error removing credentials file: %v", err)
+	}
+
+	return true, nil
+}
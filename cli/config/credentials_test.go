@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCredentials checks that credentials are stored privately, loaded back and removed
+func TestCredentials(t *testing.T) {
+	originalFile := CredentialsFile
+	CredentialsFile = filepath.Join(t.TempDir(), "quizwizard", "credentials.json")
+	defer func() { CredentialsFile = originalFile }()
+
+	credentials, err := LoadCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{}, credentials, "Expected no credentials before logging in")
+
+	expiresAt := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	stored := Credentials{Username: "ada", Token: "abc.def.ghi", ExpiresAt: expiresAt}
+	assert.NoError(t, SaveCredentials(stored))
+
+	info, err := os.Stat(CredentialsFile)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	credentials, err = LoadCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, stored, credentials)
+	assert.False(t, credentials.Expired(expiresAt.Add(-time.Second)))
+	assert.True(t, credentials.Expired(expiresAt))
+
+	removed, err := DeleteCredentials()
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = DeleteCredentials()
+	assert.NoError(t, err)
+	assert.False(t, removed, "Expected logging out twice to remove nothing")
+}
@@ -21,6 +21,14 @@ func main() {
 		log.Fatal("Failed to load API URL")
 	}
 
+	config.CredentialsFile = os.Getenv("credentials_file")
+	if config.CredentialsFile == "" {
+		config.CredentialsFile, err = config.DefaultCredentialsFile()
+		if err != nil {
+			log.Println("Logging in is unavailable:", err)
+		}
+	}
+
 	cmd.Execute()
 	fmt.Println()
 }
@@ -1,50 +1,344 @@
 package models
 
+import "time"
+
+// Error codes returned by the API which the CLI handles specially. They mirror the codes in the API's handlers.
+const (
+	// CodeValidationFailed is returned when a parameter has an invalid value, which is named in the details
+	CodeValidationFailed = "VALIDATION_FAILED"
+	// CodeCategoryNotFound is returned when a category does not exist
+	CodeCategoryNotFound = "CATEGORY_NOT_FOUND"
+	// CodeInvalidCombination is returned when random is combined with other categories
+	CodeInvalidCombination = "INVALID_COMBINATION"
+	// CodeNoQuestions is returned when no questions match the category and difficulty of a quiz
+	CodeNoQuestions = "NO_QUESTIONS"
+)
+
+// FieldError represents a problem with a single parameter or field of a failed request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Category represents a quiz category and its details. Parent is empty for top level categories.
+type Category struct {
+	Slug             string   `json:"slug"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Parent           string   `json:"parent"`
+	QuestionCount    int      `json:"questionCount"`
+	Difficulties     []string `json:"difficulties"`
+	DefaultQuestions int      `json:"defaultQuestions"`
+}
+
 // CategoriesResponse represents the response from the get categories API endpoint
 type CategoriesResponse struct {
-	Success    bool     `json:"success"`
-	Message    string   `json:"message"`
-	Categories []string `json:"data"`
+	Success    bool         `json:"success"`
+	Code       string       `json:"code"`
+	Message    string       `json:"message"`
+	Details    []FieldError `json:"details"`
+	Categories []Category   `json:"data"`
 }
 
-// Question represents a single quiz question
+const (
+	// TypeMultiSelect is the type of questions which are answered by choosing one or more answers
+	TypeMultiSelect = "multi_select"
+	// TypeFreeText is the type of questions which are answered by typing text
+	TypeFreeText = "free_text"
+	// TypeNumeric is the type of questions which are answered by typing a number
+	TypeNumeric = "numeric"
+	// TypeOrdering is the type of questions which are answered by putting the answers in order
+	TypeOrdering = "ordering"
+	// TypeMatching is the type of questions which are answered by matching each prompt to an answer
+	TypeMatching = "matching"
+)
+
+// Question represents a single quiz question. TimeLimit is the number of seconds allowed to answer it, or 0 if it is untimed.
 type Question struct {
-	ID                 int      `json:"id"`
-	Category           string   `json:"category"`
-	Question           string   `json:"question"`
-	Answers            []string `json:"answers"`
-	CorrectAnswerIndex int      `json:"correctAnswerIndex"`
+	ID         int      `json:"id"`
+	Category   string   `json:"category"`
+	Type       string   `json:"type"`
+	Question   string   `json:"question"`
+	Prompts    []string `json:"prompts"`
+	Answers    []string `json:"answers"`
+	Unit       string   `json:"unit"`
+	Difficulty string   `json:"difficulty"`
+	TimeLimit  int      `json:"timeLimit"`
+}
+
+// Quiz represents the questions issued by the API for a quiz session
+type Quiz struct {
+	SessionID  string     `json:"sessionId"`
+	Category   string     `json:"category"`
+	Categories []string   `json:"categories"`
+	Difficulty string     `json:"difficulty,omitempty"`
+	Questions  []Question `json:"questions"`
 }
 
 // QuestionsResponse represents the response from the get questions API endpoint
 type QuestionsResponse struct {
-	Success   bool       `json:"success"`
-	Message   string     `json:"message"`
-	Questions []Question `json:"data"`
+	Success bool         `json:"success"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details"`
+	Quiz    Quiz         `json:"data"`
 }
 
-// QuestionAnswer represents an answer to a quiz question
+// QuestionAnswer represents an answer to a quiz question. Multi-select, ordering and matching questions are answered
+// with Answers, free-text questions with Text and numeric questions with Value.
 type QuestionAnswer struct {
-	Question *Question `json:"question"`
-	Answer   int       `json:"answer"`
+	QuestionID int      `json:"questionId"`
+	Answer     int      `json:"answer"`
+	Answers    []int    `json:"answers,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Value      *float64 `json:"value,omitempty"`
+}
+
+// AnswerCheck represents the outcome of checking a single answer. TimedOut reports that the answer arrived too late to count.
+type AnswerCheck struct {
+	QuestionID           int     `json:"questionId"`
+	Correct              bool    `json:"correct"`
+	TimedOut             bool    `json:"timedOut"`
+	Credit               float64 `json:"credit"`
+	CorrectAnswerIndex   int     `json:"correctAnswerIndex"`
+	CorrectAnswerIndexes []int   `json:"correctAnswerIndexes"`
+	CorrectAnswer        string  `json:"correctAnswer"`
+}
+
+// AnswerCheckResponse represents the response from the check answer API endpoint
+type AnswerCheckResponse struct {
+	Success bool         `json:"success"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details"`
+	Check   AnswerCheck  `json:"data"`
 }
 
-// QuizSubmission represents a list of question answers
+// QuizSubmission represents a list of question answers for a quiz session.
+// Submissions with a player name are ranked on the leaderboard.
 type QuizSubmission struct {
-	Category          string           `json:"category"`
+	SessionID         string           `json:"sessionId"`
+	PlayerName        string           `json:"playerName,omitempty"`
 	QuestionResponses []QuestionAnswer `json:"questionResponses"`
 }
 
-// Results represents the results of a quiz submission
-type Results struct {
-	Comparison      string  `json:"comparison"`
-	ScorePercentage float64 `json:"scorePercentage"`
+// CategoryScore represents the score for one category within a quiz, in points under the category's scoring strategy
+type CategoryScore struct {
+	Strategy        string  `json:"strategy"`
+	Score           float64 `json:"score"`
+	Total           float64 `json:"total"`
 	ScoreString     string  `json:"scoreString"`
+	ScorePercentage float64 `json:"scorePercentage"`
+}
+
+// LevelComparison represents the percentage of quizzers a score was better than at one level of the category tree
+type LevelComparison struct {
+	Category   string  `json:"category"`
+	Percentile float64 `json:"percentile"`
+}
+
+// Results represents the results of a quiz submission. Username is set when the result was saved to the user's account.
+// Levels are only set for subcategories, starting with the subcategory itself.
+type Results struct {
+	Comparison      string                   `json:"comparison"`
+	Levels          []LevelComparison        `json:"levels,omitempty"`
+	ScorePercentage float64                  `json:"scorePercentage"`
+	ScoreString     string                   `json:"scoreString"`
+	Breakdown       map[string]CategoryScore `json:"breakdown,omitempty"`
+	Points          []Points                 `json:"points,omitempty"`
+	Player          string                   `json:"player,omitempty"`
+	Username        string                   `json:"username,omitempty"`
+}
+
+// Points represents how the points for a single question were earned
+type Points struct {
+	QuestionID  int     `json:"questionId"`
+	Category    string  `json:"category"`
+	Available   float64 `json:"available"`
+	Earned      float64 `json:"earned"`
+	Penalty     float64 `json:"penalty"`
+	SpeedBonus  float64 `json:"speedBonus"`
+	StreakBonus float64 `json:"streakBonus"`
+	Total       float64 `json:"total"`
 }
 
 // QuizSubmissionResponse represents the response from the submit answers API endpoint
 type QuizSubmissionResponse struct {
-	Success bool    `json:"success"`
-	Message string  `json:"message"`
-	Results Results `json:"data"`
+	Success bool         `json:"success"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details"`
+	Results Results      `json:"data"`
+}
+
+// Ranking represents a player's best score on the leaderboard. Players with the same score share a rank.
+type Ranking struct {
+	Rank        int       `json:"rank"`
+	Player      string    `json:"player"`
+	Category    string    `json:"category"`
+	Difficulty  string    `json:"difficulty,omitempty"`
+	Score       float64   `json:"score"`
+	ScoreString string    `json:"scoreString"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Leaderboard represents the rankings for a category, or every category if it is empty, over a period
+type Leaderboard struct {
+	Category string    `json:"category"`
+	Period   string    `json:"period"`
+	Rankings []Ranking `json:"rankings"`
+}
+
+// LeaderboardResponse represents the response from the leaderboard API endpoint
+type LeaderboardResponse struct {
+	Success     bool         `json:"success"`
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	Details     []FieldError `json:"details"`
+	Leaderboard Leaderboard  `json:"data"`
+}
+
+// Credentials represents the username and password sent to register or log in
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Account represents a registered user
+type Account struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RegisterResponse represents the response from the register API endpoint
+type RegisterResponse struct {
+	Success bool         `json:"success"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details"`
+	Account Account      `json:"data"`
+}
+
+// AccessToken represents the signed access token issued when logging in
+type AccessToken struct {
+	Token     string    `json:"token"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LoginResponse represents the response from the login API endpoint
+type LoginResponse struct {
+	Success     bool         `json:"success"`
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	Details     []FieldError `json:"details"`
+	AccessToken AccessToken  `json:"data"`
+}
+
+// Outcome represents how a single question of a past quiz was answered. Credit is between 0 and 1.
+type Outcome struct {
+	QuestionID int     `json:"questionId"`
+	Category   string  `json:"category"`
+	Question   string  `json:"question"`
+	Credit     float64 `json:"credit"`
+	Skipped    bool    `json:"skipped,omitempty"`
+	Points     float64 `json:"points"`
+	Available  float64 `json:"available"`
+}
+
+// PastQuiz represents the result of a quiz submitted by the logged in user
+type PastQuiz struct {
+	Category    string    `json:"category"`
+	Difficulty  string    `json:"difficulty,omitempty"`
+	Score       float64   `json:"score"`
+	ScoreString string    `json:"scoreString"`
+	Outcomes    []Outcome `json:"outcomes"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// History represents a page of the logged in user's past quizzes, newest first
+type History struct {
+	Page    int        `json:"page"`
+	Limit   int        `json:"limit"`
+	Total   int        `json:"total"`
+	Results []PastQuiz `json:"results"`
+}
+
+// HistoryResponse represents the response from the history API endpoint
+type HistoryResponse struct {
+	Success bool         `json:"success"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details"`
+	History History      `json:"data"`
+}
+
+// CategoryStats represents the logged in user's accuracy for the questions of a single category
+type CategoryStats struct {
+	Category  string  `json:"category"`
+	Quizzes   int     `json:"quizzes"`
+	Questions int     `json:"questions"`
+	Correct   int     `json:"correct"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+// DailyStats represents the quizzes the logged in user submitted on a single day
+type DailyStats struct {
+	Date         string  `json:"date"`
+	Quizzes      int     `json:"quizzes"`
+	AverageScore float64 `json:"averageScore"`
+}
+
+// Stats represents the logged in user's statistics. Scores and accuracies are percentages.
+type Stats struct {
+	Quizzes       int             `json:"quizzes"`
+	Questions     int             `json:"questions"`
+	AverageScore  float64         `json:"averageScore"`
+	BestScore     float64         `json:"bestScore"`
+	Accuracy      float64         `json:"accuracy"`
+	Categories    []CategoryStats `json:"categories"`
+	BestCategory  string          `json:"bestCategory,omitempty"`
+	WorstCategory string          `json:"worstCategory,omitempty"`
+	Trend         []DailyStats    `json:"trend"`
+	Recent        []float64       `json:"recent"`
+}
+
+// StatsResponse represents the response from the stats API endpoint
+type StatsResponse struct {
+	Success bool         `json:"success"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details"`
+	Stats   Stats        `json:"data"`
+}
+
+// ScoreStats represents summary statistics for the scores submitted for a category. Histogram counts the scores in
+// each tenth of the range from 0% to 100%.
+type ScoreStats struct {
+	Count     int     `json:"count"`
+	Mean      float64 `json:"mean"`
+	Median    float64 `json:"median"`
+	StdDev    float64 `json:"stdDev"`
+	PassRate  float64 `json:"passRate"`
+	Lowest    float64 `json:"lowest"`
+	Highest   float64 `json:"highest"`
+	Histogram []int   `json:"histogram"`
+}
+
+// CategoryStatistics represents the statistics for a category, or combination of categories, and difficulty
+type CategoryStatistics struct {
+	Category   string     `json:"category"`
+	Difficulty string     `json:"difficulty"`
+	PassMark   float64    `json:"passMark"`
+	Stats      ScoreStats `json:"stats"`
+}
+
+// CategoryStatsResponse represents the response from the category stats API endpoint
+type CategoryStatsResponse struct {
+	Success    bool               `json:"success"`
+	Code       string             `json:"code"`
+	Message    string             `json:"message"`
+	Details    []FieldError       `json:"details"`
+	Statistics CategoryStatistics `json:"data"`
 }